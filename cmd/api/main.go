@@ -4,7 +4,16 @@ import (
 	"context"
 	"log"
 	"os"
+	"tabmate/internals/auth"
+	"tabmate/internals/avatars"
 	tablecontrollers "tabmate/internals/controllers/table"
+	"tabmate/internals/encryption"
+	"tabmate/internals/invites"
+	"tabmate/internals/notifications"
+	"tabmate/internals/payments"
+	"tabmate/internals/reminders"
+	"tabmate/internals/sms"
+	"tabmate/internals/storage"
 	tabmate "tabmate/internals/store/postgres"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -34,13 +43,73 @@ func main() {
 	}
 	log.Println("Successfully connected to the database!")
 
+	paymentProvider, err := payments.NewProviderFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure payment provider: %v", err)
+	}
+	if paymentProvider == nil {
+		log.Println("PAYMENT_PROVIDER not set, in-app payments are disabled")
+	}
+
+	bankKeys, err := encryption.NewKeyProviderFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure bank details encryption: %v", err)
+	}
+
+	authenticator, err := auth.NewAuthenticatorFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure authentication: %v", err)
+	}
+
+	userWebhooks := auth.ClerkWebhookSourceFromEnv()
+	if userWebhooks == nil {
+		log.Println("CLERK_WEBHOOK_SECRET not set, Clerk user changes are only picked up at sign-in")
+	}
+
+	inviteSigner, err := invites.NewSignerFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure invite links: %v", err)
+	}
+	if inviteSigner == nil {
+		log.Println("INVITE_SIGNING_KEY not set, invite links are disabled")
+	}
+
+	smsSender, err := sms.NewSenderFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure SMS: %v", err)
+	}
+	if smsSender == nil {
+		log.Println("SMS_PROVIDER not set, phone numbers can't be verified")
+	}
+
+	blobs, err := storage.NewBlobStoreFromEnv(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to configure file storage: %v", err)
+	}
+	if blobs == nil {
+		log.Println("STORAGE_BACKEND not set, receipt images and avatars can't be uploaded")
+	}
+
 	queries := tabmate.New(pool)
-	router := setupRouter(pool, queries)
+	router := setupRouter(pool, queries, authenticator, userWebhooks, paymentProvider, encryption.NewEnvelope(bankKeys), inviteSigner, smsSender, blobs)
 
 	err = tablecontrollers.InitializeActiveTables(context.Background(), queries)
 	if err != nil {
 		log.Fatalf("Failed to initialize active tables: %v", err)
 	}
+
+	// Email reaches members who don't have the app; it is off unless SMTP_HOST is set.
+	var fallbacks []notifications.Notifier
+	if email := notifications.NewEmailNotifierFromEnv(); email != nil {
+		fallbacks = append(fallbacks, email)
+	} else {
+		log.Println("SMTP_HOST not set; notifications will be sent by push only")
+	}
+	go notifications.NewWorker(queries, notifications.NewExpoClientFromEnv(), fallbacks...).Run(context.Background())
+	go reminders.NewScheduler(pool).Run(context.Background())
+	if blobs != nil {
+		go avatars.NewSweeper(queries, blobs).Run(context.Background())
+	}
 		
 	log.Println("Server starting on http://localhost:8080")
 	if err := router.Run(":8080"); err != nil {
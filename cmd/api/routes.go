@@ -5,21 +5,37 @@ import (
 	"net/http"
 	"time"
 
+	"tabmate/internals/auth"
 	activitycontroller "tabmate/internals/controllers/activity"
 	authcontroller "tabmate/internals/controllers/auth"
+	invitecontroller "tabmate/internals/controllers/invites"
 	menucontroller "tabmate/internals/controllers/menu"
+	placeholdercontroller "tabmate/internals/controllers/placeholders"
 	splitcontroller "tabmate/internals/controllers/splits"
 	tablecontroller "tabmate/internals/controllers/table"
 	usercontroller "tabmate/internals/controllers/user"
+	webhookcontroller "tabmate/internals/controllers/webhooks"
+	"tabmate/internals/encryption"
+	"tabmate/internals/invites"
 	"tabmate/internals/middleware"
+	"tabmate/internals/payments"
+	"tabmate/internals/roles"
+	"tabmate/internals/sms"
+	"tabmate/internals/storage"
 	tabmate "tabmate/internals/store/postgres"
+	"tabmate/internals/webhooks"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func setupRouter(pool *pgxpool.Pool, queries tabmate.Querier) *gin.Engine {
+// setupRouter wires every route. authenticator verifies the bearer tokens callers sign
+// in with, userWebhooks, when set, delivers the identity provider's user changes, and
+// smsSender, when set, texts phone verification codes. blobs, when set, stores receipt
+// images and avatars; a storage.LocalStore's files are also served at
+// storage.LocalRoute.
+func setupRouter(pool *pgxpool.Pool, queries tabmate.Querier, authenticator auth.Authenticator, userWebhooks webhooks.Source, paymentProvider payments.Provider, bankCipher *encryption.Envelope, inviteSigner *invites.Signer, smsSender sms.Sender, blobs storage.BlobStore) *gin.Engine {
 	router := gin.Default()
 
 	// Load HTML templates
@@ -32,13 +48,53 @@ func setupRouter(pool *pgxpool.Pool, queries tabmate.Querier) *gin.Engine {
 		log.Printf("Request: %s %s", c.Request.Method, c.Request.URL.Path)
 	})
 
+	// Signed-in users are cached by token subject; see middleware.Identities.
+	identities := middleware.NewIdentities(queries, authenticator)
+
+	// ─── Webhooks ─────────────────────────────────────────────────────────────
+	webhookDispatcher := webhooks.NewDispatcher(queries)
+	if paymentProvider != nil {
+		webhookDispatcher.Register(payments.WebhookSource(paymentProvider), splitcontroller.PaymentEventHandler(pool, queries))
+	}
+	if userWebhooks != nil {
+		webhookDispatcher.Register(userWebhooks, usercontroller.ClerkUserEventHandler(pool, queries, identities.Forget))
+	}
+
 	// ─── Public routes ────────────────────────────────────────────────────────
 	router.GET("/", authcontroller.HandleHome)
+	router.POST("/api/webhooks/:provider", middleware.RateLimitByIP("webhooks", 120, time.Minute, 120), webhookcontroller.HandleWebhook(webhookDispatcher))
+	if local, ok := authenticator.(*auth.Local); ok {
+		log.Println("AUTH_PROVIDER=local: anyone can get a token from /api/auth/local/token")
+		router.GET("/.well-known/jwks.json", authcontroller.LocalJWKS(local))
+		router.POST("/api/auth/local/token", middleware.RateLimitByIP("local-token", 60, time.Minute, 60), authcontroller.IssueLocalToken(local))
+	}
+	if local, ok := blobs.(*storage.LocalStore); ok {
+		router.StaticFS(storage.LocalRoute, gin.Dir(local.Root(), false))
+	}
+	router.GET("/api/invites/:token", middleware.RateLimitByIP("invite-preview", 60, time.Minute, 60), invitecontroller.PreviewInvite(queries, inviteSigner))
 
 	// ─── Protected routes ─────────────────────────────────────────────────────
 	authorized := router.Group("/")
-	authorized.Use(middleware.AuthMiddleware(queries))
+	authorized.Use(middleware.AuthMiddleware(identities))
 	authorized.Use(middleware.RateLimitByUser("authorized", 180, time.Minute, 240))
+
+	// Split and table codes are looked up by whoever knows them, so misses across all of
+	// these routes share one small allowance to stop codes being guessed.
+	codeLookups := middleware.LimitFailedLookups("code-lookup", 20, time.Hour)
+
+	// Search and lookup both find people by exact email, phone number or username, so
+	// they share one allowance to stop the directory being enumerated.
+	userLookups := middleware.RateLimitByUser("user-lookup", 60, time.Hour, 20)
+
+	// Everything addressed by a split or table code goes through one of these, so only
+	// members, co-hosts with the right permission, or the host reach the handler.
+	splitMember := middleware.RequireSplitMember(queries)
+	splitHost := middleware.RequireSplitHost(queries)
+	splitCan := func(p roles.Permission) gin.HandlerFunc { return middleware.RequireSplitPermission(queries, p) }
+	tableMember := middleware.RequireTableMember(queries)
+	tableHost := middleware.RequireTableHost(queries)
+	tableCan := func(p roles.Permission) gin.HandlerFunc { return middleware.RequireTablePermission(queries, p) }
+	itemMember := middleware.RequireTableItemMember(queries)
 	{
 		authorized.GET("/profile", authcontroller.HandleProfile)
 
@@ -54,65 +110,125 @@ func setupRouter(pool *pgxpool.Pool, queries tabmate.Querier) *gin.Engine {
 		})
 
 		// ── User ──────────────────────────────────────────────────────────────
-		authorized.GET("/api/me", usercontroller.GetUser(queries))
-		authorized.GET("/api/users/search", usercontroller.SearchUsers(queries))
+		authorized.GET("/api/me", usercontroller.GetUser(queries, bankCipher))
+		authorized.DELETE("/api/me", usercontroller.DeleteAccount(pool, queries, blobs, identities.Remove))
+		authorized.GET("/api/me/export", middleware.RateLimitByUser("account-export", 5, time.Hour, 5), usercontroller.ExportAccount(queries, bankCipher))
+		authorized.GET("/api/users/search", userLookups, usercontroller.SearchUsers(queries))
+		authorized.GET("/api/users/suggestions", usercontroller.SuggestMembers(queries))
+		authorized.GET("/api/users/lookup", userLookups, usercontroller.LookupUser(queries))
+		authorized.PATCH("/api/user/username", usercontroller.UpdateUsername(queries))
+		authorized.PUT("/api/user/phone", middleware.RateLimitByUser("phone-verification", 5, time.Hour, 5), usercontroller.StartPhoneVerification(queries, smsSender))
+		authorized.POST("/api/user/phone/verify", usercontroller.VerifyPhone(pool, queries))
+		authorized.DELETE("/api/user/phone", usercontroller.RemovePhone(queries))
+		authorized.GET("/api/user/privacy", usercontroller.GetPrivacySettings(queries))
+		authorized.PUT("/api/user/privacy", usercontroller.UpdatePrivacySettings(queries))
+		authorized.GET("/api/contacts", usercontroller.ListContacts(queries))
+		authorized.GET("/api/friends", usercontroller.ListFriends(queries))
+		authorized.DELETE("/api/friends/:userId", usercontroller.RemoveFriend(queries))
+		authorized.GET("/api/friends/requests", usercontroller.ListFriendRequests(queries))
+		authorized.POST("/api/friends/requests", middleware.RateLimitByUser("friend-request", 30, time.Hour, 30), usercontroller.SendFriendRequest(queries))
+		authorized.POST("/api/friends/requests/:userId/accept", usercontroller.AcceptFriendRequest(queries))
+		authorized.DELETE("/api/friends/requests/:userId", usercontroller.DeleteFriendRequest(queries))
+		authorized.GET("/api/users/:id/bank-details", usercontroller.GetUserBankDetails(queries, bankCipher))
 		authorized.PATCH("/api/user/push-token", usercontroller.UpdatePushToken(queries))
-		authorized.PATCH("/api/user/bank-details", usercontroller.UpdateBankDetails(queries))
+		authorized.DELETE("/api/user/push-token", usercontroller.RemovePushToken(queries))
+		authorized.PATCH("/api/user/bank-details", usercontroller.UpdateBankDetails(queries, bankCipher))
 		authorized.PATCH("/api/user/profile", usercontroller.UpdateProfile(queries))
+		authorized.PUT("/api/user/avatar", middleware.RateLimitByUser("avatar-upload", 10, time.Hour, 10), usercontroller.UploadAvatar(pool, queries, blobs))
+		authorized.DELETE("/api/user/avatar", usercontroller.DeleteAvatar(pool))
+		authorized.PATCH("/api/user/timezone", usercontroller.UpdateTimezone(queries))
+		authorized.GET("/api/user/notification-preferences", usercontroller.GetNotificationPreferences(queries))
+		authorized.PUT("/api/user/notification-preferences", usercontroller.UpdateNotificationPreferences(queries))
+		authorized.GET("/api/user/payout-methods", usercontroller.ListPayoutMethods(queries, bankCipher))
+		authorized.POST("/api/user/payout-methods", usercontroller.CreatePayoutMethod(pool, queries, bankCipher))
+		authorized.PATCH("/api/user/payout-methods/:id/default", usercontroller.SetDefaultPayoutMethod(pool, queries))
+		authorized.DELETE("/api/user/payout-methods/:id", usercontroller.DeletePayoutMethod(pool, queries))
+		authorized.GET("/api/user/tokens", usercontroller.ListAccessTokens(queries))
+		authorized.POST("/api/user/tokens", middleware.RateLimitByUser("create-access-token", 10, time.Hour, 10), usercontroller.CreateAccessToken(queries))
+		authorized.DELETE("/api/user/tokens/:id", usercontroller.RevokeAccessToken(queries))
+		authorized.GET("/api/user/placeholders", placeholdercontroller.ListClaimablePlaceholders(queries))
+		authorized.POST("/api/placeholders/:id/claim", placeholdercontroller.ClaimPlaceholder(pool))
 
 		// ── Tables ────────────────────────────────────────────────────────────
 		authorized.POST("/api/create-table", tablecontroller.CreateTable(queries))
 		authorized.POST("/api/tables/add-item-to-order", tablecontroller.AddItemToTable(queries))
-		authorized.POST("/api/join-table/:code", tablecontroller.JoinTable(queries))
-		authorized.GET("/api/tables/:code", tablecontroller.GetTableHandler(queries))
-		authorized.GET("/api/tables/:code/members", tablecontroller.FetchTableMembers(queries))
-		authorized.GET("/api/tables/:code/table-items", tablecontroller.ListItemsWithUserDetailsInTable(queries))
+		authorized.POST("/api/join-table/:code", codeLookups, tablecontroller.JoinTable(queries))
+		authorized.GET("/api/tables/:code", codeLookups, tableMember, tablecontroller.GetTableHandler(queries))
+		authorized.GET("/api/tables/:code/members", tableMember, tablecontroller.FetchTableMembers(queries))
+		authorized.POST("/api/tables/:code/invites", tableCan(roles.ManageMembers), invitecontroller.CreateTableInvite(pool, inviteSigner))
+		authorized.POST("/api/tables/:code/placeholders", tableCan(roles.ManageMembers), placeholdercontroller.AddTablePlaceholder(pool, queries))
+		authorized.GET("/api/tables/:code/join-code", tableCan(roles.ManageMembers), tablecontroller.GetTableJoinCode(queries))
+		authorized.POST("/api/tables/:code/join-code", tableCan(roles.ManageMembers), tablecontroller.RotateTableJoinCode(pool, queries))
+		authorized.DELETE("/api/tables/:code/join-code", tableCan(roles.ManageMembers), tablecontroller.RevokeTableJoinCode(queries))
+		authorized.GET("/api/tables/:code/table-items", tableMember, tablecontroller.ListItemsWithUserDetailsInTable(queries))
 		authorized.GET("/api/get-user-tables", tablecontroller.ListTablesForUser(queries))
 
 		// Table Items
 		authorized.POST("/api/items", tablecontroller.AddMenuItemsToDB(queries))
-		authorized.PATCH("/api/items/:id", tablecontroller.UpdateItemQuantity(queries))
-		authorized.DELETE("/api/items/:id", tablecontroller.DeleteItemFromTable(queries))
-		authorized.POST("/api/tables/:code/sync", tablecontroller.SyncTableItems(pool))
-		authorized.PATCH("/api/tables/:code", tablecontroller.UpdateTableVat(queries))
-		authorized.PATCH("/api/tables/:code/close", tablecontroller.CloseTable(queries))
-		authorized.POST("/api/tables/:code/payment-reminder", middleware.RateLimitByUser("table-payment-reminder", 5, time.Hour, 5), tablecontroller.SendTablePaymentReminder(queries))
-		authorized.POST("/api/tables/:code/scan-menu", middleware.RateLimitByUser("scan-menu", 10, time.Hour, 10), menucontroller.ScanMenu(queries))
-		authorized.POST("/api/tables/:code/extract-menu-url", middleware.RateLimitByUser("extract-menu-url", 10, time.Hour, 10), menucontroller.ExtractMenuFromURL(queries))
-		authorized.GET("/api/tables/:code/menu", menucontroller.GetScannedMenu(queries))
-		authorized.PUT("/api/tables/:code/menu", menucontroller.UpdateScannedMenu(queries))
-		authorized.DELETE("/api/tables/:code/menu", menucontroller.DeleteScannedMenu(queries))
+		authorized.PATCH("/api/items/:id", itemMember, tablecontroller.UpdateItemQuantity(queries))
+		authorized.DELETE("/api/items/:id", itemMember, tablecontroller.DeleteItemFromTable(queries))
+		authorized.POST("/api/tables/:code/sync", tableMember, tablecontroller.SyncTableItems(pool))
+		authorized.PATCH("/api/tables/:code", tableCan(roles.ManageItems), tablecontroller.UpdateTableVat(queries))
+		authorized.PATCH("/api/tables/:code/close", tableCan(roles.Close), tablecontroller.CloseTable(pool))
+		authorized.POST("/api/tables/:code/transfer-host", tableHost, tablecontroller.TransferTableHost(pool, queries))
+		authorized.PUT("/api/tables/:code/members/:userId/role", tableHost, tablecontroller.UpdateTableMemberRole(queries))
+		authorized.POST("/api/tables/:code/payment-reminder", tableCan(roles.SendReminders), middleware.RateLimitByUser("table-payment-reminder", 5, time.Hour, 5), tablecontroller.SendTablePaymentReminder(pool, queries))
+		authorized.POST("/api/tables/:code/scan-menu", tableMember, middleware.RateLimitByUser("scan-menu", 10, time.Hour, 10), menucontroller.ScanMenu(queries))
+		authorized.POST("/api/tables/:code/extract-menu-url", tableMember, middleware.RateLimitByUser("extract-menu-url", 10, time.Hour, 10), menucontroller.ExtractMenuFromURL(queries))
+		authorized.GET("/api/tables/:code/menu", tableMember, menucontroller.GetScannedMenu(queries))
+		authorized.PUT("/api/tables/:code/menu", tableMember, menucontroller.UpdateScannedMenu(queries))
+		authorized.DELETE("/api/tables/:code/menu", tableCan(roles.ManageItems), menucontroller.DeleteScannedMenu(queries))
 
 		// ── Splits ────────────────────────────────────────────────────────────
 		authorized.POST("/api/create-split", splitcontroller.CreateSplit(queries))
-		authorized.POST("/api/create-split-from-receipt", splitcontroller.CreateSplitFromReceipt(queries))
+		authorized.POST("/api/create-split-from-receipt", splitcontroller.CreateSplitFromReceipt(queries, blobs))
 		authorized.POST("/api/splits/preview-receipt", splitcontroller.PreviewReceipt())
-		authorized.GET("/api/splits/:code", splitcontroller.GetSplitByCode(queries))
-		authorized.POST("/api/join-split/:code", splitcontroller.JoinSplit(queries))
-		authorized.POST("/api/splits/:code/add-member", splitcontroller.AddMemberToSplit(queries))
-		authorized.GET("/api/splits/:code/members", splitcontroller.GetSplitMembers(queries))
-		authorized.DELETE("/api/splits/:code/leave", splitcontroller.LeaveSplit(queries))
-		authorized.DELETE("/api/splits/:code", splitcontroller.DeleteSplit(queries))
-		authorized.DELETE("/api/splits/:code/members/:userId", splitcontroller.RemoveMemberFromSplit(queries))
-		authorized.GET("/api/splits/:code/breakdown", splitcontroller.GetSplitBreakdown(queries))
-		authorized.GET("/api/splits/:code/receipt", splitcontroller.GetSplitReceipt(queries))
-		authorized.POST("/api/splits/:code/receipt", splitcontroller.UpsertSplitReceipt(queries))
-		authorized.POST("/api/splits/:code/settle", splitcontroller.MarkAsSettled(queries))
-		authorized.POST("/api/splits/:code/close", splitcontroller.CloseSplit(queries))
-		authorized.POST("/api/splits/:code/mark-payment-sent", splitcontroller.MarkPaymentSent(queries))
-		authorized.POST("/api/splits/:code/members/:userId/confirm-payment", splitcontroller.ConfirmPayment(queries))
-		authorized.PATCH("/api/splits/:code/payment-instructions", splitcontroller.UpdatePaymentInstructions(queries))
-		authorized.POST("/api/splits/:code/remind", middleware.RateLimitByUser("split-remind", 5, time.Hour, 5), splitcontroller.RemindMembers(queries))
+		authorized.GET("/api/splits/:code", codeLookups, splitMember, splitcontroller.GetSplitByCode(queries))
+		authorized.POST("/api/join-split/:code", codeLookups, splitcontroller.JoinSplit(queries))
+		authorized.POST("/api/splits/:code/add-member", splitCan(roles.ManageMembers), splitcontroller.AddMemberToSplit(pool, queries))
+		authorized.POST("/api/splits/:code/invites", splitCan(roles.ManageMembers), invitecontroller.CreateSplitInvite(pool, inviteSigner))
+		authorized.POST("/api/splits/:code/placeholders", splitCan(roles.ManageMembers), placeholdercontroller.AddSplitPlaceholder(pool, queries))
+		authorized.GET("/api/splits/:code/join-code", splitCan(roles.ManageMembers), splitcontroller.GetSplitJoinCode(queries))
+		authorized.POST("/api/splits/:code/join-code", splitCan(roles.ManageMembers), splitcontroller.RotateSplitJoinCode(pool, queries))
+		authorized.DELETE("/api/splits/:code/join-code", splitCan(roles.ManageMembers), splitcontroller.RevokeSplitJoinCode(queries))
+		authorized.GET("/api/splits/:code/members", splitMember, splitcontroller.GetSplitMembers(queries))
+		authorized.DELETE("/api/splits/:code/leave", splitMember, splitcontroller.LeaveSplit(pool, queries))
+		authorized.DELETE("/api/splits/:code", splitHost, splitcontroller.DeleteSplit(queries))
+		authorized.DELETE("/api/splits/:code/members/:userId", splitCan(roles.ManageMembers), splitcontroller.RemoveMemberFromSplit(queries))
+		authorized.PUT("/api/splits/:code/members/:userId/role", splitHost, splitcontroller.UpdateSplitMemberRole(queries))
+		authorized.POST("/api/splits/:code/transfer-host", splitHost, splitcontroller.TransferSplitHost(pool, queries))
+		authorized.GET("/api/splits/:code/breakdown", splitMember, splitcontroller.GetSplitBreakdown(queries))
+		authorized.GET("/api/splits/:code/receipt", splitMember, splitcontroller.GetSplitReceipt(queries))
+		authorized.POST("/api/splits/:code/receipt", splitCan(roles.ManageItems), splitcontroller.UpsertSplitReceipt(queries, blobs))
+		authorized.POST("/api/splits/:code/settle", splitMember, splitcontroller.MarkAsSettled(pool, queries))
+		authorized.POST("/api/splits/:code/close", splitCan(roles.Close), splitcontroller.CloseSplit(queries))
+		authorized.POST("/api/splits/:code/mark-payment-sent", splitMember, splitcontroller.MarkPaymentSent(pool))
+		authorized.POST("/api/splits/:code/payment-link", splitMember, splitcontroller.CreatePaymentLink(queries, paymentProvider))
+		authorized.GET("/api/splits/:code/payments/:reference", splitMember, splitcontroller.GetPaymentStatus(pool, queries, paymentProvider))
+		authorized.GET("/api/splits/:code/members/:userId/payment-request", splitMember, splitcontroller.GetPaymentRequest(queries, bankCipher))
+		authorized.GET("/api/splits/:code/members/:userId/payment-qr", splitMember, splitcontroller.GetPaymentQRCode(queries, bankCipher))
+		authorized.POST("/api/splits/:code/members/:userId/confirm-payment", splitCan(roles.ConfirmPayments), splitcontroller.ConfirmPayment(pool))
+		authorized.PATCH("/api/splits/:code/payment-instructions", splitHost, splitcontroller.UpdatePaymentInstructions(queries))
+		authorized.GET("/api/splits/:code/payout-method", splitMember, splitcontroller.GetSplitPayoutMethod(queries, bankCipher))
+		authorized.PUT("/api/splits/:code/payout-method", splitHost, splitcontroller.UpdateSplitPayoutMethod(queries))
+		authorized.POST("/api/splits/:code/remind", splitCan(roles.SendReminders), middleware.RateLimitByUser("split-remind", 5, time.Hour, 5), splitcontroller.RemindMembers(pool, queries))
+		authorized.GET("/api/splits/:code/notifications", splitCan(roles.SendReminders), splitcontroller.ListSplitNotifications(queries))
+		authorized.GET("/api/splits/:code/reminder-policy", splitMember, splitcontroller.GetReminderPolicy(queries))
+		authorized.PUT("/api/splits/:code/reminder-policy", splitCan(roles.SendReminders), splitcontroller.UpdateReminderPolicy(queries))
 		authorized.GET("/api/get-user-splits", splitcontroller.ListSplitsForUser(queries))
 
+		// ── Invites ───────────────────────────────────────────────────────────
+		authorized.POST("/api/invites/:token/accept", invitecontroller.AcceptInvite(pool, queries, inviteSigner))
+		authorized.DELETE("/api/invites/:token", invitecontroller.RevokeInvite(queries, inviteSigner))
+
 		// ── Activity Feed ─────────────────────────────────────────────────────
 		authorized.GET("/api/activity", activitycontroller.GetActivityFeed(queries))
 		// Split items & claims
-		authorized.GET("/api/splits/:code/items", splitcontroller.GetSplitItems(queries))
-		authorized.PUT("/api/splits/:code/items", splitcontroller.ReplaceAllSplitItems(queries))
-		authorized.POST("/api/splits/:code/items", splitcontroller.MergeSplitItems(queries))
-		authorized.POST("/api/splits/:code/items/:itemId/claim", splitcontroller.ClaimItem(queries))
-		authorized.DELETE("/api/splits/:code/items/:itemId/claim", splitcontroller.UnclaimItem(queries))
+		authorized.GET("/api/splits/:code/items", splitMember, splitcontroller.GetSplitItems(queries))
+		authorized.PUT("/api/splits/:code/items", splitCan(roles.ManageItems), splitcontroller.ReplaceAllSplitItems(queries))
+		authorized.POST("/api/splits/:code/items", splitCan(roles.ManageItems), splitcontroller.MergeSplitItems(queries))
+		authorized.POST("/api/splits/:code/items/:itemId/claim", splitMember, splitcontroller.ClaimItem(pool, queries))
+		authorized.DELETE("/api/splits/:code/items/:itemId/claim", splitMember, splitcontroller.UnclaimItem(queries))
 	}
 
 	// ─── WebSocket (token via query param) ────────────────────────────────────
@@ -125,12 +241,22 @@ func setupRouter(pool *pgxpool.Pool, queries tabmate.Querier) *gin.Engine {
 			return
 		}
 
-		user := middleware.VerifyOIDCToken(queries, token)
+		user := middleware.VerifyOIDCToken(identities, token)
 		if !user.ID.Valid {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing user"})
 			return
 		}
 
+		dbTable, err := queries.GetTableByCode(c, code)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Table not found"})
+			return
+		}
+		if _, err := queries.GetTableMember(c, tabmate.GetTableMemberParams{TableID: dbTable.ID, UserID: user.ID}); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Table not found"})
+			return
+		}
+
 		userIDStr := uuid.UUID(user.ID.Bytes).String()
 		log.Printf("WebSocket connection for table %s by user %s (%s)", code, user.Name.String, userIDStr)
 
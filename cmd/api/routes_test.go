@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"tabmate/internals/apitokens"
+	"tabmate/internals/auth"
+	"tabmate/internals/roles"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// strangerQueries is a Querier in which every split, table and item exists but the
+// caller belongs to none of them. Methods the policies do not call are left to the
+// embedded nil interface, so a handler reached by mistake panics into a 500.
+type strangerQueries struct {
+	tabmate.Querier
+	caller pgtype.UUID
+}
+
+func (q strangerQueries) GetUserByCognitoSub(ctx context.Context, sub string) (tabmate.Users, error) {
+	return tabmate.Users{ID: q.caller, CognitoSub: sub, Name: pgtype.Text{String: "stranger", Valid: true}}, nil
+}
+
+func (strangerQueries) GetSplitByCode(ctx context.Context, code string) (tabmate.Splits, error) {
+	return tabmate.Splits{ID: newUUID(), CreatedBy: newUUID(), SplitCode: code}, nil
+}
+
+func (strangerQueries) GetSplitMember(ctx context.Context, arg tabmate.GetSplitMemberParams) (tabmate.SplitMembers, error) {
+	return tabmate.SplitMembers{}, pgx.ErrNoRows
+}
+
+func (strangerQueries) GetTableByCode(ctx context.Context, code string) (tabmate.Tables, error) {
+	return tabmate.Tables{ID: newUUID(), CreatedBy: newUUID(), TableCode: code}, nil
+}
+
+func (strangerQueries) GetTableMember(ctx context.Context, arg tabmate.GetTableMemberParams) (tabmate.TableMembers, error) {
+	return tabmate.TableMembers{}, pgx.ErrNoRows
+}
+
+func (strangerQueries) GetItemByID(ctx context.Context, id pgtype.UUID) (tabmate.Items, error) {
+	return tabmate.Items{ID: id, TableCode: "t4b1e000"}, nil
+}
+
+func newUUID() pgtype.UUID {
+	return pgtype.UUID{Bytes: uuid.New(), Valid: true}
+}
+
+func TestNonMembersGetNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Chdir("../..") // templates/ is loaded relative to the repo root
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer, err := auth.NewLocal(auth.DefaultLocalIssuer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := issuer.Issue(auth.Identity{Subject: "user_stranger", Name: "stranger"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := setupRouter(nil, strangerQueries{caller: newUUID()}, issuer, nil, nil, nil, nil, nil, nil)
+
+	// Joining is how non-members become members, so those routes are not policed.
+	open := map[string]bool{"/api/join-split/:code": true, "/api/join-table/:code": true}
+
+	type request struct{ method, path, body string }
+	requests := []request{
+		{http.MethodPost, "/api/tables/add-item-to-order", `{"table_code":"t4b1e000","name":"Chips","price":4.5}`},
+		{http.MethodPost, "/api/items", `[{"table_code":"t4b1e000","name":"Chips","price":4.5}]`},
+	}
+	for _, route := range router.Routes() {
+		if open[route.Path] || strings.HasPrefix(route.Path, "/ws/") {
+			continue
+		}
+		if !strings.Contains(route.Path, ":code") && route.Path != "/api/items/:id" {
+			continue
+		}
+		path := strings.ReplaceAll(route.Path, ":code", "c0de0000")
+		for _, param := range []string{":id", ":userId", ":itemId", ":reference"} {
+			path = strings.ReplaceAll(path, param, uuid.NewString())
+		}
+		requests = append(requests, request{route.Method, path, "{}"})
+	}
+	if len(requests) < 40 {
+		t.Fatalf("only %d routes checked; were routes renamed?", len(requests))
+	}
+
+	for _, r := range requests {
+		req := httptest.NewRequest(r.method, r.path, strings.NewReader(r.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s %s = %d, want 404: %s", r.method, r.path, w.Code, w.Body.String())
+		}
+	}
+}
+
+// hostQueries is a Querier in which the caller hosts every split and table, and holds
+// personal access tokens whose hashes map to their scopes.
+type hostQueries struct {
+	strangerQueries
+	tokens map[string][]string
+}
+
+func (q hostQueries) GetSplitMember(ctx context.Context, arg tabmate.GetSplitMemberParams) (tabmate.SplitMembers, error) {
+	return tabmate.SplitMembers{SplitID: arg.SplitID, UserID: arg.UserID, Role: roles.Host}, nil
+}
+
+func (q hostQueries) GetTableMember(ctx context.Context, arg tabmate.GetTableMemberParams) (tabmate.TableMembers, error) {
+	return tabmate.TableMembers{TableID: arg.TableID, UserID: arg.UserID, Role: roles.Host}, nil
+}
+
+func (q hostQueries) GetActivePersonalAccessTokenByHash(ctx context.Context, hash []byte) (tabmate.PersonalAccessTokens, error) {
+	scopes, ok := q.tokens[string(hash)]
+	if !ok {
+		return tabmate.PersonalAccessTokens{}, pgx.ErrNoRows
+	}
+	return tabmate.PersonalAccessTokens{ID: newUUID(), UserID: q.caller, TokenHash: hash, Scopes: scopes}, nil
+}
+
+func (q hostQueries) GetUserByID(ctx context.Context, id pgtype.UUID) (tabmate.Users, error) {
+	return tabmate.Users{ID: id, Name: pgtype.Text{String: "host", Valid: true}}, nil
+}
+
+func (hostQueries) TouchPersonalAccessToken(ctx context.Context, id pgtype.UUID) error {
+	return nil
+}
+
+func TestAccessTokensCannotReachHostOnlyRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Chdir("../..")
+
+	// One token per scope, and one with all of them.
+	grants := [][]string{{string(apitokens.ReadSplits)}, {string(apitokens.WriteSplits)}, {string(apitokens.ManageTables)}, {}}
+	for _, s := range apitokens.All {
+		grants[len(grants)-1] = append(grants[len(grants)-1], string(s))
+	}
+	q := hostQueries{strangerQueries: strangerQueries{caller: newUUID()}, tokens: map[string][]string{}}
+	tokens := make([]string, len(grants))
+	for i, scopes := range grants {
+		token, err := apitokens.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens[i] = token
+		q.tokens[string(apitokens.Hash(token))] = scopes
+	}
+	router := setupRouter(nil, q, nil, nil, nil, nil, nil, nil, nil)
+
+	hostOnly := []struct{ method, path string }{
+		{http.MethodDelete, "/api/splits/c0de0000"},
+		{http.MethodPut, "/api/splits/c0de0000/members/" + uuid.NewString() + "/role"},
+		{http.MethodPost, "/api/splits/c0de0000/transfer-host"},
+		{http.MethodGet, "/api/splits/c0de0000/members/" + uuid.NewString() + "/payment-request"},
+		{http.MethodGet, "/api/splits/c0de0000/members/" + uuid.NewString() + "/payment-qr"},
+		{http.MethodPatch, "/api/splits/c0de0000/payment-instructions"},
+		{http.MethodGet, "/api/splits/c0de0000/payout-method"},
+		{http.MethodPut, "/api/splits/c0de0000/payout-method"},
+		{http.MethodPut, "/api/tables/t4b1e000/members/" + uuid.NewString() + "/role"},
+		{http.MethodPost, "/api/tables/t4b1e000/transfer-host"},
+		{http.MethodPatch, "/api/user/bank-details"},
+		{http.MethodGet, "/api/user/payout-methods"},
+	}
+	for i, token := range tokens {
+		for _, r := range hostOnly {
+			req := httptest.NewRequest(r.method, r.path, strings.NewReader("{}"))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusForbidden {
+				t.Errorf("%v: %s %s = %d, want 403: %s", grants[i], r.method, r.path, w.Code, w.Body.String())
+			}
+		}
+	}
+
+	// The same caller gets past the token check on a route their scope covers.
+	req := httptest.NewRequest(http.MethodGet, "/api/splits/c0de0000/breakdown", nil)
+	req.Header.Set("Authorization", "Bearer "+tokens[0])
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code == http.StatusForbidden {
+		t.Fatalf("splits:read token was refused a split breakdown: %s", w.Body.String())
+	}
+}
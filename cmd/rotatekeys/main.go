@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"tabmate/internals/encryption"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+// rotatekeys re-encrypts every user's bank details and payout methods under the
+// current key in BANK_ENCRYPTION_KEYS. Legacy plaintext rows are encrypted on the
+// way. Run it after putting a new key first in the list; the old key can be dropped
+// once it finishes.
+func main() {
+	_ = godotenv.Load()
+
+	dryRun := flag.Bool("dry-run", false, "report rows that need rotation without writing")
+	flag.Parse()
+
+	connectionString := os.Getenv("DB_SOURCE")
+	if connectionString == "" {
+		log.Fatal("DB_SOURCE environment variable is not set")
+	}
+
+	keys, err := encryption.NewKeyProviderFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure bank details encryption: %v", err)
+	}
+	cipher := encryption.NewEnvelope(keys)
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, connectionString)
+	if err != nil {
+		log.Fatalf("Unable to connect to database: %v", err)
+	}
+	defer pool.Close()
+	queries := tabmate.New(pool)
+
+	users, err := queries.ListUsersWithBankDetails(ctx)
+	if err != nil {
+		log.Fatalf("Failed to list users: %v", err)
+	}
+
+	var rotated, failed int
+	for _, user := range users {
+		fields := []pgtype.Text{user.BankName, user.AccountName, user.AccountNumber}
+		if !needsRotation(cipher, fields) {
+			continue
+		}
+		if *dryRun {
+			rotated++
+			continue
+		}
+
+		reencrypted := make([]pgtype.Text, len(fields))
+		for i, field := range fields {
+			plaintext, err := cipher.DecryptText(ctx, field)
+			if err == nil {
+				reencrypted[i], err = cipher.EncryptText(ctx, plaintext)
+			}
+			if err != nil {
+				log.Printf("user %x: %v", user.ID.Bytes, err)
+				failed++
+				reencrypted = nil
+				break
+			}
+		}
+		if reencrypted == nil {
+			continue
+		}
+
+		if err := queries.UpdateBankDetails(ctx, tabmate.UpdateBankDetailsParams{
+			BankName:      reencrypted[0],
+			AccountName:   reencrypted[1],
+			AccountNumber: reencrypted[2],
+			ID:            user.ID,
+		}); err != nil {
+			log.Printf("user %x: update failed: %v", user.ID.Bytes, err)
+			failed++
+			continue
+		}
+		rotated++
+	}
+
+	verb := "rotated"
+	if *dryRun {
+		verb = "need rotation"
+	}
+	log.Printf("%d of %d users %s under key %q, %d failed", rotated, len(users), verb, keys.CurrentKeyID(), failed)
+
+	methods, err := queries.ListAllPayoutMethods(ctx)
+	if err != nil {
+		log.Fatalf("Failed to list payout methods: %v", err)
+	}
+
+	var rotatedMethods int
+	for _, method := range methods {
+		if !cipher.NeedsRotation(method.Details) {
+			continue
+		}
+		if *dryRun {
+			rotatedMethods++
+			continue
+		}
+
+		plaintext, err := cipher.Decrypt(ctx, method.Details)
+		if err != nil {
+			log.Printf("payout method %x: %v", method.ID.Bytes, err)
+			failed++
+			continue
+		}
+		sealed, err := cipher.Encrypt(ctx, plaintext)
+		if err != nil {
+			log.Printf("payout method %x: %v", method.ID.Bytes, err)
+			failed++
+			continue
+		}
+		if err := queries.UpdatePayoutMethodDetails(ctx, tabmate.UpdatePayoutMethodDetailsParams{
+			ID:      method.ID,
+			Details: sealed,
+		}); err != nil {
+			log.Printf("payout method %x: update failed: %v", method.ID.Bytes, err)
+			failed++
+			continue
+		}
+		rotatedMethods++
+	}
+	log.Printf("%d of %d payout methods %s", rotatedMethods, len(methods), verb)
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func needsRotation(cipher *encryption.Envelope, fields []pgtype.Text) bool {
+	for _, field := range fields {
+		if field.Valid && cipher.NeedsRotation(field.String) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+// Package accounts handles what happens to a user's data when they delete their account.
+package accounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"tabmate/internals/hosts"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DeletedName is what a deleted user is shown as in splits, tables and activity.
+const DeletedName = "Deleted user"
+
+// Anonymize removes what identifies user while keeping the money right. Their users
+// row becomes a placeholder named DeletedName, so the splits they are in still add up:
+// what they owe, what they claimed, their items and payments are untouched. Splits and
+// tables they host are handed to the next co-host or member with an account, their
+// activity is renamed, and their device tokens, payout methods, invite links, access
+// tokens, friendships and pending notifications are removed. Their avatar is marked
+// replaced, so the avatars sweeper deletes its images. queries must be bound to a
+// transaction.
+func Anonymize(ctx context.Context, queries tabmate.Querier, user tabmate.Users) error {
+	splitIDs, err := queries.ListSplitsHostedByUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("list hosted splits: %w", err)
+	}
+	for _, splitID := range splitIDs {
+		if err := handOverSplit(ctx, queries, splitID, user.ID); err != nil {
+			return fmt.Errorf("hand over split: %w", err)
+		}
+	}
+
+	tableIDs, err := queries.ListTablesHostedByUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("list hosted tables: %w", err)
+	}
+	for _, tableID := range tableIDs {
+		if err := handOverTable(ctx, queries, tableID, user.ID); err != nil {
+			return fmt.Errorf("hand over table: %w", err)
+		}
+	}
+
+	if err := queries.DemoteUserInSplits(ctx, user.ID); err != nil {
+		return fmt.Errorf("demote in splits: %w", err)
+	}
+	if err := queries.DemoteUserInTables(ctx, user.ID); err != nil {
+		return fmt.Errorf("demote in tables: %w", err)
+	}
+	if err := queries.AnonymizeActivityEvents(ctx, tabmate.AnonymizeActivityEventsParams{
+		UserID:  user.ID,
+		Name:    DeletedName,
+		OldName: user.Name.String,
+	}); err != nil {
+		return fmt.Errorf("anonymize activity: %w", err)
+	}
+
+	if err := queries.DeleteDeviceTokensForUser(ctx, user.ID); err != nil {
+		return fmt.Errorf("delete device tokens: %w", err)
+	}
+	if err := queries.DeletePayoutMethodsForUser(ctx, user.ID); err != nil {
+		return fmt.Errorf("delete payout methods: %w", err)
+	}
+	if err := queries.RevokeInviteLinksByCreator(ctx, user.ID); err != nil {
+		return fmt.Errorf("revoke invite links: %w", err)
+	}
+	if err := queries.RevokePersonalAccessTokensForUser(ctx, user.ID); err != nil {
+		return fmt.Errorf("revoke access tokens: %w", err)
+	}
+	if err := queries.DeletePhoneVerification(ctx, user.ID); err != nil {
+		return fmt.Errorf("delete phone verification: %w", err)
+	}
+	if err := queries.DeleteFriendshipsForUser(ctx, user.ID); err != nil {
+		return fmt.Errorf("delete friendships: %w", err)
+	}
+	if err := queries.ReplaceAvatarsForUser(ctx, user.ID); err != nil {
+		return fmt.Errorf("replace avatars: %w", err)
+	}
+	if err := queries.CancelPendingNotificationsForUser(ctx, user.ID); err != nil {
+		return fmt.Errorf("cancel notifications: %w", err)
+	}
+
+	if _, err := queries.AnonymizeUser(ctx, tabmate.AnonymizeUserParams{
+		Name: pgtype.Text{String: DeletedName, Valid: true},
+		ID:   user.ID,
+	}); err != nil {
+		return fmt.Errorf("anonymize user: %w", err)
+	}
+	return nil
+}
+
+// handOverSplit makes the next co-host or member the host of a split, who then becomes
+// the one owed. A split with nobody to take over keeps the deleted user as its payee.
+func handOverSplit(ctx context.Context, queries tabmate.Querier, splitID, userID pgtype.UUID) error {
+	next, err := queries.NextSplitHost(ctx, tabmate.NextSplitHostParams{SplitID: splitID, UserID: userID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return hosts.HandOverSplit(ctx, queries, splitID, userID, next, false)
+}
+
+// handOverTable makes the next co-host or member the host of a table.
+func handOverTable(ctx context.Context, queries tabmate.Querier, tableID, userID pgtype.UUID) error {
+	next, err := queries.NextTableHost(ctx, tabmate.NextTableHostParams{TableID: tableID, UserID: userID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return hosts.HandOverTable(ctx, queries, tableID, userID, next, false)
+}
@@ -0,0 +1,121 @@
+package accounts
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Export is everything TabMate holds about one user. The JSON parts are written as the
+// API would return them; the rest are tabular and written as CSV.
+type Export struct {
+	Profile  any
+	Tables   any
+	Splits   any
+	Activity any
+	Items    []tabmate.Items
+	Claims   []tabmate.ListSplitClaimsForUserRow
+	Payments []tabmate.ListSplitPaymentsForUserRow
+}
+
+// WriteZip writes the export to w as a ZIP archive with one file per part.
+func (e Export) WriteZip(w io.Writer, exportedAt time.Time) error {
+	archive := zip.NewWriter(w)
+
+	for _, part := range []struct {
+		name  string
+		value any
+	}{
+		{"profile.json", e.Profile},
+		{"tables.json", e.Tables},
+		{"splits.json", e.Splits},
+		{"activity.json", e.Activity},
+	} {
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: part.name, Method: zip.Deflate, Modified: exportedAt})
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(part.value); err != nil {
+			return err
+		}
+	}
+
+	items := [][]string{{"table_code", "name", "price", "quantity", "description", "source", "added_at"}}
+	for _, item := range e.Items {
+		items = append(items, []string{
+			item.TableCode,
+			item.Name,
+			money(item.Price),
+			strconv.Itoa(int(item.Quantity)),
+			item.Description.String,
+			item.Source.String,
+			timestamp(item.CreatedAt),
+		})
+	}
+	claims := [][]string{{"split_code", "item_name", "price", "quantity_claimed", "claimed_at"}}
+	for _, claim := range e.Claims {
+		claims = append(claims, []string{
+			claim.SplitCode,
+			claim.ItemName,
+			money(claim.Price),
+			strconv.Itoa(int(claim.QuantityClaimed)),
+			timestamp(claim.ClaimedAt),
+		})
+	}
+	payments := [][]string{{"split_code", "provider", "reference", "amount", "currency", "status", "created_at", "confirmed_at"}}
+	for _, payment := range e.Payments {
+		payments = append(payments, []string{
+			payment.SplitCode,
+			payment.Provider,
+			payment.Reference,
+			money(payment.Amount),
+			payment.Currency,
+			payment.Status,
+			timestamp(payment.CreatedAt),
+			timestamp(payment.ConfirmedAt),
+		})
+	}
+
+	for _, part := range []struct {
+		name string
+		rows [][]string
+	}{
+		{"table_items.csv", items},
+		{"split_claims.csv", claims},
+		{"payments.csv", payments},
+	} {
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: part.name, Method: zip.Deflate, Modified: exportedAt})
+		if err != nil {
+			return err
+		}
+		if err := csv.NewWriter(file).WriteAll(part.rows); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func money(n pgtype.Numeric) string {
+	value, err := n.Float64Value()
+	if err != nil || !value.Valid {
+		return ""
+	}
+	return strconv.FormatFloat(value.Float64, 'f', 2, 64)
+}
+
+func timestamp(t pgtype.Timestamptz) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,77 @@
+package accounts
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestExportWriteZip(t *testing.T) {
+	var amount pgtype.Numeric
+	if err := amount.Scan("12.5"); err != nil {
+		t.Fatal(err)
+	}
+	paidAt := time.Date(2026, 3, 1, 18, 30, 0, 0, time.UTC)
+	export := Export{
+		Profile:  map[string]string{"name": "Ada"},
+		Tables:   []string{},
+		Splits:   []string{},
+		Activity: []string{},
+		Payments: []tabmate.ListSplitPaymentsForUserRow{{
+			SplitCode: "7k3m9pq2",
+			Provider:  "paystack",
+			Reference: "TM-1",
+			Amount:    amount,
+			Currency:  "NGN",
+			Status:    "succeeded",
+			CreatedAt: pgtype.Timestamptz{Time: paidAt, Valid: true},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteZip(&buf, paidAt); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name], _ = io.ReadAll(r)
+		r.Close()
+	}
+	for _, name := range []string{"profile.json", "tables.json", "splits.json", "activity.json", "table_items.csv", "split_claims.csv", "payments.csv"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("%s missing from the export", name)
+		}
+	}
+
+	var profile map[string]string
+	if err := json.Unmarshal(files["profile.json"], &profile); err != nil || profile["name"] != "Ada" {
+		t.Errorf("profile.json = %s (%v)", files["profile.json"], err)
+	}
+
+	rows, err := csv.NewReader(bytes.NewReader(files["payments.csv"])).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"7k3m9pq2", "paystack", "TM-1", "12.50", "NGN", "succeeded", "2026-03-01T18:30:00Z", ""}
+	if len(rows) != 2 || !slices.Equal(rows[1], want) {
+		t.Errorf("payments.csv = %q, want a header and %q", rows, want)
+	}
+}
@@ -0,0 +1,180 @@
+// Package apitokens issues the personal access tokens scripts use to call the API, and
+// decides which routes each token scope opens.
+package apitokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Prefix starts every personal access token, so they are told apart from identity
+// provider tokens without a lookup, and secret scanners can recognise a leaked one.
+const Prefix = "tm_pat_"
+
+// MaxTTLDays is the longest, in days, a token can be made to last. Tokens without an
+// expiry last until they are revoked.
+const MaxTTLDays = 365
+
+// MaxTTL is MaxTTLDays as a duration.
+const MaxTTL = MaxTTLDays * 24 * time.Hour
+
+const (
+	secretLength = 32
+	hintLength   = 4
+)
+
+var (
+	ErrNoScopes   = errors.New("a token needs at least one scope")
+	ErrInvalidTTL = fmt.Errorf("expires_in_days must be between 1 and %d, or 0 for no expiry", MaxTTLDays)
+)
+
+// Scope is a group of routes a token may call.
+type Scope string
+
+const (
+	ReadSplits   Scope = "splits:read"   // view splits, their members, items, receipts and payments
+	WriteSplits  Scope = "splits:write"  // create, join and change splits; implies splits:read
+	ManageTables Scope = "tables:manage" // create, join and change tables and their items
+)
+
+// All lists every scope, in the order they are shown.
+var All = []Scope{ReadSplits, WriteSplits, ManageTables}
+
+// route is a method and a route pattern as registered (gin's FullPath).
+type route struct{ method, path string }
+
+// routes lists every route a token can call and the scope it needs. Anything else,
+// such as the caller's profile, bank details or tokens, and on splits and tables
+// deleting them, handing over the host, changing roles or seeing where money is paid,
+// needs a sign-in.
+var routes = map[route]Scope{
+	{"POST", "/api/create-split"}:                                 WriteSplits,
+	{"POST", "/api/create-split-from-receipt"}:                    WriteSplits,
+	{"POST", "/api/splits/preview-receipt"}:                       WriteSplits,
+	{"POST", "/api/join-split/:code"}:                             WriteSplits,
+	{"GET", "/api/get-user-splits"}:                               ReadSplits,
+	{"GET", "/api/splits/:code"}:                                  ReadSplits,
+	{"GET", "/api/splits/:code/members"}:                          ReadSplits,
+	{"POST", "/api/splits/:code/add-member"}:                      WriteSplits,
+	{"DELETE", "/api/splits/:code/members/:userId"}:               WriteSplits,
+	{"POST", "/api/splits/:code/invites"}:                         WriteSplits,
+	{"POST", "/api/splits/:code/placeholders"}:                    WriteSplits,
+	{"GET", "/api/splits/:code/join-code"}:                        ReadSplits,
+	{"POST", "/api/splits/:code/join-code"}:                       WriteSplits,
+	{"DELETE", "/api/splits/:code/join-code"}:                     WriteSplits,
+	{"DELETE", "/api/splits/:code/leave"}:                         WriteSplits,
+	{"GET", "/api/splits/:code/breakdown"}:                        ReadSplits,
+	{"GET", "/api/splits/:code/receipt"}:                          ReadSplits,
+	{"POST", "/api/splits/:code/receipt"}:                         WriteSplits,
+	{"GET", "/api/splits/:code/items"}:                            ReadSplits,
+	{"PUT", "/api/splits/:code/items"}:                            WriteSplits,
+	{"POST", "/api/splits/:code/items"}:                           WriteSplits,
+	{"POST", "/api/splits/:code/items/:itemId/claim"}:             WriteSplits,
+	{"DELETE", "/api/splits/:code/items/:itemId/claim"}:           WriteSplits,
+	{"POST", "/api/splits/:code/settle"}:                          WriteSplits,
+	{"POST", "/api/splits/:code/close"}:                           WriteSplits,
+	{"POST", "/api/splits/:code/mark-payment-sent"}:               WriteSplits,
+	{"POST", "/api/splits/:code/payment-link"}:                    WriteSplits,
+	{"GET", "/api/splits/:code/payments/:reference"}:              ReadSplits,
+	{"POST", "/api/splits/:code/members/:userId/confirm-payment"}: WriteSplits,
+	{"POST", "/api/splits/:code/remind"}:                          WriteSplits,
+	{"GET", "/api/splits/:code/notifications"}:                    ReadSplits,
+	{"GET", "/api/splits/:code/reminder-policy"}:                  ReadSplits,
+	{"PUT", "/api/splits/:code/reminder-policy"}:                  WriteSplits,
+
+	{"POST", "/api/create-table"}:                  ManageTables,
+	{"POST", "/api/join-table/:code"}:              ManageTables,
+	{"GET", "/api/get-user-tables"}:                ManageTables,
+	{"GET", "/api/tables/:code"}:                   ManageTables,
+	{"PATCH", "/api/tables/:code"}:                 ManageTables,
+	{"GET", "/api/tables/:code/members"}:           ManageTables,
+	{"POST", "/api/tables/:code/invites"}:          ManageTables,
+	{"POST", "/api/tables/:code/placeholders"}:     ManageTables,
+	{"GET", "/api/tables/:code/join-code"}:         ManageTables,
+	{"POST", "/api/tables/:code/join-code"}:        ManageTables,
+	{"DELETE", "/api/tables/:code/join-code"}:      ManageTables,
+	{"GET", "/api/tables/:code/table-items"}:       ManageTables,
+	{"POST", "/api/tables/:code/sync"}:             ManageTables,
+	{"PATCH", "/api/tables/:code/close"}:           ManageTables,
+	{"POST", "/api/tables/:code/payment-reminder"}: ManageTables,
+	{"POST", "/api/tables/:code/scan-menu"}:        ManageTables,
+	{"POST", "/api/tables/:code/extract-menu-url"}: ManageTables,
+	{"GET", "/api/tables/:code/menu"}:              ManageTables,
+	{"PUT", "/api/tables/:code/menu"}:              ManageTables,
+	{"DELETE", "/api/tables/:code/menu"}:           ManageTables,
+	{"POST", "/api/tables/add-item-to-order"}:      ManageTables,
+	{"POST", "/api/items"}:                         ManageTables,
+	{"PATCH", "/api/items/:id"}:                    ManageTables,
+	{"DELETE", "/api/items/:id"}:                   ManageTables,
+}
+
+// Generate returns a new token. Only its Hash should be stored.
+func Generate() (string, error) {
+	buf := make([]byte, secretLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return Prefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// IsToken reports whether a bearer token is a personal access token.
+func IsToken(token string) bool {
+	return strings.HasPrefix(token, Prefix)
+}
+
+// Hash returns the value stored for token. Tokens carry 256 random bits, so a plain
+// SHA-256 is enough; there is nothing to gain from a slow password hash.
+func Hash(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// Hint returns the last few characters of token, shown in listings so users can tell
+// their tokens apart.
+func Hint(token string) string {
+	if len(token) <= hintLength {
+		return token
+	}
+	return token[len(token)-hintLength:]
+}
+
+// ParseScopes checks a list of scope names from a request and returns it in canonical
+// order without duplicates.
+func ParseScopes(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, ErrNoScopes
+	}
+	for _, name := range names {
+		if !slices.Contains(All, Scope(name)) {
+			return nil, fmt.Errorf("unknown scope %q", name)
+		}
+	}
+	scopes := []string{}
+	for _, s := range All {
+		if slices.Contains(names, string(s)) {
+			scopes = append(scopes, string(s))
+		}
+	}
+	return scopes, nil
+}
+
+// Required returns the scope a token needs to call method on route, the route pattern
+// as registered (gin's FullPath). It returns false for routes tokens can't call at all.
+func Required(method, path string) (Scope, bool) {
+	s, ok := routes[route{method, path}]
+	return s, ok
+}
+
+// Allows reports whether a token granted scopes may use s.
+func Allows(granted []string, s Scope) bool {
+	if slices.Contains(granted, string(s)) {
+		return true
+	}
+	return s == ReadSplits && slices.Contains(granted, string(WriteSplits))
+}
@@ -0,0 +1,74 @@
+package apitokens
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	a, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := Generate()
+	if a == b || !IsToken(a) || IsToken("eyJhbGciOiJSUzI1NiJ9.e30.sig") {
+		t.Fatalf("Generate = %q, %q", a, b)
+	}
+	if !bytes.Equal(Hash(a), Hash(a)) || bytes.Equal(Hash(a), Hash(b)) {
+		t.Fatal("Hash is not a function of the token")
+	}
+	if Hint(a) != a[len(a)-4:] {
+		t.Fatalf("Hint(%q) = %q", a, Hint(a))
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	got, err := ParseScopes([]string{"tables:manage", "splits:read", "tables:manage"})
+	if err != nil || !slices.Equal(got, []string{"splits:read", "tables:manage"}) {
+		t.Fatalf("ParseScopes = %v, %v", got, err)
+	}
+	if _, err := ParseScopes(nil); err != ErrNoScopes {
+		t.Fatalf("ParseScopes(nil) error = %v, want ErrNoScopes", err)
+	}
+	if _, err := ParseScopes([]string{"admin"}); err == nil {
+		t.Fatal("ParseScopes accepted an unknown scope")
+	}
+}
+
+func TestRequired(t *testing.T) {
+	for _, tc := range []struct {
+		method, route string
+		want          Scope
+		ok            bool
+	}{
+		{"GET", "/api/splits/:code", ReadSplits, true},
+		{"GET", "/api/get-user-splits", ReadSplits, true},
+		{"POST", "/api/create-split-from-receipt", WriteSplits, true},
+		{"POST", "/api/splits/:code/items/:itemId/claim", WriteSplits, true},
+		{"GET", "/api/tables/:code/members", ManageTables, true},
+		{"PATCH", "/api/items/:id", ManageTables, true},
+		{"GET", "/api/me", "", false},
+		{"POST", "/api/user/tokens", "", false},
+		{"PATCH", "/api/user/bank-details", "", false},
+		{"DELETE", "/api/splits/:code", "", false},
+		{"GET", "/api/splits/:code/payout-method", "", false},
+		{"POST", "/api/tables/:code/transfer-host", "", false},
+		{"GET", "/api/splits/:code/unknown", "", false},
+	} {
+		got, ok := Required(tc.method, tc.route)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("Required(%s %s) = %q, %v; want %q, %v", tc.method, tc.route, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestAllows(t *testing.T) {
+	write := []string{string(WriteSplits)}
+	if !Allows(write, ReadSplits) || !Allows(write, WriteSplits) || Allows(write, ManageTables) {
+		t.Fatal("splits:write should allow reading and writing splits only")
+	}
+	if Allows([]string{string(ReadSplits)}, WriteSplits) {
+		t.Fatal("splits:read allowed a write")
+	}
+}
@@ -0,0 +1,54 @@
+// Package auth verifies the bearer tokens clients sign in with. Which provider checks
+// them is chosen by AUTH_PROVIDER: Clerk in production, or a local issuer for
+// development and integration tests.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed, badly signed or expired.
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Identity is who a verified token belongs to.
+type Identity struct {
+	Subject string // the provider's user ID, stored in users.cognito_sub
+	Email   string
+	Name    string
+}
+
+// Authenticator verifies a bearer token and says whose it is.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (Identity, error)
+}
+
+// UserDeleter is implemented by authenticators whose provider holds the user's sign-in,
+// so deleting a TabMate account can delete that too.
+type UserDeleter interface {
+	DeleteUser(ctx context.Context, subject string) error
+}
+
+// NewAuthenticatorFromEnv builds the authenticator selected by AUTH_PROVIDER, which
+// defaults to clerk.
+func NewAuthenticatorFromEnv() (Authenticator, error) {
+	switch strings.ToLower(os.Getenv("AUTH_PROVIDER")) {
+	case "", "clerk":
+		secretKey := os.Getenv("CLERK_SECRET_KEY")
+		if secretKey == "" {
+			return nil, fmt.Errorf("CLERK_SECRET_KEY is required when AUTH_PROVIDER=clerk")
+		}
+		return NewClerk(secretKey), nil
+	case "local":
+		local, err := NewLocalFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return local, nil
+	default:
+		return nil, fmt.Errorf("unknown AUTH_PROVIDER %q", os.Getenv("AUTH_PROVIDER"))
+	}
+}
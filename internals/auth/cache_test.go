@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheExpires(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	cache := NewCache[string](10, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("user_1", "Sam")
+	if got, ok := cache.Get("user_1"); !ok || got != "Sam" {
+		t.Fatalf("Get() = %q, %v; want Sam, true", got, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get("user_1"); ok {
+		t.Error("Get() hit after the TTL")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Len() = %d after expiry, want 0", cache.Len())
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewCache[int](2, time.Hour)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	cache.Set("c", 3)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("%s should still be cached", key)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", cache.Len())
+	}
+
+	cache.Delete("a")
+	if _, ok := cache.Get("a"); ok {
+		t.Error("a should have been deleted")
+	}
+}
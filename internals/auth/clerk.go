@@ -4,87 +4,139 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
-	"strings"
+	"time"
 
 	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/clerk/clerk-sdk-go/v2/jwks"
 	clerkjwt "github.com/clerk/clerk-sdk-go/v2/jwt"
 	clerkuser "github.com/clerk/clerk-sdk-go/v2/user"
-	"github.com/joho/godotenv"
 )
 
-func init() {
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using environment variables")
-	}
-
-	secretKey := os.Getenv("CLERK_SECRET_KEY")
-	if secretKey == "" {
-		log.Fatal("CLERK_SECRET_KEY is not set")
-	}
+// Clerk verifies Clerk session tokens. Signing keys are cached by key ID, and a user's
+// name and email come from the token when the session token template includes them,
+// or else from the Clerk API, cached per user, so most requests make no outbound call.
+type Clerk struct {
+	jwks     *jwks.Client
+	users    *clerkuser.Client
+	keys     *Cache[*clerk.JSONWebKey]
+	profiles *Cache[Identity]
+}
 
-	clerk.SetKey(secretKey)
-	log.Println("Clerk auth initialized")
+// clerkProfileClaims are the custom claims read from a session token. Add them to the
+// Clerk session token template as {"email": "{{user.primary_email_address}}",
+// "name": "{{user.full_name}}"} to skip the user lookup entirely.
+type clerkProfileClaims struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
 }
 
-// ClerkUserInfo holds identity extracted from a verified Clerk session token.
-type ClerkUserInfo struct {
-	Sub   string // Clerk user ID (e.g. user_2abc...)
-	Email string
-	Name  string
+const (
+	clerkKeyTTL     = time.Hour
+	clerkProfileTTL = 15 * time.Minute
+)
+
+// NewClerk returns a Clerk authenticator using secretKey for the Clerk API.
+func NewClerk(secretKey string) *Clerk {
+	config := &clerk.ClientConfig{}
+	config.Key = clerk.String(secretKey)
+	return &Clerk{
+		jwks:     jwks.NewClient(config),
+		users:    clerkuser.NewClient(config),
+		keys:     NewCache[*clerk.JSONWebKey](16, clerkKeyTTL),
+		profiles: NewCache[Identity](10_000, clerkProfileTTL),
+	}
 }
 
-// VerifyClerkToken verifies a Clerk session token and returns the caller's identity.
-func VerifyClerkToken(tokenString string) (*ClerkUserInfo, error) {
-	claims, err := clerkjwt.Verify(context.Background(), &clerkjwt.VerifyParams{
-		Token: tokenString,
+// Authenticate verifies a Clerk session token and returns the caller's identity.
+func (a *Clerk) Authenticate(ctx context.Context, token string) (Identity, error) {
+	unverified, err := clerkjwt.Decode(ctx, &clerkjwt.DecodeParams{Token: token})
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	key, err := a.signingKey(ctx, unverified.KeyID)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	claims, err := clerkjwt.Verify(ctx, &clerkjwt.VerifyParams{
+		Token: token,
+		JWK:   key,
+		CustomClaimsConstructor: func(context.Context) any {
+			return &clerkProfileClaims{}
+		},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("invalid Clerk token: %w", err)
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
 	}
 
-	info := &ClerkUserInfo{Sub: claims.Subject}
+	id := Identity{Subject: claims.Subject}
+	if profile, ok := claims.Custom.(*clerkProfileClaims); ok && profile.Email != "" {
+		id.Email, id.Name = profile.Email, profile.Name
+		return id, nil
+	}
 
-	// Fetch email/name from Clerk API.
-	details, err := fetchClerkUser(info.Sub)
+	if cached, ok := a.profiles.Get(id.Subject); ok {
+		return cached, nil
+	}
+	details, err := a.fetchUser(ctx, id.Subject)
 	if err != nil {
-		log.Printf("Warning: could not fetch Clerk user details for %s: %v", info.Sub, err)
-	} else {
-		info.Email = details.Email
-		info.Name = details.Name
+		log.Printf("Warning: could not fetch Clerk user details for %s: %v", id.Subject, err)
+		return id, nil
 	}
+	a.profiles.Set(id.Subject, details)
+	return details, nil
+}
+
+// Forget drops the cached profile for subject, so the next request fetches it again.
+// The user webhook calls it when a user changes their name or email in Clerk.
+func (a *Clerk) Forget(subject string) {
+	a.profiles.Delete(subject)
+}
 
-	return info, nil
+// DeleteUser deletes the user in Clerk, which ends their sessions.
+func (a *Clerk) DeleteUser(ctx context.Context, subject string) error {
+	a.Forget(subject)
+	_, err := a.users.Delete(ctx, subject)
+	return err
 }
 
-func fetchClerkUser(userID string) (*ClerkUserInfo, error) {
-	u, err := clerkuser.Get(context.Background(), userID)
+// signingKey returns the JWKS key with the given ID. The key set is fetched again when
+// a token names a key that isn't cached, which is how rotated keys are picked up.
+func (a *Clerk) signingKey(ctx context.Context, keyID string) (*clerk.JSONWebKey, error) {
+	if key, ok := a.keys.Get(keyID); ok {
+		return key, nil
+	}
+	key, err := clerkjwt.GetJSONWebKey(ctx, &clerkjwt.GetJSONWebKeyParams{
+		KeyID:      keyID,
+		JWKSClient: a.jwks,
+	})
 	if err != nil {
 		return nil, err
 	}
+	a.keys.Set(keyID, key)
+	return key, nil
+}
+
+func (a *Clerk) fetchUser(ctx context.Context, userID string) (Identity, error) {
+	u, err := a.users.Get(ctx, userID)
+	if err != nil {
+		return Identity{}, err
+	}
 
-	info := &ClerkUserInfo{Sub: userID}
+	id := Identity{Subject: userID}
 
 	// Resolve primary email.
 	for _, e := range u.EmailAddresses {
 		if u.PrimaryEmailAddressID != nil && e.ID == *u.PrimaryEmailAddressID {
-			info.Email = e.EmailAddress
+			id.Email = e.EmailAddress
 			break
 		}
 	}
-	if info.Email == "" && len(u.EmailAddresses) > 0 {
-		info.Email = u.EmailAddresses[0].EmailAddress
+	if id.Email == "" && len(u.EmailAddresses) > 0 {
+		id.Email = u.EmailAddresses[0].EmailAddress
 	}
 
-	// Build display name.
-	parts := []string{}
-	if u.FirstName != nil && *u.FirstName != "" {
-		parts = append(parts, *u.FirstName)
-	}
-	if u.LastName != nil && *u.LastName != "" {
-		parts = append(parts, *u.LastName)
-	}
-	info.Name = strings.Join(parts, " ")
+	id.Name = fullName(u.FirstName, u.LastName)
 
-	return info, nil
+	return id, nil
 }
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"tabmate/internals/webhooks"
+)
+
+// svixExampleSecret and the delivery below are the worked example from Svix's
+// signature verification docs.
+const svixExampleSecret = "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"
+
+func TestSvixSignatureMatchesReference(t *testing.T) {
+	verifier := ClerkWebhookVerifier(svixExampleSecret)
+	verifier.Now = func() time.Time { return time.Unix(1614265330, 0) }
+
+	header := http.Header{}
+	header.Set("svix-id", "msg_p5jXN8AQM9LWM0D4loKWxJek")
+	header.Set("svix-timestamp", "1614265330")
+	header.Set("svix-signature", "v1,bm9ldHUjZ2V0c3RhcnRlZA== v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE=")
+	body := []byte(`{"test": 2432232314}`)
+
+	if err := verifier.Verify(header, body); err != nil {
+		t.Fatalf("Verify() = %v", err)
+	}
+	if err := verifier.Verify(header, []byte(`{"test": 2432232315}`)); !errors.Is(err, webhooks.ErrInvalidSignature) {
+		t.Errorf("tampered body: Verify() = %v, want ErrInvalidSignature", err)
+	}
+
+	verifier.Now = func() time.Time { return time.Unix(1614265330, 0).Add(time.Hour) }
+	if err := verifier.Verify(header, body); !errors.Is(err, webhooks.ErrStaleTimestamp) {
+		t.Errorf("old delivery: Verify() = %v, want ErrStaleTimestamp", err)
+	}
+}
+
+func TestClerkWebhookSourceParsesUser(t *testing.T) {
+	body := []byte(`{"type":"user.updated","data":{"id":"user_1","first_name":"Ada","last_name":null,` +
+		`"primary_email_address_id":"idn_2","email_addresses":[{"id":"idn_1","email_address":"old@example.com"},` +
+		`{"id":"idn_2","email_address":"ada@example.com"}]}}`)
+	header := ClerkWebhookVerifier(svixExampleSecret).Sign(body)
+
+	event, err := ClerkWebhookSource(svixExampleSecret).ParseWebhook(header, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.ID != header.Get("svix-id") || event.Type != ClerkUserUpdated {
+		t.Errorf("event = %s %s, want %s %s", event.ID, event.Type, header.Get("svix-id"), ClerkUserUpdated)
+	}
+	want := Identity{Subject: "user_1", Email: "ada@example.com", Name: "Ada"}
+	if got := event.Data.(*ClerkUserEvent).User; got != want {
+		t.Errorf("user = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+)
+
+// DefaultLocalIssuer is the iss claim of tokens from a Local issuer unless
+// AUTH_LOCAL_ISSUER says otherwise.
+const DefaultLocalIssuer = "tabmate-local"
+
+// Local issues and verifies its own RS256 tokens, so the API can run without Clerk in
+// development and integration tests. Whoever can reach its token endpoint can sign in
+// as anyone, so it must never be used in production.
+type Local struct {
+	issuer string
+	key    jose.JSONWebKey
+	signer jose.Signer
+}
+
+type localClaims struct {
+	jwt.Claims
+	Email string `json:"email,omitempty"`
+	Name  string `json:"name,omitempty"`
+}
+
+// NewLocal returns a Local issuer that signs with key and names itself issuer.
+func NewLocal(issuer string, key *rsa.PrivateKey) (*Local, error) {
+	jwk := jose.JSONWebKey{Key: key, Algorithm: string(jose.RS256), Use: "sig"}
+	public := jwk.Public()
+	thumbprint, err := public.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	jwk.KeyID = base64.RawURLEncoding.EncodeToString(thumbprint)
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: jwk}, (&jose.SignerOptions{}).WithType("JWT"))
+	if err != nil {
+		return nil, err
+	}
+	return &Local{issuer: issuer, key: jwk, signer: signer}, nil
+}
+
+// NewLocalFromEnv builds a Local issuer from AUTH_LOCAL_KEY_FILE, a PEM RSA private
+// key. Without one it generates a key, so tokens stop working when the server restarts.
+func NewLocalFromEnv() (*Local, error) {
+	issuer := os.Getenv("AUTH_LOCAL_ISSUER")
+	if issuer == "" {
+		issuer = DefaultLocalIssuer
+	}
+
+	path := os.Getenv("AUTH_LOCAL_KEY_FILE")
+	if path == "" {
+		log.Println("AUTH_LOCAL_KEY_FILE not set; local tokens are signed with a throwaway key")
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		return NewLocal(issuer, key)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading AUTH_LOCAL_KEY_FILE: %w", err)
+	}
+	key, err := parseRSAKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("AUTH_LOCAL_KEY_FILE: %w", err)
+	}
+	return NewLocal(issuer, key)
+}
+
+// Issue signs a token for id that expires after ttl.
+func (l *Local) Issue(id Identity, ttl time.Duration) (string, error) {
+	if id.Subject == "" {
+		return "", errors.New("a token needs a subject")
+	}
+	now := time.Now()
+	return jwt.Signed(l.signer).Claims(localClaims{
+		Claims: jwt.Claims{
+			Issuer:    l.issuer,
+			Subject:   id.Subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Expiry:    jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Email: id.Email,
+		Name:  id.Name,
+	}).CompactSerialize()
+}
+
+// JWKS returns the public half of the signing key, for clients that verify tokens
+// themselves.
+func (l *Local) JWKS() jose.JSONWebKeySet {
+	return jose.JSONWebKeySet{Keys: []jose.JSONWebKey{l.key.Public()}}
+}
+
+// Authenticate verifies a token issued by l. Name and email come from the token itself.
+func (l *Local) Authenticate(ctx context.Context, token string) (Identity, error) {
+	parsed, err := jwt.ParseSigned(token)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if len(parsed.Headers) != 1 || parsed.Headers[0].Algorithm != string(jose.RS256) || parsed.Headers[0].KeyID != l.key.KeyID {
+		return Identity{}, fmt.Errorf("%w: not signed by this issuer", ErrInvalidToken)
+	}
+
+	var claims localClaims
+	if err := parsed.Claims(l.key.Public().Key, &claims); err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Expiry == nil || claims.Subject == "" {
+		return Identity{}, fmt.Errorf("%w: missing exp or sub", ErrInvalidToken)
+	}
+	if err := claims.Claims.ValidateWithLeeway(jwt.Expected{Issuer: l.issuer, Time: time.Now()}, jwt.DefaultLeeway); err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return Identity{Subject: claims.Subject, Email: claims.Email, Name: claims.Name}, nil
+}
+
+func parseRSAKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an RSA private key")
+	}
+	return key, nil
+}
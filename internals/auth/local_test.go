@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestLocal(t *testing.T) *Local {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := NewLocal(DefaultLocalIssuer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return local
+}
+
+func TestLocalRoundTrip(t *testing.T) {
+	local := newTestLocal(t)
+	want := Identity{Subject: "user_123", Email: "sam@example.com", Name: "Sam"}
+
+	token, err := local.Issue(want, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := local.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Authenticate() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLocalRejects(t *testing.T) {
+	local := newTestLocal(t)
+	other := newTestLocal(t)
+
+	expired, err := local.Issue(Identity{Subject: "user_123"}, -time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, err := other.Issue(Identity{Subject: "user_123"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{
+		"expired":      expired,
+		"foreign key":  foreign,
+		"not a token":  "garbage",
+		"empty string": "",
+	} {
+		if _, err := local.Authenticate(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: err = %v, want ErrInvalidToken", name, err)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Package avatars turns uploaded profile pictures into square JPEG thumbnails, and
+// removes the stored images of avatars that have been replaced.
+package avatars
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"net/http"
+	"slices"
+
+	// Decoders for the formats Process accepts.
+	_ "image/gif"
+	_ "image/png"
+)
+
+// Sizes are the edge lengths, in pixels, of the thumbnails made for each avatar,
+// smallest first. The profile picture URL points at the largest.
+var Sizes = []int{96, 256, 512}
+
+const (
+	// MediaType is the type of every thumbnail.
+	MediaType = "image/jpeg"
+
+	// MaxUploadBytes is the largest file accepted.
+	MaxUploadBytes = 10 << 20
+
+	// MinSide is the shortest an image's shorter side may be.
+	MinSide = 64
+
+	// maxPixels stops small files that decode to huge images from exhausting memory.
+	maxPixels = 50_000_000
+
+	jpegQuality = 85
+)
+
+var (
+	ErrUnsupported = errors.New("avatars must be JPEG, PNG or GIF images")
+	ErrTooSmall    = fmt.Errorf("avatars must be at least %dx%d pixels", MinSide, MinSide)
+	ErrTooLarge    = errors.New("image is too large")
+)
+
+// formats maps the content types the upload is sniffed as to the decoder that must
+// then read it, so a file can't pass as one format and be decoded as another.
+var formats = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+// Thumbnail is one size of a processed avatar.
+type Thumbnail struct {
+	Size int
+	Data []byte
+}
+
+// Process checks that data is an image by its content rather than any name or type the
+// client gave, and returns a square JPEG thumbnail for each of Sizes, in that order.
+// The image is turned upright according to its EXIF orientation and cropped to its
+// centre. Re-encoding drops all metadata, EXIF location included; transparency becomes
+// white.
+func Process(data []byte) ([]Thumbnail, error) {
+	if len(data) > MaxUploadBytes {
+		return nil, ErrTooLarge
+	}
+	format, ok := formats[http.DetectContentType(data)]
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	cfg, decoded, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || decoded != format {
+		return nil, ErrUnsupported
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+	if min(cfg.Width, cfg.Height) < MinSide {
+		return nil, ErrTooSmall
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	src, side := centreSquare(img, orientation)
+	largest := resize(src, side, slices.Max(Sizes))
+
+	thumbs := make([]Thumbnail, 0, len(Sizes))
+	for _, size := range Sizes {
+		thumb := largest
+		if size != largest.Rect.Dx() {
+			thumb = resize(rgbaSampler(largest), largest.Rect.Dx(), size)
+		}
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		thumbs = append(thumbs, Thumbnail{Size: size, Data: buf.Bytes()})
+	}
+	return thumbs, nil
+}
+
+// sampler returns the colour at a point as 16-bit channels, already composited onto
+// white.
+type sampler func(x, y int) (r, g, b uint32)
+
+// centreSquare returns a sampler over the largest centred square of img once turned
+// upright, and that square's side.
+func centreSquare(img image.Image, orientation int) (sampler, int) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	ow, oh := w, h
+	if orientation >= 5 {
+		ow, oh = h, w
+	}
+	side := min(ow, oh)
+	ox, oy := (ow-side)/2, (oh-side)/2
+
+	return func(x, y int) (uint32, uint32, uint32) {
+		sx, sy := orient(orientation, x+ox, y+oy, w, h)
+		r, g, b, a := img.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+		return r + 0xffff - a, g + 0xffff - a, b + 0xffff - a
+	}, side
+}
+
+func rgbaSampler(img *image.RGBA) sampler {
+	return func(x, y int) (uint32, uint32, uint32) {
+		i := img.PixOffset(x, y)
+		return uint32(img.Pix[i]) * 0x101, uint32(img.Pix[i+1]) * 0x101, uint32(img.Pix[i+2]) * 0x101
+	}
+}
+
+// resize scales a square of side srcSide to size by averaging the source pixels each
+// target pixel covers. Images smaller than size are scaled up by repeating pixels.
+func resize(src sampler, srcSide, size int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for dy := 0; dy < size; dy++ {
+		y0 := dy * srcSide / size
+		y1 := max((dy+1)*srcSide/size, y0+1)
+		for dx := 0; dx < size; dx++ {
+			x0 := dx * srcSide / size
+			x1 := max((dx+1)*srcSide/size, x0+1)
+
+			var r, g, b, n uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					pr, pg, pb := src(x, y)
+					r, g, b, n = r+uint64(pr), g+uint64(pg), b+uint64(pb), n+1
+				}
+			}
+			i := dst.PixOffset(dx, dy)
+			dst.Pix[i] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(b / n >> 8)
+			dst.Pix[i+3] = 0xff
+		}
+	}
+	return dst
+}
@@ -0,0 +1,171 @@
+package avatars
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+var (
+	red  = color.RGBA{R: 0xff, A: 0xff}
+	blue = color.RGBA{B: 0xff, A: 0xff}
+)
+
+// halves returns a w×h image whose left half is red and right half blue.
+func halves(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := red
+			if x >= w/2 {
+				c = blue
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withOrientation inserts an EXIF segment carrying the orientation tag after the SOI
+// marker of a JPEG.
+func withOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, exifOrientationTag)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+
+	out := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	out = binary.BigEndian.AppendUint16(out, uint16(len(segment)+2))
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func near(c color.Color, want color.RGBA) bool {
+	r, g, b, _ := c.RGBA()
+	d := func(a uint32, b uint8) bool { return int(a>>8)-int(b) < 40 && int(b)-int(a>>8) < 40 }
+	return d(r, want.R) && d(g, want.G) && d(b, want.B)
+}
+
+func decode(t *testing.T, thumb Thumbnail) image.Image {
+	t.Helper()
+	img, format, err := image.Decode(bytes.NewReader(thumb.Data))
+	if err != nil || format != "jpeg" {
+		t.Fatalf("thumbnail %d is not a JPEG: %v", thumb.Size, err)
+	}
+	return img
+}
+
+func TestProcessMakesSquareThumbnails(t *testing.T) {
+	thumbs, err := Process(encodePNG(t, halves(300, 150)))
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if len(thumbs) != len(Sizes) {
+		t.Fatalf("got %d thumbnails, want %d", len(thumbs), len(Sizes))
+	}
+	for i, thumb := range thumbs {
+		if thumb.Size != Sizes[i] {
+			t.Errorf("thumbnail %d has size %d, want %d", i, thumb.Size, Sizes[i])
+		}
+		img := decode(t, thumb)
+		if b := img.Bounds(); b.Dx() != thumb.Size || b.Dy() != thumb.Size {
+			t.Errorf("thumbnail %d is %dx%d", thumb.Size, b.Dx(), b.Dy())
+		}
+		// The centre square keeps the red and blue halves side by side.
+		if !near(img.At(2, 2), red) || !near(img.At(thumb.Size-3, 2), blue) {
+			t.Errorf("thumbnail %d was not cropped to the centre", thumb.Size)
+		}
+	}
+}
+
+func TestProcessAppliesOrientationAndStripsEXIF(t *testing.T) {
+	// Orientation 6 means the stored image must be turned clockwise, so the red left
+	// half ends up on top.
+	data := withOrientation(encodeJPEG(t, halves(200, 100)), 6)
+	if jpegOrientation(data) != 6 {
+		t.Fatalf("jpegOrientation = %d, want 6", jpegOrientation(data))
+	}
+
+	thumbs, err := Process(data)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	img := decode(t, thumbs[0])
+	size := thumbs[0].Size
+	if !near(img.At(2, 2), red) || !near(img.At(2, size-3), blue) {
+		t.Errorf("image was not turned upright")
+	}
+	if bytes.Contains(thumbs[0].Data, []byte("Exif\x00\x00")) {
+		t.Errorf("thumbnail still has EXIF metadata")
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"text", []byte("definitely not an image"), ErrUnsupported},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), ErrUnsupported},
+		{"truncated", encodePNG(t, halves(100, 100))[:40], ErrUnsupported},
+		{"too small", encodePNG(t, halves(MinSide-1, 200)), ErrTooSmall},
+		{"too big", make([]byte, MaxUploadBytes+1), ErrTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Process(tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("Process error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// Every orientation must map the upright h×w (or w×h) image onto the stored
+	// image one to one.
+	const w, h = 4, 3
+	for o := 1; o <= 8; o++ {
+		ow, oh := w, h
+		if o >= 5 {
+			ow, oh = h, w
+		}
+		seen := map[[2]int]bool{}
+		for y := 0; y < oh; y++ {
+			for x := 0; x < ow; x++ {
+				sx, sy := orient(o, x, y, w, h)
+				if sx < 0 || sx >= w || sy < 0 || sy >= h || seen[[2]int{sx, sy}] {
+					t.Fatalf("orientation %d maps (%d,%d) to (%d,%d)", o, x, y, sx, sy)
+				}
+				seen[[2]int{sx, sy}] = true
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package avatars
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// DefaultInterval is how often the sweeper looks for replaced avatars.
+	DefaultInterval = time.Hour
+
+	// GracePeriod is how long a replaced avatar's images are kept, so clients that
+	// cached the old URL keep showing it until they next refresh the profile.
+	GracePeriod = 24 * time.Hour
+
+	sweepBatchSize = 100
+)
+
+// Deleter removes stored objects. Deleting a key that doesn't exist must not be an
+// error, so a sweep interrupted after deleting some objects can be retried.
+type Deleter interface {
+	Delete(ctx context.Context, key string) error
+}
+
+// Sweeper deletes the stored images of replaced avatars once GracePeriod has passed.
+type Sweeper struct {
+	queries  tabmate.Querier
+	objects  Deleter
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewSweeper(queries tabmate.Querier, objects Deleter) *Sweeper {
+	return &Sweeper{
+		queries:  queries,
+		objects:  objects,
+		interval: DefaultInterval,
+		now:      time.Now,
+	}
+}
+
+// Run sweeps every interval until ctx is cancelled.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunOnce(ctx); err != nil {
+			log.Printf("[avatars] sweep failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce deletes one batch of expired avatars. An avatar whose objects can't all be
+// deleted keeps its row and is retried on the next run.
+func (s *Sweeper) RunOnce(ctx context.Context) error {
+	expired, err := s.queries.ListReplacedAvatars(ctx, tabmate.ListReplacedAvatarsParams{
+		ReplacedAt: pgtype.Timestamptz{Time: s.now().Add(-GracePeriod), Valid: true},
+		Limit:      sweepBatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("list replaced avatars: %w", err)
+	}
+
+	for _, avatar := range expired {
+		if err := s.remove(ctx, avatar); err != nil {
+			log.Printf("[avatars] failed to remove avatar %x: %v", avatar.ID.Bytes, err)
+		}
+	}
+	return nil
+}
+
+func (s *Sweeper) remove(ctx context.Context, avatar tabmate.Avatars) error {
+	for _, key := range avatar.ObjectKeys {
+		if err := s.objects.Delete(ctx, key); err != nil {
+			return fmt.Errorf("delete %s: %w", key, err)
+		}
+	}
+	return s.queries.DeleteAvatar(ctx, avatar.ID)
+}
@@ -0,0 +1,71 @@
+package avatars
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type avatarQueries struct {
+	tabmate.Querier
+	avatars []tabmate.Avatars
+	before  time.Time
+}
+
+func (q *avatarQueries) ListReplacedAvatars(ctx context.Context, arg tabmate.ListReplacedAvatarsParams) ([]tabmate.Avatars, error) {
+	q.before = arg.ReplacedAt.Time
+	return q.avatars, nil
+}
+
+func (q *avatarQueries) DeleteAvatar(ctx context.Context, id pgtype.UUID) error {
+	for i, a := range q.avatars {
+		if a.ID == id {
+			q.avatars = append(q.avatars[:i], q.avatars[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+type objectStore struct {
+	deleted []string
+	fail    string
+}
+
+func (s *objectStore) Delete(ctx context.Context, key string) error {
+	if key == s.fail {
+		return errors.New("unavailable")
+	}
+	s.deleted = append(s.deleted, key)
+	return nil
+}
+
+func TestSweeperRunOnce(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	queries := &avatarQueries{avatars: []tabmate.Avatars{
+		{ID: pgtype.UUID{Bytes: [16]byte{1}, Valid: true}, ObjectKeys: []string{"a/96.jpg", "a/512.jpg"}},
+		{ID: pgtype.UUID{Bytes: [16]byte{2}, Valid: true}, ObjectKeys: []string{"b/96.jpg", "b/512.jpg"}},
+	}}
+	objects := &objectStore{fail: "b/512.jpg"}
+	s := NewSweeper(queries, objects)
+	s.now = func() time.Time { return now }
+
+	if err := s.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if !queries.before.Equal(now.Add(-GracePeriod)) {
+		t.Errorf("swept avatars replaced before %v, want %v", queries.before, now.Add(-GracePeriod))
+	}
+	if len(objects.deleted) != 3 {
+		t.Errorf("deleted %v", objects.deleted)
+	}
+	// The avatar whose objects weren't all deleted stays for the next run.
+	if len(queries.avatars) != 1 || queries.avatars[0].ID.Bytes[0] != 2 {
+		t.Errorf("remaining avatars = %v", queries.avatars)
+	}
+}
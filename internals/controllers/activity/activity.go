@@ -25,7 +25,8 @@ type ActivityEventResponse struct {
 	CreatedAt  time.Time       `json:"created_at"`
 }
 
-func toResponse(e tabmate.ActivityEvents) ActivityEventResponse {
+// ToResponse converts a stored event to the shape the client sees.
+func ToResponse(e tabmate.ActivityEvents) ActivityEventResponse {
 	metadata := json.RawMessage(e.Metadata)
 	if len(metadata) == 0 {
 		metadata = json.RawMessage("{}")
@@ -76,7 +77,7 @@ func GetActivityFeed(queries tabmate.Querier) gin.HandlerFunc {
 
 		resp := make([]ActivityEventResponse, len(events))
 		for i, e := range events {
-			resp[i] = toResponse(e)
+			resp[i] = ToResponse(e)
 		}
 		c.JSON(http.StatusOK, resp)
 	}
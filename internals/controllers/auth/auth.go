@@ -1,9 +1,13 @@
-// Package controllers handles HTTP requests. Auth is now managed by Clerk on the frontend.
-// This file retains only web-facing handlers needed for HTML templates.
+// Package controllers handles HTTP requests. Auth is managed by Clerk on the frontend;
+// this file holds the web-facing handlers needed for HTML templates, and the local
+// token issuer used in development.
 package controllers
 
 import (
 	"net/http"
+	"time"
+
+	"tabmate/internals/auth"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,3 +25,46 @@ func HandleProfile(c *gin.Context) {
 		"email":    email,
 	})
 }
+
+type LocalTokenRequest struct {
+	Subject string `json:"sub" binding:"required"`
+	Email   string `json:"email"`
+	Name    string `json:"name"`
+	TTLMins int    `json:"ttl_minutes"` // defaults to a day
+}
+
+// LocalJWKS serves the local issuer's public key. Only registered when
+// AUTH_PROVIDER=local.
+// GET /.well-known/jwks.json
+func LocalJWKS(issuer *auth.Local) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, issuer.JWKS())
+	}
+}
+
+// IssueLocalToken signs a token for whoever the request names, for development and
+// integration tests. Only registered when AUTH_PROVIDER=local.
+// POST /api/auth/local/token
+func IssueLocalToken(issuer *auth.Local) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req LocalTokenRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sub is required"})
+			return
+		}
+		ttl := 24 * time.Hour
+		if req.TTLMins > 0 {
+			ttl = time.Duration(req.TTLMins) * time.Minute
+		}
+
+		token, err := issuer.Issue(auth.Identity{Subject: req.Subject, Email: req.Email, Name: req.Name}, ttl)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue token"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"token":      token,
+			"expires_at": time.Now().Add(ttl),
+		})
+	}
+}
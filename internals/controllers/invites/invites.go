@@ -0,0 +1,413 @@
+package invitecontroller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	activity "tabmate/internals/controllers/activity"
+	splitcontroller "tabmate/internals/controllers/splits"
+	"tabmate/internals/invites"
+	"tabmate/internals/middleware"
+	"tabmate/internals/notifications"
+	"tabmate/internals/placeholders"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CreateInviteRequest struct {
+	// PlaceholderName, if set, adds a placeholder member with this name straight away;
+	// whoever accepts the link takes over their items and balance.
+	PlaceholderName string `json:"placeholder_name"`
+	ExpiresInHours  int    `json:"expires_in_hours"` // defaults to 7 days, at most 30
+}
+
+// CreateSplitInvite creates a signed invite link to a split.
+// POST /api/splits/:code/invites
+func CreateSplitInvite(pool *pgxpool.Pool, signer *invites.Signer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		split, _ := middleware.AuthorizedSplit(c)
+
+		createInvite(c, pool, signer, tabmate.CreateInviteLinkParams{SplitID: split.ID, CreatedBy: pgUserID},
+			func(q tabmate.Querier, placeholderID pgtype.UUID) error {
+				_, _, err := splitcontroller.JoinAsGuest(c, q, split, placeholderID)
+				return err
+			})
+	}
+}
+
+// CreateTableInvite creates a signed invite link to a table.
+// POST /api/tables/:code/invites
+func CreateTableInvite(pool *pgxpool.Pool, signer *invites.Signer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		table, _ := middleware.AuthorizedTable(c)
+
+		createInvite(c, pool, signer, tabmate.CreateInviteLinkParams{TableID: table.ID, CreatedBy: pgUserID},
+			func(q tabmate.Querier, placeholderID pgtype.UUID) error {
+				_, err := q.AddUserToTable(c, tabmate.AddUserToTableParams{
+					TableID: table.ID,
+					UserID:  placeholderID,
+					Role:    "guest",
+				})
+				return err
+			})
+	}
+}
+
+// createInvite stores an invite and, if asked for, its placeholder member, whom join
+// adds to the split or table.
+func createInvite(c *gin.Context, pool *pgxpool.Pool, signer *invites.Signer, params tabmate.CreateInviteLinkParams, join func(tabmate.Querier, pgtype.UUID) error) {
+	if signer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Invite links are not configured"})
+		return
+	}
+
+	var req CreateInviteRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+	}
+	ttl := invites.DefaultTTL
+	if req.ExpiresInHours != 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+	if ttl <= 0 || ttl > invites.MaxTTL {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("expires_in_hours must be between 1 and %d", int(invites.MaxTTL.Hours()))})
+		return
+	}
+	name := strings.TrimSpace(req.PlaceholderName)
+	if len(name) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "placeholder_name must be at most 100 characters"})
+		return
+	}
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+	params.ExpiresAt = pgtype.Timestamptz{Time: expiresAt, Valid: true}
+
+	tx, err := pool.BeginTx(c, pgx.TxOptions{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invite"})
+		return
+	}
+	defer tx.Rollback(c)
+	txQueries := tabmate.New(tx)
+
+	var placeholder any
+	if name != "" {
+		user, err := txQueries.CreatePlaceholderUser(c, pgtype.Text{String: name, Valid: true})
+		if err == nil {
+			err = join(txQueries, user.ID)
+		}
+		if err != nil {
+			log.Printf("[invites] failed to add placeholder %q: %v", name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invite"})
+			return
+		}
+		params.PlaceholderUserID = user.ID
+		placeholder = gin.H{"id": uuid.UUID(user.ID.Bytes).String(), "name": name}
+	}
+
+	invite, err := txQueries.CreateInviteLink(c, params)
+	if err != nil {
+		log.Printf("[invites] failed to create invite: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invite"})
+		return
+	}
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invite"})
+		return
+	}
+
+	token := signer.Sign(uuid.UUID(invite.ID.Bytes), expiresAt)
+	c.JSON(http.StatusCreated, gin.H{
+		"token":       token,
+		"url":         signer.URL(token),
+		"expires_at":  expiresAt,
+		"placeholder": placeholder,
+	})
+}
+
+// PreviewInvite describes what an invite link leads to, so the app or landing page can
+// show it before the person signs in. It does not reveal the join code.
+// GET /api/invites/:token
+func PreviewInvite(queries tabmate.Querier, signer *invites.Signer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := verifyToken(c, signer)
+		if !ok {
+			return
+		}
+
+		invite, err := queries.GetInviteLinkDetails(c, id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+			return
+		}
+		if msg := unusableReason(invite.RevokedAt, invite.ClaimedAt); msg != "" {
+			c.JSON(http.StatusGone, gin.H{"error": msg})
+			return
+		}
+
+		resp := gin.H{
+			"type":             "split",
+			"name":             invite.SplitName.String,
+			"host_name":        invite.HostName.String,
+			"expires_at":       invite.ExpiresAt.Time,
+			"placeholder_name": nil,
+		}
+		if invite.TableID.Valid {
+			resp["type"] = "table"
+			resp["name"] = invite.TableName.String
+		}
+		if invite.PlaceholderName.Valid {
+			resp["placeholder_name"] = invite.PlaceholderName.String
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// AcceptInvite joins the caller to the invite's split or table. If the invite holds a
+// placeholder, the caller takes it over: its items, claims and balance become theirs,
+// and the link cannot be used again.
+// POST /api/invites/:token/accept
+func AcceptInvite(pool *pgxpool.Pool, queries tabmate.Querier, signer *invites.Signer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		id, ok := verifyToken(c, signer)
+		if !ok {
+			return
+		}
+
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invite"})
+			return
+		}
+		defer tx.Rollback(c)
+		txQueries := tabmate.New(tx)
+
+		invite, err := txQueries.LockInviteLink(c, id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+			return
+		}
+		if msg := unusableReason(invite.RevokedAt, invite.ClaimedAt); msg != "" {
+			c.JSON(http.StatusGone, gin.H{"error": msg})
+			return
+		}
+
+		var target joinTarget
+		if invite.SplitID.Valid {
+			target, err = splitTarget(c, txQueries, invite.SplitID)
+		} else {
+			target, err = tableTarget(c, txQueries, invite.TableID)
+		}
+		var isMember, placeholderIsMember bool
+		if err == nil {
+			isMember, err = target.hasMember(pgUserID)
+		}
+		if err == nil && invite.PlaceholderUserID.Valid {
+			placeholderIsMember, err = target.hasMember(invite.PlaceholderUserID)
+		}
+		if err != nil {
+			log.Printf("[invites] failed to load invite %s target: %v", id.String(), err)
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+			return
+		}
+
+		resp := gin.H{"type": target.kind, "code": target.code, "claimed_placeholder": false}
+		switch {
+		case invite.PlaceholderUserID.Valid && isMember:
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("You're already in this %s, so you can't take over the invited member", target.kind)})
+			return
+		case invite.PlaceholderUserID.Valid:
+			// The link is single use either way; if the host has since removed the
+			// placeholder, the caller simply joins.
+			err = placeholders.Merge(c, txQueries, invite.PlaceholderUserID, pgUserID)
+			if err == nil && !placeholderIsMember {
+				err = target.join(pgUserID)
+			}
+			if err == nil {
+				_, err = txQueries.ClaimInviteLink(c, tabmate.ClaimInviteLinkParams{ID: invite.ID, ClaimedBy: pgUserID})
+			}
+			resp["claimed_placeholder"] = placeholderIsMember
+		case isMember:
+			c.JSON(http.StatusOK, gin.H{"message": "Already a member", "type": target.kind, "code": target.code})
+			return
+		default:
+			err = target.join(pgUserID)
+		}
+		if errors.Is(err, placeholders.ErrOverlap) {
+			c.JSON(http.StatusConflict, gin.H{"error": "You already share a split or table with the invited member, so you can't take them over"})
+			return
+		}
+		if err != nil {
+			log.Printf("[invites] failed to accept invite %s: %v", id.String(), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invite"})
+			return
+		}
+
+		actorName, _ := c.Get("username")
+		err = notifications.Enqueue(c, txQueries, notifications.Notification{
+			UserID:  invite.CreatedBy,
+			SplitID: invite.SplitID,
+			Kind:    notifications.KindMemberJoined,
+			Title:   "Someone joined from your invite",
+			Body:    fmt.Sprintf("%s joined \"%s\"", actorName, target.name),
+			Data:    map[string]string{target.kind + "Code": target.code},
+		})
+		if err != nil && !errors.Is(err, notifications.ErrOptedOut) {
+			log.Printf("[invites] failed to queue join notification: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invite"})
+			return
+		}
+
+		if err := tx.Commit(c); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invite"})
+			return
+		}
+
+		activity.InsertEvent(c, queries, tabmate.InsertActivityEventParams{
+			EventType:  "member_joined",
+			ActorID:    pgUserID,
+			ActorName:  actorName.(string),
+			EntityType: target.kind,
+			EntityCode: target.code,
+			EntityName: target.name,
+		})
+
+		resp["message"] = "Joined " + target.kind
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// RevokeInvite stops an invite link from working. Only its creator can revoke it.
+// DELETE /api/invites/:token
+func RevokeInvite(queries tabmate.Querier, signer *invites.Signer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		id, ok := verifyToken(c, signer)
+		if !ok {
+			return
+		}
+		n, err := queries.RevokeInviteLink(c, tabmate.RevokeInviteLinkParams{ID: id, CreatedBy: pgUserID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invite"})
+			return
+		}
+		if n == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Invite revoked"})
+	}
+}
+
+// joinTarget is the split or table an invite leads to. Its functions use the queries
+// it was loaded with.
+type joinTarget struct {
+	kind      string // "split" or "table"
+	code      string
+	name      string
+	hasMember func(userID pgtype.UUID) (bool, error)
+	join      func(userID pgtype.UUID) error
+}
+
+func splitTarget(c *gin.Context, q tabmate.Querier, splitID pgtype.UUID) (joinTarget, error) {
+	split, err := q.GetSplitByID(c, splitID)
+	if err != nil {
+		return joinTarget{}, err
+	}
+	return joinTarget{
+		kind: "split",
+		code: split.SplitCode,
+		name: split.Name,
+		hasMember: func(userID pgtype.UUID) (bool, error) {
+			_, err := q.GetSplitMember(c, tabmate.GetSplitMemberParams{SplitID: split.ID, UserID: userID})
+			return found(err)
+		},
+		join: func(userID pgtype.UUID) error {
+			_, _, err := splitcontroller.JoinAsGuest(c, q, split, userID)
+			return err
+		},
+	}, nil
+}
+
+func tableTarget(c *gin.Context, q tabmate.Querier, tableID pgtype.UUID) (joinTarget, error) {
+	table, err := q.GetTableByID(c, tableID)
+	if err != nil {
+		return joinTarget{}, err
+	}
+	return joinTarget{
+		kind: "table",
+		code: table.TableCode,
+		name: table.Name.String,
+		hasMember: func(userID pgtype.UUID) (bool, error) {
+			_, err := q.GetTableMember(c, tabmate.GetTableMemberParams{TableID: table.ID, UserID: userID})
+			return found(err)
+		},
+		join: func(userID pgtype.UUID) error {
+			_, err := q.AddUserToTable(c, tabmate.AddUserToTableParams{
+				TableID: table.ID,
+				UserID:  userID,
+				Role:    "guest",
+			})
+			return err
+		},
+	}, nil
+}
+
+func found(err error) (bool, error) {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// verifyToken checks the :token param and writes the error response if it is unusable.
+func verifyToken(c *gin.Context, signer *invites.Signer) (pgtype.UUID, bool) {
+	if signer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Invite links are not configured"})
+		return pgtype.UUID{}, false
+	}
+	id, err := signer.Verify(c.Param("token"), time.Now())
+	switch {
+	case errors.Is(err, invites.ErrExpired):
+		c.JSON(http.StatusGone, gin.H{"error": "This invite link has expired"})
+		return pgtype.UUID{}, false
+	case err != nil:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+		return pgtype.UUID{}, false
+	}
+	return pgtype.UUID{Bytes: id, Valid: true}, true
+}
+
+// unusableReason explains why an invite can no longer be used, or returns "". Only
+// invites with a placeholder are ever claimed; the others stay open until they expire.
+func unusableReason(revokedAt, claimedAt pgtype.Timestamptz) string {
+	switch {
+	case revokedAt.Valid:
+		return "This invite link has been revoked"
+	case claimedAt.Valid:
+		return "This invite link has already been used"
+	}
+	return ""
+}
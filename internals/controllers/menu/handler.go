@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"tabmate/internals/menu"
+	"tabmate/internals/middleware"
 	tabmate "tabmate/internals/store/postgres"
 
 	"github.com/gin-gonic/gin"
@@ -166,11 +167,7 @@ func ExtractMenuFromURL(queries tabmate.Querier) gin.HandlerFunc {
 		}
 
 		// Check current count before hitting external APIs.
-		dbTable, err := queries.GetTableByCode(c.Request.Context(), tableCode)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "table not found"})
-			return
-		}
+		dbTable, _ := middleware.AuthorizedTable(c)
 		if dbTable.UrlExtractCount >= maxExtracts {
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error": "URL extraction limit reached for this table (max 5)",
@@ -0,0 +1,231 @@
+package placeholdercontroller
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	activity "tabmate/internals/controllers/activity"
+	splitcontroller "tabmate/internals/controllers/splits"
+	"tabmate/internals/middleware"
+	"tabmate/internals/placeholders"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9 ()-]{7,20}$`)
+
+type AddPlaceholderRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Email string `json:"email"` // lets the person take the placeholder over once they sign up
+	Phone string `json:"phone"`
+}
+
+// AddSplitPlaceholder adds a member without an account to a split. The host claims
+// items and records payments for them until they sign up.
+// POST /api/splits/:code/placeholders
+func AddSplitPlaceholder(pool *pgxpool.Pool, queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		split, _ := middleware.AuthorizedSplit(c)
+		if split.Status == "settled" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Split is already settled"})
+			return
+		}
+
+		addPlaceholder(c, pool, queries, pgUserID, "split", split.SplitCode, split.Name,
+			func(q tabmate.Querier, placeholderID pgtype.UUID) error {
+				_, _, err := splitcontroller.JoinAsGuest(c, q, split, placeholderID)
+				return err
+			})
+	}
+}
+
+// AddTablePlaceholder adds a member without an account to a table.
+// POST /api/tables/:code/placeholders
+func AddTablePlaceholder(pool *pgxpool.Pool, queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		table, _ := middleware.AuthorizedTable(c)
+
+		addPlaceholder(c, pool, queries, pgUserID, "table", table.TableCode, table.Name.String,
+			func(q tabmate.Querier, placeholderID pgtype.UUID) error {
+				_, err := q.AddUserToTable(c, tabmate.AddUserToTableParams{
+					TableID: table.ID,
+					UserID:  placeholderID,
+					Role:    "guest",
+				})
+				return err
+			})
+	}
+}
+
+// addPlaceholder creates a placeholder from the request body, records its contact
+// details and hands it to join.
+func addPlaceholder(c *gin.Context, pool *pgxpool.Pool, queries tabmate.Querier, hostID pgtype.UUID, kind, code, entityName string, join func(tabmate.Querier, pgtype.UUID) error) {
+	var req AddPlaceholderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	email := strings.TrimSpace(req.Email)
+	phone := strings.TrimSpace(req.Phone)
+	switch {
+	case name == "" || len(name) > 100:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be between 1 and 100 characters"})
+		return
+	case email != "" && !validEmail(email):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email address"})
+		return
+	case phone != "" && !phonePattern.MatchString(phone):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid phone number"})
+		return
+	}
+
+	tx, err := pool.BeginTx(c, pgx.TxOptions{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add placeholder"})
+		return
+	}
+	defer tx.Rollback(c)
+	txQueries := tabmate.New(tx)
+
+	user, err := txQueries.CreatePlaceholderUser(c, pgtype.Text{String: name, Valid: true})
+	if err == nil {
+		_, err = txQueries.CreatePlaceholderProfile(c, tabmate.CreatePlaceholderProfileParams{
+			UserID:    user.ID,
+			CreatedBy: hostID,
+			Email:     pgtype.Text{String: email, Valid: email != ""},
+			Phone:     pgtype.Text{String: phone, Valid: phone != ""},
+		})
+	}
+	if err == nil {
+		err = join(txQueries, user.ID)
+	}
+	if err != nil {
+		log.Printf("[placeholders] failed to add %q to %s %s: %v", name, kind, code, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add placeholder"})
+		return
+	}
+	if err := tx.Commit(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add placeholder"})
+		return
+	}
+
+	actorName, _ := c.Get("username")
+	metadata, _ := json.Marshal(gin.H{"added_by": actorName, "placeholder": true})
+	activity.InsertEvent(c, queries, tabmate.InsertActivityEventParams{
+		EventType:  "member_joined",
+		ActorID:    user.ID,
+		ActorName:  name,
+		EntityType: kind,
+		EntityCode: code,
+		EntityName: entityName,
+		Metadata:   metadata,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"user_id":        uuid.UUID(user.ID.Bytes).String(),
+		"name":           name,
+		"email":          email,
+		"phone":          phone,
+		"is_placeholder": true,
+	})
+}
+
+// ListClaimablePlaceholders lists placeholders a host added with the caller's email,
+// which the caller can take over.
+// GET /api/user/placeholders
+func ListClaimablePlaceholders(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		email, _ := c.Get("email")
+
+		rows, err := queries.ListClaimablePlaceholders(c, email.(string))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch placeholders"})
+			return
+		}
+
+		response := []gin.H{}
+		for _, r := range rows {
+			response = append(response, gin.H{
+				"id":         uuid.UUID(r.ID.Bytes).String(),
+				"name":       r.Name.String,
+				"host_name":  r.HostName.String,
+				"created_at": r.CreatedAt.Time,
+			})
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// ClaimPlaceholder merges a placeholder into the caller: its memberships, claims,
+// items and balances become theirs. Only placeholders recorded with the caller's email
+// can be claimed.
+// POST /api/placeholders/:id/claim
+func ClaimPlaceholder(pool *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+		email, _ := c.Get("email")
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid placeholder ID"})
+			return
+		}
+
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to claim placeholder"})
+			return
+		}
+		defer tx.Rollback(c)
+		txQueries := tabmate.New(tx)
+
+		placeholder, err := txQueries.GetClaimablePlaceholder(c, tabmate.GetClaimablePlaceholderParams{
+			UserID: pgtype.UUID{Bytes: id, Valid: true},
+			Email:  email.(string),
+		})
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Placeholder not found"})
+			return
+		}
+
+		err = placeholders.Merge(c, txQueries, placeholder.ID, pgUserID)
+		if errors.Is(err, placeholders.ErrOverlap) {
+			c.JSON(http.StatusConflict, gin.H{"error": "You're already in a split or table with this placeholder; ask the host to remove it first"})
+			return
+		}
+		if err != nil {
+			log.Printf("[placeholders] failed to merge %s into %s: %v", id.String(), uuid.UUID(pgUserID.Bytes).String(), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to claim placeholder"})
+			return
+		}
+		if err := tx.Commit(c); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to claim placeholder"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Placeholder merged into your account", "name": placeholder.Name.String})
+	}
+}
+
+func validEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
@@ -5,11 +5,16 @@ import (
 	"log"
 	"net/http"
 	activity "tabmate/internals/controllers/activity"
+	"tabmate/internals/middleware"
+	"tabmate/internals/notifications"
+	"tabmate/internals/roles"
 	tabmate "tabmate/internals/store/postgres"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // GetSplitItems returns all items for a split with claim details.
@@ -18,11 +23,7 @@ func GetSplitItems(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		code := c.Param("code")
 
-		split, err := queries.GetSplitByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
-			return
-		}
+		split, _ := middleware.AuthorizedSplit(c)
 
 		items, err := queries.ListSplitItems(c, split.ID)
 		if err != nil {
@@ -72,9 +73,10 @@ func GetSplitItems(queries tabmate.Querier) gin.HandlerFunc {
 	}
 }
 
-// ClaimItem lets a member claim N units of an item.
+// ClaimItem lets a member claim N units of an item. The host can claim for a placeholder
+// member with ?on_behalf_of=<user_id>.
 // POST /api/splits/:code/items/:itemId/claim
-func ClaimItem(queries tabmate.Querier) gin.HandlerFunc {
+func ClaimItem(pool *pgxpool.Pool, queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		code := c.Param("code")
 		itemIDStr := c.Param("itemId")
@@ -90,18 +92,10 @@ func ClaimItem(queries tabmate.Querier) gin.HandlerFunc {
 			return
 		}
 
-		// Verify split exists and user is a member
-		split, err := queries.GetSplitByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
-			return
-		}
+		split, member := middleware.AuthorizedSplit(c)
 
-		if _, err := queries.GetSplitMember(c, tabmate.GetSplitMemberParams{
-			SplitID: split.ID,
-			UserID:  pgUserID,
-		}); err != nil {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You are not a member of this split"})
+		claimantID, ok := claimantFor(c, queries, split, member)
+		if !ok {
 			return
 		}
 
@@ -121,8 +115,8 @@ func ClaimItem(queries tabmate.Querier) gin.HandlerFunc {
 
 		// Check if user already has a claim on this item
 		existingClaim, existingErr := queries.GetSplitItemClaim(c, tabmate.GetSplitItemClaimParams{
-			SplitItemID:      pgItemID,
-			ClaimedByUserID:  pgUserID,
+			SplitItemID:     pgItemID,
+			ClaimedByUserID: claimantID,
 		})
 
 		// Calculate how much of remaining_qty this new claim uses
@@ -141,10 +135,18 @@ func ClaimItem(queries tabmate.Querier) gin.HandlerFunc {
 			return
 		}
 
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to claim item"})
+			return
+		}
+		defer tx.Rollback(c)
+		txQueries := tabmate.New(tx)
+
 		// Upsert the claim
-		if _, err := queries.AddSplitItemClaim(c, tabmate.AddSplitItemClaimParams{
+		if _, err := txQueries.AddSplitItemClaim(c, tabmate.AddSplitItemClaimParams{
 			SplitItemID:     pgItemID,
-			ClaimedByUserID: pgUserID,
+			ClaimedByUserID: claimantID,
 			QuantityClaimed: int32(body.Quantity),
 		}); err != nil {
 			log.Printf("Error upserting claim: %v", err)
@@ -154,7 +156,7 @@ func ClaimItem(queries tabmate.Querier) gin.HandlerFunc {
 
 		// Update remaining_qty: start from total quantity, subtract all claims
 		newRemaining := availableQty - int32(body.Quantity)
-		if _, err := queries.UpdateSplitItemRemainingQty(c, tabmate.UpdateSplitItemRemainingQtyParams{
+		if _, err := txQueries.UpdateSplitItemRemainingQty(c, tabmate.UpdateSplitItemRemainingQtyParams{
 			ID:           pgItemID,
 			RemainingQty: newRemaining,
 		}); err != nil {
@@ -162,10 +164,10 @@ func ClaimItem(queries tabmate.Querier) gin.HandlerFunc {
 		}
 
 		// Recalculate this member's amount_owed
-		recalculateMemberAmount(c, queries, split, pgUserID)
+		recalculateMemberAmount(c, txQueries, split, claimantID)
 
 		actorName, _ := c.Get("username")
-		activity.InsertEvent(c, queries, tabmate.InsertActivityEventParams{
+		activity.InsertEvent(c, txQueries, tabmate.InsertActivityEventParams{
 			EventType:  "item_claimed",
 			ActorID:    pgUserID,
 			ActorName:  actorName.(string),
@@ -175,6 +177,26 @@ func ClaimItem(queries tabmate.Querier) gin.HandlerFunc {
 			Metadata:   []byte(`{"item_name":"` + item.Name + `"}`),
 		})
 
+		if pgUserID != split.CreatedBy {
+			notify(c, txQueries, notifications.Notification{
+				UserID:  split.CreatedBy,
+				SplitID: split.ID,
+				Kind:    notifications.KindItemClaimed,
+				Title:   "Item claimed",
+				Body:    fmt.Sprintf("%s claimed %d× %s in \"%s\"", actorName.(string), body.Quantity, item.Name, split.Name),
+				Data: map[string]string{
+					"splitCode": split.SplitCode,
+					"splitName": split.Name,
+					"itemName":  item.Name,
+				},
+			})
+		}
+
+		if err := tx.Commit(c); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to claim item"})
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"message":       "Item claimed",
 			"remaining_qty": newRemaining,
@@ -182,7 +204,8 @@ func ClaimItem(queries tabmate.Querier) gin.HandlerFunc {
 	}
 }
 
-// UnclaimItem removes a member's claim on an item.
+// UnclaimItem removes a member's claim on an item, or with ?on_behalf_of=<user_id> the
+// host removes a placeholder member's.
 // DELETE /api/splits/:code/items/:itemId/claim
 func UnclaimItem(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -192,9 +215,10 @@ func UnclaimItem(queries tabmate.Querier) gin.HandlerFunc {
 		userID, _ := c.Get("user_id")
 		pgUserID := userID.(pgtype.UUID)
 
-		split, err := queries.GetSplitByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
+		split, member := middleware.AuthorizedSplit(c)
+
+		claimantID, ok := claimantFor(c, queries, split, member)
+		if !ok {
 			return
 		}
 
@@ -208,7 +232,7 @@ func UnclaimItem(queries tabmate.Querier) gin.HandlerFunc {
 		// Get existing claim to restore quantity
 		claim, err := queries.GetSplitItemClaim(c, tabmate.GetSplitItemClaimParams{
 			SplitItemID:     pgItemID,
-			ClaimedByUserID: pgUserID,
+			ClaimedByUserID: claimantID,
 		})
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "No claim found for this item"})
@@ -218,7 +242,7 @@ func UnclaimItem(queries tabmate.Querier) gin.HandlerFunc {
 		// Delete the claim
 		if err := queries.DeleteSplitItemClaim(c, tabmate.DeleteSplitItemClaimParams{
 			SplitItemID:     pgItemID,
-			ClaimedByUserID: pgUserID,
+			ClaimedByUserID: claimantID,
 		}); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove claim"})
 			return
@@ -235,7 +259,7 @@ func UnclaimItem(queries tabmate.Querier) gin.HandlerFunc {
 		}
 
 		// Recalculate this member's amount_owed
-		recalculateMemberAmount(c, queries, split, pgUserID)
+		recalculateMemberAmount(c, queries, split, claimantID)
 
 		actorName, _ := c.Get("username")
 		activity.InsertEvent(c, queries, tabmate.InsertActivityEventParams{
@@ -251,6 +275,41 @@ func UnclaimItem(queries tabmate.Querier) gin.HandlerFunc {
 	}
 }
 
+// claimantFor returns whose claim the caller's request changes: their own, or with
+// ?on_behalf_of=<user_id> a placeholder member's, which only the host or a co-host who
+// manages items may do. It writes the error response and returns false if the request
+// isn't allowed.
+func claimantFor(c *gin.Context, queries tabmate.Querier, split tabmate.Splits, member tabmate.SplitMembers) (pgtype.UUID, bool) {
+	onBehalfOf := c.Query("on_behalf_of")
+	if onBehalfOf == "" {
+		return member.UserID, true
+	}
+	if !roles.Can(member.Role, member.Permissions, roles.ManageItems) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You aren't allowed to claim for other members"})
+		return pgtype.UUID{}, false
+	}
+
+	targetUUID, err := uuid.Parse(onBehalfOf)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid on_behalf_of user ID"})
+		return pgtype.UUID{}, false
+	}
+	targetID := pgtype.UUID{Bytes: targetUUID, Valid: true}
+	if _, err := queries.GetSplitMember(c, tabmate.GetSplitMemberParams{
+		SplitID: split.ID,
+		UserID:  targetID,
+	}); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found in this split"})
+		return pgtype.UUID{}, false
+	}
+	target, err := queries.GetUserByID(c, targetID)
+	if err != nil || !target.IsPlaceholder {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only claim for placeholder members"})
+		return pgtype.UUID{}, false
+	}
+	return targetID, true
+}
+
 type receiptItemInput struct {
 	Name     string  `json:"name" binding:"required"`
 	Price    float64 `json:"price"`
@@ -316,24 +375,13 @@ type scanItemsRequest struct {
 }
 
 // ReplaceAllSplitItems wipes all existing items (and their claims) and inserts a new set.
-// Host only. PUT /api/splits/:code/items
+// PUT /api/splits/:code/items
 func ReplaceAllSplitItems(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		code := c.Param("code")
 		userID, _ := c.Get("user_id")
 		pgUserID := userID.(pgtype.UUID)
 
-		split, err := queries.GetSplitByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
-			return
-		}
-
-		member, err := queries.GetSplitMember(c, tabmate.GetSplitMemberParams{SplitID: split.ID, UserID: pgUserID})
-		if err != nil || member.Role != "host" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only the host can update receipt items"})
-			return
-		}
+		split, _ := middleware.AuthorizedSplit(c)
 
 		var req scanItemsRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -357,24 +405,13 @@ func ReplaceAllSplitItems(queries tabmate.Querier) gin.HandlerFunc {
 }
 
 // MergeSplitItems appends new items to the existing item list without touching claims.
-// Host only. POST /api/splits/:code/items
+// POST /api/splits/:code/items
 func MergeSplitItems(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		code := c.Param("code")
 		userID, _ := c.Get("user_id")
 		pgUserID := userID.(pgtype.UUID)
 
-		split, err := queries.GetSplitByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
-			return
-		}
-
-		member, err := queries.GetSplitMember(c, tabmate.GetSplitMemberParams{SplitID: split.ID, UserID: pgUserID})
-		if err != nil || member.Role != "host" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only the host can add receipt items"})
-			return
-		}
+		split, _ := middleware.AuthorizedSplit(c)
 
 		var req scanItemsRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -0,0 +1,130 @@
+package splitcontroller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"tabmate/internals/joincodes"
+	"tabmate/internals/middleware"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type JoinCodeRequest struct {
+	ExpiresInHours int `json:"expires_in_hours"` // 0 means the code lasts until rotated or revoked
+}
+
+// GetSplitJoinCode returns the split's live join code, or null if it has none.
+// GET /api/splits/:code/join-code
+func GetSplitJoinCode(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		split, _ := middleware.AuthorizedSplit(c)
+
+		joinCode, err := queries.GetActiveJoinCode(c, tabmate.GetActiveJoinCodeParams{SplitID: split.ID})
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusOK, gin.H{"join_code": nil})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch join code"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"join_code": joinCodeResponse(joinCode)})
+	}
+}
+
+// RotateSplitJoinCode issues a new join code for a split and revokes the previous one.
+// From then on the permanent split code no longer lets new people join.
+// POST /api/splits/:code/join-code
+func RotateSplitJoinCode(pool *pgxpool.Pool, queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		split, _ := middleware.AuthorizedSplit(c)
+		var req JoinCodeRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+				return
+			}
+		}
+
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create join code"})
+			return
+		}
+		defer tx.Rollback(c)
+
+		joinCode, err := joincodes.Rotate(c, tabmate.New(tx), tabmate.CreateJoinCodeParams{
+			SplitID:   split.ID,
+			CreatedBy: pgUserID,
+		}, time.Duration(req.ExpiresInHours)*time.Hour)
+		if errors.Is(err, joincodes.ErrInvalidTTL) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err == nil {
+			err = tx.Commit(c)
+		}
+		if err != nil {
+			log.Printf("[join-codes] failed to rotate join code for split %s: %v", split.SplitCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create join code"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"join_code": joinCodeResponse(joinCode)})
+	}
+}
+
+// RevokeSplitJoinCode revokes the split's join code, closing it to new members until
+// the host issues another.
+// DELETE /api/splits/:code/join-code
+func RevokeSplitJoinCode(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		split, _ := middleware.AuthorizedSplit(c)
+
+		if _, err := queries.RevokeJoinCodes(c, tabmate.RevokeJoinCodesParams{SplitID: split.ID}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke join code"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Join code revoked"})
+	}
+}
+
+// splitToJoin finds the split a code lets the caller join. A live join code always
+// works; the permanent split code only works until the host first issues a join code,
+// which locked reports.
+func splitToJoin(c *gin.Context, queries tabmate.Querier, code string) (split tabmate.Splits, locked bool, err error) {
+	code = joincodes.Normalize(code)
+	if joinCode, err := queries.ResolveJoinCode(c, code); err == nil && joinCode.SplitID.Valid {
+		split, err := queries.GetSplitByID(c, joinCode.SplitID)
+		return split, false, err
+	}
+
+	split, err = queries.GetSplitByCode(c, code)
+	if err != nil {
+		return split, false, err
+	}
+	locked, err = queries.HasJoinCodes(c, tabmate.HasJoinCodesParams{SplitID: split.ID})
+	return split, locked, err
+}
+
+func joinCodeResponse(j tabmate.JoinCodes) gin.H {
+	var expiresAt *time.Time
+	if j.ExpiresAt.Valid {
+		expiresAt = &j.ExpiresAt.Time
+	}
+	return gin.H{
+		"code":       j.Code,
+		"expires_at": expiresAt,
+		"created_at": j.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,205 @@
+package splitcontroller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"tabmate/internals/encryption"
+	"tabmate/internals/middleware"
+	"tabmate/internals/payments"
+	"tabmate/internals/payouts"
+	"tabmate/internals/roles"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	defaultQRSize = 512
+	maxQRSize     = 2048
+)
+
+// GetPaymentRequest returns the deep link and scannable payloads a debtor can use to pay the host.
+// GET /api/splits/:code/members/:userId/payment-request
+func GetPaymentRequest(queries tabmate.Querier, cipher *encryption.Envelope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request, status, msg := loadPaymentRequest(c, queries, cipher)
+		if status != http.StatusOK {
+			c.JSON(status, gin.H{"error": msg})
+			return
+		}
+
+		response := gin.H{
+			"reference": request.Reference,
+			"amount":    request.Amount,
+			"currency":  request.Currency,
+			"payee": gin.H{
+				"name":           request.PayeeName,
+				"bank_name":      request.BankName,
+				"account_number": request.AccountNumber,
+			},
+			"deep_link":   request.DeepLink(),
+			"payto_uri":   nil,
+			"epc_payload": nil,
+		}
+		if uri, err := request.PaytoURI(); err == nil {
+			response["payto_uri"] = uri
+		}
+		if payload, err := request.EPCPayload(); err == nil {
+			response["epc_payload"] = payload
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// GetPaymentQRCode renders a member's payment request as a QR code.
+// Query params: format=png|svg (default png), scheme=link|payto|epc (default link), size=pixels.
+// GET /api/splits/:code/members/:userId/payment-qr
+func GetPaymentQRCode(queries tabmate.Querier, cipher *encryption.Envelope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		size := defaultQRSize
+		if raw := c.Query("size"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 64 || parsed > maxQRSize {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "size must be between 64 and 2048"})
+				return
+			}
+			size = parsed
+		}
+
+		format := c.DefaultQuery("format", "png")
+		if format != "png" && format != "svg" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "format must be png or svg"})
+			return
+		}
+
+		request, status, msg := loadPaymentRequest(c, queries, cipher)
+		if status != http.StatusOK {
+			c.JSON(status, gin.H{"error": msg})
+			return
+		}
+
+		var content string
+		var err error
+		switch c.DefaultQuery("scheme", "link") {
+		case "link":
+			content = request.DeepLink()
+		case "payto":
+			content, err = request.PaytoURI()
+		case "epc":
+			content, err = request.EPCPayload()
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "scheme must be link, payto or epc"})
+			return
+		}
+		if errors.Is(err, payments.ErrUnsupportedFormat) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "The host's account or currency does not support this QR scheme"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build payment request"})
+			return
+		}
+
+		if format == "svg" {
+			image, err := payments.QRCodeSVG(content, size)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code"})
+				return
+			}
+			c.Data(http.StatusOK, "image/svg+xml", image)
+			return
+		}
+
+		image, err := payments.QRCodePNG(content, size)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code"})
+			return
+		}
+		c.Data(http.StatusOK, "image/png", image)
+	}
+}
+
+// loadPaymentRequest builds the payment request for :userId's share of :code. Only the
+// debtor and the host may see it, so the host's bank details are decrypted in full.
+// It returns an HTTP status and message on failure.
+func loadPaymentRequest(c *gin.Context, queries tabmate.Querier, cipher *encryption.Envelope) (payments.PaymentRequest, int, string) {
+	targetUUID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		return payments.PaymentRequest{}, http.StatusBadRequest, "Invalid user ID"
+	}
+	pgTargetID := pgtype.UUID{Bytes: targetUUID, Valid: true}
+
+	split, requester := middleware.AuthorizedSplit(c)
+	if requester.UserID != pgTargetID && !roles.Can(requester.Role, requester.Permissions, roles.ConfirmPayments) {
+		return payments.PaymentRequest{}, http.StatusForbidden, "Only the debtor or the split host can view this payment request"
+	}
+
+	member, err := queries.GetSplitMember(c, tabmate.GetSplitMemberParams{
+		SplitID: split.ID,
+		UserID:  pgTargetID,
+	})
+	if err != nil {
+		return payments.PaymentRequest{}, http.StatusNotFound, "Member not found in this split"
+	}
+	if member.Role == "host" {
+		return payments.PaymentRequest{}, http.StatusBadRequest, "The host does not owe anything on this split"
+	}
+	if member.PaymentStatus == "confirmed" {
+		return payments.PaymentRequest{}, http.StatusBadRequest, "Payment already confirmed"
+	}
+
+	amountFloat, _ := member.AmountOwed.Float64Value()
+	if amountFloat.Float64 <= 0 {
+		return payments.PaymentRequest{}, http.StatusBadRequest, "Nothing to pay on this split"
+	}
+
+	host, err := queries.GetUserByID(c, split.CreatedBy)
+	if err != nil {
+		return payments.PaymentRequest{}, http.StatusInternalServerError, "Failed to load host"
+	}
+
+	// Prefer the payout method chosen for the split (or the host's default) and fall
+	// back to the bank details stored on the host's profile.
+	var payeeName, bankName, accountNumber, bic string
+	method, err := payouts.ForSplit(c, queries, cipher, split)
+	if err != nil {
+		log.Printf("[payments] failed to load payout method for split %s: %v", split.SplitCode, err)
+		return payments.PaymentRequest{}, http.StatusInternalServerError, "Failed to load host bank details"
+	}
+	if method != nil {
+		payeeName, bankName, accountNumber, bic = method.Payee()
+	} else {
+		accountNumber, err = cipher.DecryptText(c, host.AccountNumber)
+		if err == nil {
+			bankName, err = cipher.DecryptText(c, host.BankName)
+		}
+		if err == nil {
+			payeeName, err = cipher.DecryptText(c, host.AccountName)
+		}
+		if err != nil {
+			log.Printf("[payments] failed to decrypt bank details for split %s host: %v", split.SplitCode, err)
+			return payments.PaymentRequest{}, http.StatusInternalServerError, "Failed to load host bank details"
+		}
+	}
+	if accountNumber == "" {
+		return payments.PaymentRequest{}, http.StatusUnprocessableEntity, "The host has not added bank details yet"
+	}
+	if payeeName == "" {
+		payeeName = host.Name.String
+	}
+
+	return payments.PaymentRequest{
+		PayeeName:     payeeName,
+		BankName:      bankName,
+		AccountNumber: accountNumber,
+		BIC:           bic,
+		Amount:        amountFloat.Float64,
+		Currency:      payments.Currency(),
+		Reference:     payments.MemberReference(split.SplitCode, targetUUID.String()),
+	}, http.StatusOK, ""
+}
@@ -0,0 +1,370 @@
+package splitcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	activity "tabmate/internals/controllers/activity"
+	"tabmate/internals/middleware"
+	"tabmate/internals/notifications"
+	"tabmate/internals/payments"
+	"tabmate/internals/roles"
+	tabmate "tabmate/internals/store/postgres"
+	"tabmate/internals/webhooks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CreatePaymentLink starts a provider checkout for the caller's share of a split.
+// POST /api/splits/:code/payment-link
+func CreatePaymentLink(queries tabmate.Querier, provider payments.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if provider == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Payments are not configured"})
+			return
+		}
+
+		code := c.Param("code")
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		split, member := middleware.AuthorizedSplit(c)
+		if member.Role == "host" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "The host does not owe anything on this split"})
+			return
+		}
+		if member.PaymentStatus == "confirmed" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Payment already confirmed"})
+			return
+		}
+
+		amountFloat, _ := member.AmountOwed.Float64Value()
+		if amountFloat.Float64 <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to pay on this split"})
+			return
+		}
+
+		email, _ := c.Get("email")
+		currency := payments.Currency()
+		reference := payments.NewReference(split.SplitCode)
+
+		link, err := provider.CreatePaymentLink(c, payments.LinkRequest{
+			Reference:   reference,
+			Amount:      amountFloat.Float64,
+			Currency:    currency,
+			Email:       fmt.Sprintf("%v", email),
+			Description: split.Name,
+			Metadata: map[string]string{
+				"split_code": split.SplitCode,
+				"user_id":    uuid.UUID(pgUserID.Bytes).String(),
+			},
+		})
+		if err != nil {
+			log.Printf("[payments] %s CreatePaymentLink failed for split %s: %v", provider.Name(), code, err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to create payment link"})
+			return
+		}
+
+		var amountNumeric pgtype.Numeric
+		amountNumeric.Scan(fmt.Sprintf("%.2f", amountFloat.Float64))
+
+		payment, err := queries.CreateSplitPayment(c, tabmate.CreateSplitPaymentParams{
+			SplitID:     split.ID,
+			UserID:      pgUserID,
+			Provider:    provider.Name(),
+			Reference:   link.Reference,
+			Amount:      amountNumeric,
+			Currency:    currency,
+			CheckoutUrl: link.CheckoutURL,
+		})
+		if err != nil {
+			log.Printf("Error storing split payment %s: %v", link.Reference, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create payment"})
+			return
+		}
+
+		c.JSON(http.StatusOK, paymentResponse(payment))
+	}
+}
+
+// GetPaymentStatus reports a payment's state, asking the provider while it is still pending.
+// GET /api/splits/:code/payments/:reference
+func GetPaymentStatus(pool *pgxpool.Pool, queries tabmate.Querier, provider payments.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		split, member := middleware.AuthorizedSplit(c)
+
+		payment, err := queries.GetSplitPaymentByReference(c, c.Param("reference"))
+		if err != nil || payment.SplitID != split.ID {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+			return
+		}
+
+		if payment.UserID != pgUserID && !roles.Can(member.Role, member.Permissions, roles.ConfirmPayments) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the payer or the split host can view this payment"})
+			return
+		}
+
+		if payment.Status == string(payments.StatusPending) && provider != nil && provider.Name() == payment.Provider {
+			status, err := provider.GetPaymentStatus(c, payment.Reference)
+			if err != nil {
+				log.Printf("[payments] %s GetPaymentStatus failed for %s: %v", provider.Name(), payment.Reference, err)
+			} else if updated, err := applyPaymentStatus(c, pool, queries, payment, status); err != nil {
+				log.Printf("[payments] failed to apply status %s to %s: %v", status, payment.Reference, err)
+			} else {
+				payment = updated
+			}
+		}
+
+		c.JSON(http.StatusOK, paymentResponse(payment))
+	}
+}
+
+// PaymentEventHandler applies verified provider payment events to the matching split
+// payment. It is registered with the webhooks dispatcher for the configured provider.
+func PaymentEventHandler(pool *pgxpool.Pool, queries tabmate.Querier) webhooks.HandlerFunc {
+	return func(ctx context.Context, event *webhooks.Event) error {
+		paymentEvent, ok := event.Data.(*payments.WebhookEvent)
+		if !ok {
+			return fmt.Errorf("unexpected %s event data %T", event.Source, event.Data)
+		}
+
+		payment, err := queries.GetSplitPaymentByReference(ctx, paymentEvent.Reference)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Unknown references are acknowledged so the provider stops retrying.
+			log.Printf("[payments] %s webhook for unknown reference %q", event.Source, paymentEvent.Reference)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if payment.Provider != event.Source {
+			log.Printf("[payments] %s webhook for %s payment %s ignored", event.Source, payment.Provider, payment.Reference)
+			return nil
+		}
+
+		if paymentEvent.Status == payments.StatusSucceeded {
+			if !strings.EqualFold(paymentEvent.Currency, payment.Currency) {
+				log.Printf("[payments] %s paid in %q, expected %s", payment.Reference, paymentEvent.Currency, payment.Currency)
+				return nil
+			}
+			expected, _ := payment.Amount.Float64Value()
+			if paymentEvent.Amount+0.005 < expected.Float64 {
+				log.Printf("[payments] %s underpaid: got %.2f, expected %.2f", payment.Reference, paymentEvent.Amount, expected.Float64)
+				return nil
+			}
+		}
+
+		_, err = applyPaymentStatus(ctx, pool, queries, payment, paymentEvent.Status)
+		return err
+	}
+}
+
+// applyPaymentStatus records a provider-reported status and, on success, confirms the
+// member's payment exactly as if the host had confirmed it by hand. A payment link is
+// for the share owed when it was created, so if the member's share has grown since then
+// the payment is recorded but left for the host to confirm. The webhook and a
+// status poll can race on the same payment, so the update only matches a payment that
+// has not succeeded yet, and whichever of them wins it settles the member in the same
+// transaction; the other finds nothing to do.
+func applyPaymentStatus(ctx context.Context, pool *pgxpool.Pool, queries tabmate.Querier, payment tabmate.SplitPayments, status payments.Status) (tabmate.SplitPayments, error) {
+	if payment.Status == string(status) || payment.Status == string(payments.StatusSucceeded) {
+		return payment, nil
+	}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return payment, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := tabmate.New(tx)
+
+	updated, err := qtx.UpdateSplitPaymentStatus(ctx, tabmate.UpdateSplitPaymentStatusParams{
+		Status:    string(status),
+		Reference: payment.Reference,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return queries.GetSplitPaymentByReference(ctx, payment.Reference)
+	}
+	if err != nil {
+		return payment, err
+	}
+	if status != payments.StatusSucceeded {
+		return updated, tx.Commit(ctx)
+	}
+
+	split, err := qtx.GetSplitByID(ctx, payment.SplitID)
+	if err != nil {
+		return payment, err
+	}
+	member, err := qtx.GetSplitMember(ctx, tabmate.GetSplitMemberParams{
+		SplitID: split.ID,
+		UserID:  payment.UserID,
+	})
+	if err != nil {
+		return payment, err
+	}
+
+	amount, _ := payment.Amount.Float64Value()
+	owed, _ := member.AmountOwed.Float64Value()
+	covered := amount.Float64+0.005 >= owed.Float64
+	if covered {
+		if err := settleMemberPayment(ctx, qtx, split, payment.UserID); err != nil {
+			return payment, err
+		}
+	} else {
+		log.Printf("[payments] %s paid %.2f but the member now owes %.2f; left for the host to confirm", payment.Reference, amount.Float64, owed.Float64)
+	}
+
+	actorName := "Someone"
+	if payer, err := qtx.GetUserByID(ctx, payment.UserID); err == nil && payer.Name.Valid {
+		actorName = payer.Name.String
+	}
+	metadata, _ := json.Marshal(gin.H{
+		"reference": payment.Reference,
+		"provider":  payment.Provider,
+		"amount":    fmt.Sprintf("%.2f", amount.Float64),
+		"currency":  payment.Currency,
+		"confirmed": covered,
+	})
+	eventType := "payment_confirmed"
+	if !covered {
+		eventType = "payment_sent"
+	}
+	activity.InsertEvent(ctx, qtx, tabmate.InsertActivityEventParams{
+		EventType:  eventType,
+		ActorID:    payment.UserID,
+		ActorName:  actorName,
+		EntityType: "split",
+		EntityCode: split.SplitCode,
+		EntityName: split.Name,
+		Metadata:   metadata,
+	})
+
+	body := fmt.Sprintf("%s paid you $%.2f for \"%s\"", actorName, amount.Float64, split.Name)
+	if !covered {
+		body = fmt.Sprintf("%s paid you $%.2f for \"%s\" but now owes $%.2f. Confirm it once the rest arrives.", actorName, amount.Float64, split.Name, owed.Float64)
+	}
+	notify(ctx, qtx, notifications.Notification{
+		UserID:  split.CreatedBy,
+		SplitID: split.ID,
+		Kind:    notifications.KindPaymentReceived,
+		Title:   "Payment received 💰",
+		Body:    body,
+		Data: map[string]string{
+			"splitCode": split.SplitCode,
+			"splitName": split.Name,
+			"amount":    fmt.Sprintf("%.2f", amount.Float64),
+		},
+	})
+
+	if err := tx.Commit(ctx); err != nil {
+		return payment, err
+	}
+	return updated, nil
+}
+
+// settleMemberPayment confirms a member's payment and settles the split once nobody owes
+// anything. queries should belong to the transaction confirming the payment, so the
+// notifications it queues are only sent if the confirmation commits.
+func settleMemberPayment(ctx context.Context, queries tabmate.Querier, split tabmate.Splits, userID pgtype.UUID) error {
+	member, err := queries.ConfirmSplitMemberPayment(ctx, tabmate.ConfirmSplitMemberPaymentParams{
+		SplitID: split.ID,
+		UserID:  userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	amount, _ := member.AmountOwed.Float64Value()
+	notify(ctx, queries, notifications.Notification{
+		UserID:  userID,
+		SplitID: split.ID,
+		Kind:    notifications.KindPaymentConfirmed,
+		Title:   "Payment confirmed ✅",
+		Body:    fmt.Sprintf("Your $%.2f payment for \"%s\" has been confirmed", amount.Float64, split.Name),
+		Data: map[string]string{
+			"splitCode": split.SplitCode,
+			"splitName": split.Name,
+			"amount":    fmt.Sprintf("%.2f", amount.Float64),
+		},
+	})
+
+	count, err := queries.CountUnsettledSplitMembers(ctx, split.ID)
+	if err == nil && count == 0 {
+		queries.UpdateSplitStatus(ctx, tabmate.UpdateSplitStatusParams{
+			ID:     split.ID,
+			Status: "settled",
+		})
+		notifySettled(ctx, queries, split)
+	}
+	return nil
+}
+
+// notifySettled sends every member a summary of a split that has just been settled.
+func notifySettled(ctx context.Context, queries tabmate.Querier, split tabmate.Splits) {
+	members, err := queries.ListSplitMembersBySplitID(ctx, split.ID)
+	if err != nil {
+		log.Printf("[notifications] failed to list members of settled split %s: %v", split.SplitCode, err)
+		return
+	}
+
+	total, _ := split.TotalAmount.Float64Value()
+	for _, m := range members {
+		share, _ := m.AmountOwed.Float64Value()
+		notify(ctx, queries, notifications.Notification{
+			UserID:  m.UserID,
+			SplitID: split.ID,
+			Kind:    notifications.KindSplitSettled,
+			Title:   "Split settled 🎉",
+			Body:    fmt.Sprintf("Everyone has paid for \"%s\"", split.Name),
+			Data: map[string]string{
+				"splitCode": split.SplitCode,
+				"splitName": split.Name,
+				"total":     fmt.Sprintf("%.2f", total.Float64),
+				"members":   fmt.Sprint(len(members)),
+				"amount":    fmt.Sprintf("%.2f", share.Float64),
+			},
+		})
+	}
+}
+
+// notify queues n in the caller's transaction. Errors are logged rather than returned;
+// a failed insert still aborts the transaction, so a change and its notification commit
+// or roll back together.
+func notify(ctx context.Context, queries tabmate.Querier, n notifications.Notification) {
+	err := notifications.Enqueue(ctx, queries, n)
+	if err != nil && !errors.Is(err, notifications.ErrOptedOut) {
+		log.Printf("[notifications] failed to queue %s notification: %v", n.Kind, err)
+	}
+}
+
+func paymentResponse(p tabmate.SplitPayments) gin.H {
+	amountFloat, _ := p.Amount.Float64Value()
+
+	var confirmedAt any
+	if p.ConfirmedAt.Valid {
+		confirmedAt = p.ConfirmedAt.Time
+	}
+
+	return gin.H{
+		"reference":    p.Reference,
+		"provider":     p.Provider,
+		"amount":       amountFloat.Float64,
+		"currency":     p.Currency,
+		"status":       p.Status,
+		"checkout_url": p.CheckoutUrl,
+		"created_at":   p.CreatedAt.Time,
+		"confirmed_at": confirmedAt,
+	}
+}
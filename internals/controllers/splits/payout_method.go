@@ -0,0 +1,104 @@
+package splitcontroller
+
+import (
+	"log"
+	"net/http"
+	"tabmate/internals/encryption"
+	"tabmate/internals/middleware"
+	"tabmate/internals/payouts"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// GetSplitPayoutMethod returns where debtors should pay for this split: the method the
+// host picked, or the host's default. Account identifiers are masked for members who
+// no longer owe the host anything.
+// GET /api/splits/:code/payout-method
+func GetSplitPayoutMethod(queries tabmate.Querier, cipher *encryption.Envelope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		code := c.Param("code")
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		split, _ := middleware.AuthorizedSplit(c)
+
+		method, err := payouts.ForSplit(c, queries, cipher, split)
+		if err != nil {
+			log.Printf("[payouts] failed to load payout method for split %s: %v", code, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load payout method"})
+			return
+		}
+		if method == nil {
+			c.JSON(http.StatusOK, gin.H{"payout_method": nil})
+			return
+		}
+
+		full := pgUserID == split.CreatedBy
+		if !full {
+			full, err = queries.UserOwesUser(c, tabmate.UserOwesUserParams{
+				DebtorID:   pgUserID,
+				CreditorID: split.CreatedBy,
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load payout method"})
+				return
+			}
+		}
+		if !full {
+			masked := method.Mask()
+			method = &masked
+		}
+
+		c.JSON(http.StatusOK, gin.H{"payout_method": method})
+	}
+}
+
+type UpdateSplitPayoutMethodRequest struct {
+	PayoutMethodID string `json:"payout_method_id"`
+}
+
+// UpdateSplitPayoutMethod lets the host choose which of their payout methods debtors
+// should use for this split. An empty payout_method_id reverts to the host's default.
+// PUT /api/splits/:code/payout-method
+func UpdateSplitPayoutMethod(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		var req UpdateSplitPayoutMethodRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		split, _ := middleware.AuthorizedSplit(c)
+
+		var methodID pgtype.UUID
+		if req.PayoutMethodID != "" {
+			methodUUID, err := uuid.Parse(req.PayoutMethodID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payout method ID"})
+				return
+			}
+			method, err := queries.GetPayoutMethod(c, pgtype.UUID{Bytes: methodUUID, Valid: true})
+			if err != nil || method.UserID != pgUserID {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Payout method not found"})
+				return
+			}
+			methodID = method.ID
+		}
+
+		if _, err := queries.UpdateSplitPayoutMethod(c, tabmate.UpdateSplitPayoutMethodParams{
+			ID:             split.ID,
+			PayoutMethodID: methodID,
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update payout method"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Payout method updated"})
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"net/http"
 	"strings"
 	activity "tabmate/internals/controllers/activity"
+	"tabmate/internals/joincodes"
 	"tabmate/internals/menu"
+	"tabmate/internals/middleware"
 	"tabmate/internals/storage"
 	tabmate "tabmate/internals/store/postgres"
 
@@ -128,7 +130,7 @@ type CreateSplitFromReceiptRequest struct {
 
 // CreateSplitFromReceipt creates a split with pre-scanned receipt items in a single call.
 // POST /api/create-split-from-receipt
-func CreateSplitFromReceipt(queries tabmate.Querier) gin.HandlerFunc {
+func CreateSplitFromReceipt(queries tabmate.Querier, blobs storage.BlobStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, exists := c.Get("user_id")
 		if !exists {
@@ -155,7 +157,12 @@ func CreateSplitFromReceipt(queries tabmate.Querier) gin.HandlerFunc {
 			totalAmount += req.Tip
 		}
 
-		splitCode := uuid.New().String()[:8]
+		splitCode, err := joincodes.Unique(c, queries.IsCodeInUse)
+		if err != nil {
+			log.Printf("Error generating code: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create split"})
+			return
+		}
 
 		var totalAmountNumeric pgtype.Numeric
 		if err := totalAmountNumeric.Scan(fmt.Sprintf("%.2f", totalAmount)); err != nil {
@@ -195,7 +202,7 @@ func CreateSplitFromReceipt(queries tabmate.Querier) gin.HandlerFunc {
 		}
 
 		if receiptUpload != nil {
-			stored, err := storeSplitReceipt(c, queries, split.ID, splitCode, pgUserID, receiptUpload)
+			stored, err := storeSplitReceipt(c, queries, blobs, split.ID, splitCode, pgUserID, receiptUpload)
 			if err != nil {
 				log.Printf("[receipt] storeSplitReceipt failed for split %s: %v", splitCode, err)
 			} else {
@@ -277,10 +284,9 @@ func CreateSplitFromReceipt(queries tabmate.Querier) gin.HandlerFunc {
 	}
 }
 
-func storeSplitReceipt(ctx *gin.Context, queries tabmate.Querier, splitID pgtype.UUID, splitCode string, userID pgtype.UUID, upload *storedReceiptUpload) (tabmate.SplitReceipts, error) {
-	r2, err := storage.NewR2Client(ctx)
-	if err != nil {
-		return tabmate.SplitReceipts{}, err
+func storeSplitReceipt(ctx *gin.Context, queries tabmate.Querier, blobs storage.BlobStore, splitID pgtype.UUID, splitCode string, userID pgtype.UUID, upload *storedReceiptUpload) (tabmate.SplitReceipts, error) {
+	if blobs == nil {
+		return tabmate.SplitReceipts{}, storage.ErrNotConfigured
 	}
 
 	ext := "jpg"
@@ -291,7 +297,7 @@ func storeSplitReceipt(ctx *gin.Context, queries tabmate.Querier, splitID pgtype
 	}
 
 	key := fmt.Sprintf("receipts/splits/%s/%s.%s", splitCode, uuid.New().String(), ext)
-	object, err := r2.Upload(ctx, key, upload.Bytes, upload.MediaType)
+	object, err := blobs.Upload(ctx, key, upload.Bytes, upload.MediaType)
 	if err != nil {
 		return tabmate.SplitReceipts{}, err
 	}
@@ -306,35 +312,11 @@ func storeSplitReceipt(ctx *gin.Context, queries tabmate.Querier, splitID pgtype
 	})
 }
 
-func userIsSplitMember(ctx *gin.Context, queries tabmate.Querier, splitID pgtype.UUID, userID pgtype.UUID) bool {
-	_, err := queries.GetSplitMember(ctx, tabmate.GetSplitMemberParams{
-		SplitID: splitID,
-		UserID:  userID,
-	})
-	return err == nil
-}
-
 // GetSplitReceipt returns the stored original receipt image for a split.
 // GET /api/splits/:code/receipt
 func GetSplitReceipt(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		code := c.Param("code")
-		userID, exists := c.Get("user_id")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found"})
-			return
-		}
-		pgUserID := userID.(pgtype.UUID)
-
-		split, err := queries.GetSplitByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
-			return
-		}
-		if !userIsSplitMember(c, queries, split.ID, pgUserID) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Not a split member"})
-			return
-		}
+		split, _ := middleware.AuthorizedSplit(c)
 
 		receipt, err := queries.GetSplitReceiptBySplitID(c, split.ID)
 		if err != nil {
@@ -355,7 +337,7 @@ func GetSplitReceipt(queries tabmate.Querier) gin.HandlerFunc {
 
 // UpsertSplitReceipt stores or replaces the original receipt image for a split.
 // POST /api/splits/:code/receipt
-func UpsertSplitReceipt(queries tabmate.Querier) gin.HandlerFunc {
+func UpsertSplitReceipt(queries tabmate.Querier, blobs storage.BlobStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		code := c.Param("code")
 		userID, exists := c.Get("user_id")
@@ -365,24 +347,7 @@ func UpsertSplitReceipt(queries tabmate.Querier) gin.HandlerFunc {
 		}
 		pgUserID := userID.(pgtype.UUID)
 
-		split, err := queries.GetSplitByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
-			return
-		}
-
-		member, err := queries.GetSplitMember(c, tabmate.GetSplitMemberParams{
-			SplitID: split.ID,
-			UserID:  pgUserID,
-		})
-		if err != nil {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Not a split member"})
-			return
-		}
-		if member.Role != "host" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only the host can update the receipt"})
-			return
-		}
+		split, _ := middleware.AuthorizedSplit(c)
 
 		upload, err := readReceiptUpload(c)
 		if err != nil {
@@ -390,7 +355,7 @@ func UpsertSplitReceipt(queries tabmate.Querier) gin.HandlerFunc {
 			return
 		}
 
-		receipt, err := storeSplitReceipt(c, queries, split.ID, code, pgUserID, upload)
+		receipt, err := storeSplitReceipt(c, queries, blobs, split.ID, code, pgUserID, upload)
 		if err != nil {
 			log.Printf("Error storing split receipt: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store receipt"})
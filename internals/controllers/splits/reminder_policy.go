@@ -0,0 +1,82 @@
+package splitcontroller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"tabmate/internals/middleware"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const maxAutoReminders = 10
+
+type ReminderPolicyRequest struct {
+	FirstAfterHours  int32 `json:"first_after_hours"`
+	RepeatEveryHours int32 `json:"repeat_every_hours"`
+	MaxReminders     int32 `json:"max_reminders"`
+	Enabled          bool  `json:"enabled"`
+}
+
+// GetReminderPolicy returns the automatic reminder schedule for a split, or null if
+// the host has not set one up.
+// GET /api/splits/:code/reminder-policy
+func GetReminderPolicy(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		split, _ := middleware.AuthorizedSplit(c)
+
+		policy, err := queries.GetSplitReminderPolicy(c, split.ID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusOK, gin.H{"policy": nil})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load reminder policy"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"policy": policy})
+	}
+}
+
+// UpdateReminderPolicy lets the host schedule automatic payment reminders, e.g. the
+// first one a day after joining, then every three days, at most five times.
+// PUT /api/splits/:code/reminder-policy
+func UpdateReminderPolicy(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		code := c.Param("code")
+
+		var req ReminderPolicyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		if req.FirstAfterHours < 1 || req.RepeatEveryHours < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "first_after_hours and repeat_every_hours must be at least 1"})
+			return
+		}
+		if req.MaxReminders < 1 || req.MaxReminders > maxAutoReminders {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "max_reminders must be between 1 and 10"})
+			return
+		}
+
+		split, _ := middleware.AuthorizedSplit(c)
+
+		policy, err := queries.UpsertSplitReminderPolicy(c, tabmate.UpsertSplitReminderPolicyParams{
+			SplitID:          split.ID,
+			FirstAfterHours:  req.FirstAfterHours,
+			RepeatEveryHours: req.RepeatEveryHours,
+			MaxReminders:     req.MaxReminders,
+			Enabled:          req.Enabled,
+		})
+		if err != nil {
+			log.Printf("[reminders] failed to save policy for split %s: %v", code, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save reminder policy"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"policy": policy})
+	}
+}
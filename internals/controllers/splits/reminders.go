@@ -1,24 +1,30 @@
 package splitcontroller
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"tabmate/internals/middleware"
 	"tabmate/internals/notifications"
 	tabmate "tabmate/internals/store/postgres"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type RemindRequest struct {
 	UserID string `json:"user_id"` // optional — if empty, remind all unsettled members
 }
 
-func RemindMembers(queries tabmate.Querier) gin.HandlerFunc {
+// RemindMembers reminds unsettled members, or just the one named, to pay. The reminders
+// are queued together, so a failure queues none of them and the host can simply retry.
+// POST /api/splits/:code/remind
+func RemindMembers(pool *pgxpool.Pool, queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		code := c.Param("code")
 		requesterID, _ := c.Get("user_id")
 		pgRequesterID := requesterID.(pgtype.UUID)
 
@@ -26,22 +32,7 @@ func RemindMembers(queries tabmate.Querier) gin.HandlerFunc {
 		var req RemindRequest
 		c.ShouldBindJSON(&req) // non-binding — body is optional
 
-		// Fetch split
-		split, err := queries.GetSplitByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
-			return
-		}
-
-		// Only the host can send reminders
-		hostMember, err := queries.GetSplitMember(c, tabmate.GetSplitMemberParams{
-			SplitID: split.ID,
-			UserID:  pgRequesterID,
-		})
-		if err != nil || hostMember.Role != "host" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only the split host can send reminders"})
-			return
-		}
+		split, _ := middleware.AuthorizedSplit(c)
 
 		// Get host name for the notification body
 		hostUser, err := queries.GetUserByID(c, pgRequesterID)
@@ -50,7 +41,7 @@ func RemindMembers(queries tabmate.Querier) gin.HandlerFunc {
 			hostName = hostUser.Name.String
 		}
 
-		// Fetch unsettled members with push tokens
+		// Fetch unsettled members and whether we can reach them
 		unsettled, err := queries.ListUnsettledSplitMembersForReminder(c, split.ID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch members"})
@@ -87,9 +78,17 @@ func RemindMembers(queries tabmate.Querier) gin.HandlerFunc {
 
 		totalAmountFloat, _ := split.TotalAmount.Float64Value()
 
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send reminders"})
+			return
+		}
+		defer tx.Rollback(c)
+		txQueries := tabmate.New(tx)
+
 		sent := 0
 		for _, member := range unsettled {
-			if !member.PushToken.Valid || member.PushToken.String == "" {
+			if !member.Reachable {
 				continue
 			}
 
@@ -99,30 +98,59 @@ func RemindMembers(queries tabmate.Querier) gin.HandlerFunc {
 				memberName = member.UserName.String
 			}
 
-			go func(token, name string, amount float64) {
-				err := notifications.SendExpoPushNotification(notifications.ExpoMessage{
-					To:    token,
-					Title: "Payment reminder 💸",
-					Body: fmt.Sprintf(
-						"%s is reminding you to pay your share of \"%s\" ($%.2f of $%.2f total)",
-						hostName, split.Name, amount, totalAmountFloat.Float64,
-					),
-					Data: map[string]string{
-						"splitCode": split.SplitCode,
-						"type":      "payment_reminder",
-					},
-				})
-				if err != nil {
-					log.Printf("Failed to send reminder to %s: %v", name, err)
-				}
-			}(member.PushToken.String, memberName, amountFloat.Float64)
+			err := notifications.Enqueue(c, txQueries, notifications.Notification{
+				UserID:  member.UserID,
+				SplitID: split.ID,
+				Kind:    notifications.KindPaymentReminder,
+				Title:   "Payment reminder 💸",
+				Body: fmt.Sprintf(
+					"%s is reminding you to pay your share of \"%s\" ($%.2f of $%.2f total)",
+					hostName, split.Name, amountFloat.Float64, totalAmountFloat.Float64,
+				),
+				Data: map[string]string{
+					"splitCode": split.SplitCode,
+					"splitName": split.Name,
+					"amount":    fmt.Sprintf("%.2f", amountFloat.Float64),
+					"type":      "payment_reminder",
+				},
+			})
+			if errors.Is(err, notifications.ErrOptedOut) {
+				continue
+			}
+			if err != nil {
+				log.Printf("Failed to queue reminder for %s: %v", memberName, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send reminders"})
+				return
+			}
 
 			sent++
 		}
 
+		if err := tx.Commit(c); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send reminders"})
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"message": fmt.Sprintf("Reminders sent to %d member(s)", sent),
 			"sent":    sent,
 		})
 	}
 }
+
+// ListSplitNotifications shows the host what has been sent to members of this split
+// (reminders, invitations) and whether each one was delivered.
+// GET /api/splits/:code/notifications
+func ListSplitNotifications(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		split, _ := middleware.AuthorizedSplit(c)
+
+		sent, err := queries.ListSplitNotifications(c, split.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"notifications": sent})
+	}
+}
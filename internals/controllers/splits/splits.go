@@ -1,17 +1,25 @@
 package splitcontroller
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
 	"net/http"
 	activity "tabmate/internals/controllers/activity"
+	"tabmate/internals/directory"
+	"tabmate/internals/hosts"
+	"tabmate/internals/joincodes"
+	"tabmate/internals/middleware"
 	"tabmate/internals/notifications"
+	"tabmate/internals/roles"
 	tabmate "tabmate/internals/store/postgres"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type CreateSplitRequest struct {
@@ -38,7 +46,12 @@ func CreateSplit(queries tabmate.Querier) gin.HandlerFunc {
 			return
 		}
 
-		splitCode := uuid.New().String()[:8]
+		splitCode, err := joincodes.Unique(c, queries.IsCodeInUse)
+		if err != nil {
+			log.Printf("Error generating code: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create split"})
+			return
+		}
 
 		var totalAmount pgtype.Numeric
 		if err := totalAmount.Scan(fmt.Sprintf("%f", req.TotalAmount)); err != nil {
@@ -92,8 +105,8 @@ func JoinSplit(queries tabmate.Querier) gin.HandlerFunc {
 		userID, _ := c.Get("user_id")
 		pgUserID := userID.(pgtype.UUID)
 
-		// Get split
-		split, err := queries.GetSplitByCode(c, code)
+		// Get split, by join code or permanent code
+		split, locked, err := splitToJoin(c, queries, code)
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
 			return
@@ -106,39 +119,21 @@ func JoinSplit(queries tabmate.Querier) gin.HandlerFunc {
 		})
 
 		if err == nil {
-			c.JSON(http.StatusOK, gin.H{"message": "Already a member"})
+			c.JSON(http.StatusOK, gin.H{"message": "Already a member", "code": split.SplitCode})
+			return
+		}
+		if locked {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Ask the host for the current join code"})
 			return
 		}
 
-		// Calculate current split amount (before adding new member)
-		members, _ := queries.ListSplitMembersBySplitID(c, split.ID)
-		newMemberCount := len(members) + 1
-
-		// Total amount as float
-		totalAmountFloat, _ := split.TotalAmount.Float64Value()
-		totalAmount := totalAmountFloat.Float64
-		amountOwed := totalAmount / float64(newMemberCount)
-
-		var amountOwedNumeric pgtype.Numeric
-		amountOwedNumeric.Scan(amountOwed)
-
-		// Add new member
-		_, err = queries.AddUserToSplit(c, tabmate.AddUserToSplitParams{
-			SplitID:    split.ID,
-			UserID:     pgUserID,
-			AmountOwed: amountOwedNumeric,
-			Role:       "guest",
-		})
-
+		amountOwed, newMemberCount, err := JoinAsGuest(c, queries, split, pgUserID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join split"})
 			return
 		}
-
-		// Recalculate split for all members
-		queries.RecalculateSplitForAllMembers(c, split.ID)
-		// For receipt splits, restore claim-based amounts (equal recalc above overwrites them)
-		recalculateAllMembersFromClaims(c, queries, split)
+		totalAmountFloat, _ := split.TotalAmount.Float64Value()
+		totalAmount := totalAmountFloat.Float64
 
 		actorName, _ := c.Get("username")
 		activity.InsertEvent(c, queries, tabmate.InsertActivityEventParams{
@@ -146,12 +141,13 @@ func JoinSplit(queries tabmate.Querier) gin.HandlerFunc {
 			ActorID:    pgUserID,
 			ActorName:  actorName.(string),
 			EntityType: "split",
-			EntityCode: code,
+			EntityCode: split.SplitCode,
 			EntityName: split.Name,
 		})
 
 		c.JSON(http.StatusOK, gin.H{
 			"message":       "Successfully joined split",
+			"code":          split.SplitCode,
 			"amount_owed":   amountOwed,
 			"total_amount":  totalAmount,
 			"members_count": newMemberCount,
@@ -159,15 +155,42 @@ func JoinSplit(queries tabmate.Querier) gin.HandlerFunc {
 	}
 }
 
+// JoinAsGuest adds userID to split as a guest and rebalances everyone's share. It
+// returns the equal share at the time of joining and the new member count.
+func JoinAsGuest(c *gin.Context, queries tabmate.Querier, split tabmate.Splits, userID pgtype.UUID) (float64, int, error) {
+	// Calculate current split amount (before adding new member)
+	members, _ := queries.ListSplitMembersBySplitID(c, split.ID)
+	newMemberCount := len(members) + 1
+
+	// Total amount as float
+	totalAmountFloat, _ := split.TotalAmount.Float64Value()
+	amountOwed := totalAmountFloat.Float64 / float64(newMemberCount)
+
+	var amountOwedNumeric pgtype.Numeric
+	amountOwedNumeric.Scan(amountOwed)
+
+	// Add new member
+	_, err := queries.AddUserToSplit(c, tabmate.AddUserToSplitParams{
+		SplitID:    split.ID,
+		UserID:     userID,
+		AmountOwed: amountOwedNumeric,
+		Role:       "guest",
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Recalculate split for all members
+	queries.RecalculateSplitForAllMembers(c, split.ID)
+	// For receipt splits, restore claim-based amounts (equal recalc above overwrites them)
+	recalculateAllMembersFromClaims(c, queries, split)
+
+	return amountOwed, newMemberCount, nil
+}
+
 func GetSplitByCode(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		code := c.Param("code")
-
-		split, err := queries.GetSplitByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
-			return
-		}
+		split, _ := middleware.AuthorizedSplit(c)
 
 		totalAmountFloat, _ := split.TotalAmount.Float64Value()
 		taxAmountFloat, _ := split.TaxAmount.Float64Value()
@@ -199,15 +222,17 @@ func GetSplitByCode(queries tabmate.Querier) gin.HandlerFunc {
 
 func GetSplitMembers(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		code := c.Param("code")
+		split, _ := middleware.AuthorizedSplit(c)
 
-		split, err := queries.GetSplitByCode(c, code)
+		members, err := queries.ListSplitMembersWithUserDetails(c, split.ID)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch members"})
 			return
 		}
 
-		members, err := queries.ListSplitMembersWithUserDetails(c, split.ID)
+		// Only the caller's friends' emails are shown.
+		userID, _ := c.Get("user_id")
+		emails, err := directory.LoadEmailFilter(c, queries, userID.(pgtype.UUID))
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch members"})
 			return
@@ -220,12 +245,14 @@ func GetSplitMembers(queries tabmate.Querier) gin.HandlerFunc {
 			response = append(response, gin.H{
 				"user_id":        uuid.UUID(m.UserID.Bytes).String(),
 				"name":           m.UserName.String,
-				"email":          m.UserEmail,
+				"email":          emails.Email(m.UserID, m.UserEmail),
 				"role":           m.Role,
+				"permissions":    m.Permissions,
 				"amount_owed":    amountOwedFloat.Float64,
 				"is_settled":     m.IsSettled,
 				"payment_status": m.PaymentStatus,
 				"joined_at":      m.JoinedAt.Time,
+				"is_placeholder": m.UserIsPlaceholder,
 			})
 		}
 
@@ -233,47 +260,91 @@ func GetSplitMembers(queries tabmate.Querier) gin.HandlerFunc {
 	}
 }
 
-func LeaveSplit(queries tabmate.Querier) gin.HandlerFunc {
+// LeaveSplit removes the caller from a split. If the host leaves, the longest-standing
+// co-host takes over, or failing that the longest-standing member with an account; a
+// host with nobody to take over has to delete the split instead.
+// DELETE /api/splits/:code/leave
+func LeaveSplit(pool *pgxpool.Pool, queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		code := c.Param("code")
 		userID, _ := c.Get("user_id")
 		pgUserID := userID.(pgtype.UUID)
 
-		split, err := queries.GetSplitByCode(c, code)
+		split, member := middleware.AuthorizedSplit(c)
+
+		var (
+			newHostID pgtype.UUID
+			err       error
+		)
+		if member.Role == roles.Host {
+			newHostID, err = queries.NextSplitHost(c, tabmate.NextSplitHostParams{
+				SplitID: split.ID,
+				UserID:  pgUserID,
+			})
+			if errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Nobody else can host this split; delete it instead"})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave split"})
+				return
+			}
+		}
+
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave split"})
 			return
 		}
+		defer tx.Rollback(c)
+		qtx := tabmate.New(tx)
 
-		err = queries.RemoveUserFromSplit(c, tabmate.RemoveUserFromSplitParams{
-			SplitID: split.ID,
-			UserID:  pgUserID,
-		})
-
+		if newHostID.Valid {
+			err = hosts.HandOverSplit(c, qtx, split.ID, pgUserID, newHostID, false)
+		}
+		if err == nil {
+			err = qtx.RemoveUserFromSplit(c, tabmate.RemoveUserFromSplitParams{
+				SplitID: split.ID,
+				UserID:  pgUserID,
+			})
+		}
+		if err == nil {
+			err = tx.Commit(c)
+		}
 		if err != nil {
+			log.Printf("[splits] failed to leave split %s: %v", code, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave split"})
 			return
 		}
+		if newHostID.Valid {
+			logHostChange(c, queries, split, pgUserID, newHostID)
+		}
 
 		// Recalculate for remaining members
 		queries.RecalculateSplitForAllMembers(c, split.ID)
 		recalculateAllMembersFromClaims(c, queries, split)
 
-		c.JSON(http.StatusOK, gin.H{"message": "Successfully left the split"})
+		response := gin.H{"message": "Successfully left the split"}
+		if newHostID.Valid {
+			response["new_host_id"] = uuid.UUID(newHostID.Bytes).String()
+		}
+		c.JSON(http.StatusOK, response)
 	}
 }
 
 func GetSplitBreakdown(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		code := c.Param("code")
+		split, _ := middleware.AuthorizedSplit(c)
 
-		split, err := queries.GetSplitByCode(c, code)
+		members, err := queries.ListSplitMembersWithUserDetails(c, split.ID)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch members"})
 			return
 		}
 
-		members, err := queries.ListSplitMembersWithUserDetails(c, split.ID)
+		// Only the caller's friends' emails are shown.
+		userID, _ := c.Get("user_id")
+		emails, err := directory.LoadEmailFilter(c, queries, userID.(pgtype.UUID))
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch members"})
 			return
@@ -287,12 +358,14 @@ func GetSplitBreakdown(queries tabmate.Querier) gin.HandlerFunc {
 				response = append(response, gin.H{
 					"user_id":        uuid.UUID(m.UserID.Bytes).String(),
 					"name":           m.UserName.String,
-					"email":          m.UserEmail,
+					"email":          emails.Email(m.UserID, m.UserEmail),
 					"role":           m.Role,
+					"permissions":    m.Permissions,
 					"amount_owed":    amountOwedFloat.Float64,
 					"is_settled":     m.IsSettled,
 					"payment_status": m.PaymentStatus,
 					"joined_at":      m.JoinedAt.Time,
+					"is_placeholder": m.UserIsPlaceholder,
 				})
 			}
 			c.JSON(http.StatusOK, gin.H{"split_type": "simple", "members": response})
@@ -330,8 +403,9 @@ func GetSplitBreakdown(queries tabmate.Querier) gin.HandlerFunc {
 			response = append(response, gin.H{
 				"user_id":        uuid.UUID(m.UserID.Bytes).String(),
 				"name":           m.UserName.String,
-				"email":          m.UserEmail,
+				"email":          emails.Email(m.UserID, m.UserEmail),
 				"role":           m.Role,
+				"permissions":    m.Permissions,
 				"amount_owed":    amountOwedFloat.Float64,
 				"claimed_items":  claimedItems,
 				"tax_share":      taxShare,
@@ -339,6 +413,7 @@ func GetSplitBreakdown(queries tabmate.Querier) gin.HandlerFunc {
 				"is_settled":     m.IsSettled,
 				"payment_status": m.PaymentStatus,
 				"joined_at":      m.JoinedAt.Time,
+				"is_placeholder": m.UserIsPlaceholder,
 			})
 		}
 
@@ -358,26 +433,13 @@ func CloseSplit(queries tabmate.Querier) gin.HandlerFunc {
 		requesterID, _ := c.Get("user_id")
 		pgRequesterID := requesterID.(pgtype.UUID)
 
-		split, err := queries.GetSplitByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
-			return
-		}
+		split, _ := middleware.AuthorizedSplit(c)
 
 		if split.Status == "settled" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Split is already closed"})
 			return
 		}
 
-		hostMember, err := queries.GetSplitMember(c, tabmate.GetSplitMemberParams{
-			SplitID: split.ID,
-			UserID:  pgRequesterID,
-		})
-		if err != nil || hostMember.Role != "host" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only the split host can close the split"})
-			return
-		}
-
 		if _, err := queries.UpdateSplitStatus(c, tabmate.UpdateSplitStatusParams{
 			ID:     split.ID,
 			Status: "settled",
@@ -401,18 +463,14 @@ func CloseSplit(queries tabmate.Querier) gin.HandlerFunc {
 	}
 }
 
-func MarkAsSettled(queries tabmate.Querier) gin.HandlerFunc {
+func MarkAsSettled(pool *pgxpool.Pool, queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		code := c.Param("code")
 
 		userID, _ := c.Get("user_id")
 		pgUserID := userID.(pgtype.UUID)
 
-		split, err := queries.GetSplitByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
-			return
-		}
+		split, _ := middleware.AuthorizedSplit(c)
 
 		// Block settlement if any items are still unclaimed
 		if split.SplitType == "receipt" {
@@ -426,7 +484,15 @@ func MarkAsSettled(queries tabmate.Querier) gin.HandlerFunc {
 			}
 		}
 
-		_, err = queries.UpdateSplitMemberSettledStatus(c, tabmate.UpdateSplitMemberSettledStatusParams{
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to settle split"})
+			return
+		}
+		defer tx.Rollback(c)
+		txQueries := tabmate.New(tx)
+
+		_, err = txQueries.UpdateSplitMemberSettledStatus(c, tabmate.UpdateSplitMemberSettledStatusParams{
 			SplitID:   split.ID,
 			UserID:    pgUserID,
 			IsSettled: true,
@@ -438,7 +504,7 @@ func MarkAsSettled(queries tabmate.Querier) gin.HandlerFunc {
 		}
 
 		actorName, _ := c.Get("username")
-		activity.InsertEvent(c, queries, tabmate.InsertActivityEventParams{
+		activity.InsertEvent(c, txQueries, tabmate.InsertActivityEventParams{
 			EventType:  "payment_confirmed",
 			ActorID:    pgUserID,
 			ActorName:  actorName.(string),
@@ -448,13 +514,14 @@ func MarkAsSettled(queries tabmate.Querier) gin.HandlerFunc {
 		})
 
 		// Check if everyone is settled; if so, mark split as settled too
-		count, err := queries.CountUnsettledSplitMembers(c, split.ID)
+		count, err := txQueries.CountUnsettledSplitMembers(c, split.ID)
 		if err == nil && count == 0 {
-			queries.UpdateSplitStatus(c, tabmate.UpdateSplitStatusParams{
+			txQueries.UpdateSplitStatus(c, tabmate.UpdateSplitStatusParams{
 				ID:     split.ID,
 				Status: "settled",
 			})
-			activity.InsertEvent(c, queries, tabmate.InsertActivityEventParams{
+			notifySettled(c, txQueries, split)
+			activity.InsertEvent(c, txQueries, tabmate.InsertActivityEventParams{
 				EventType:  "split_settled",
 				ActorID:    pgUserID,
 				ActorName:  actorName.(string),
@@ -464,6 +531,11 @@ func MarkAsSettled(queries tabmate.Querier) gin.HandlerFunc {
 			})
 		}
 
+		if err := tx.Commit(c); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to settle split"})
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{"message": "Marked as settled"})
 	}
 }
@@ -472,9 +544,8 @@ type AddMemberRequest struct {
 	UserID string `json:"user_id" binding:"required"`
 }
 
-func AddMemberToSplit(queries tabmate.Querier) gin.HandlerFunc {
+func AddMemberToSplit(pool *pgxpool.Pool, queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		code := c.Param("code")
 		requesterID, _ := c.Get("user_id")
 		pgRequesterID := requesterID.(pgtype.UUID)
 
@@ -484,22 +555,7 @@ func AddMemberToSplit(queries tabmate.Querier) gin.HandlerFunc {
 			return
 		}
 
-		// Get split
-		split, err := queries.GetSplitByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
-			return
-		}
-
-		// Verify requester is the host
-		hostMember, err := queries.GetSplitMember(c, tabmate.GetSplitMemberParams{
-			SplitID: split.ID,
-			UserID:  pgRequesterID,
-		})
-		if err != nil || hostMember.Role != "host" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only the split host can add members"})
-			return
-		}
+		split, _ := middleware.AuthorizedSplit(c)
 
 		// Parse target user UUID
 		targetUUID, err := uuid.Parse(req.UserID)
@@ -510,7 +566,7 @@ func AddMemberToSplit(queries tabmate.Querier) gin.HandlerFunc {
 		pgTargetID := pgtype.UUID{Bytes: targetUUID, Valid: true}
 
 		// Check target user exists
-		targetUser, err := queries.GetUserByID(c, pgTargetID)
+		_, err = queries.GetUserByID(c, pgTargetID)
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 			return
@@ -526,8 +582,24 @@ func AddMemberToSplit(queries tabmate.Querier) gin.HandlerFunc {
 			return
 		}
 
+		hostUser, err := queries.GetUserByID(c, pgRequesterID)
+		hostName := "Someone"
+		if err == nil && hostUser.Name.Valid {
+			hostName = hostUser.Name.String
+		}
+
+		// Add the member and queue their notification together, so the notification
+		// is only delivered if the membership sticks.
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add member"})
+			return
+		}
+		defer tx.Rollback(c)
+		txQueries := tabmate.New(tx)
+
 		// Add member with zero placeholder (recalculate will set the real amount)
-		_, err = queries.AddUserToSplit(c, tabmate.AddUserToSplitParams{
+		_, err = txQueries.AddUserToSplit(c, tabmate.AddUserToSplitParams{
 			SplitID:    split.ID,
 			UserID:     pgTargetID,
 			AmountOwed: pgtype.Numeric{Int: big.NewInt(0), Valid: true},
@@ -539,6 +611,25 @@ func AddMemberToSplit(queries tabmate.Querier) gin.HandlerFunc {
 			return
 		}
 
+		err = notifications.Enqueue(c, txQueries, notifications.Notification{
+			UserID:  pgTargetID,
+			SplitID: split.ID,
+			Kind:    notifications.KindAddedToSplit,
+			Title:   "You've been added to a split",
+			Body:    fmt.Sprintf("%s added you to \"%s\"", hostName, split.Name),
+			Data:    map[string]string{"splitCode": split.SplitCode, "splitName": split.Name},
+		})
+		if err != nil {
+			log.Printf("Error queueing notification for user %s: %v", req.UserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add member"})
+			return
+		}
+
+		if err := tx.Commit(c); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add member"})
+			return
+		}
+
 		// Recalculate split for everyone
 		queries.RecalculateSplitForAllMembers(c, split.ID)
 		recalculateAllMembersFromClaims(c, queries, split)
@@ -546,27 +637,6 @@ func AddMemberToSplit(queries tabmate.Querier) gin.HandlerFunc {
 		members, _ := queries.ListSplitMembersBySplitID(c, split.ID)
 		totalAmountFloat, _ := split.TotalAmount.Float64Value()
 
-		// Send push notification to the added user (fire-and-forget)
-		if targetUser.PushToken.Valid && targetUser.PushToken.String != "" {
-			hostUser, err := queries.GetUserByID(c, pgRequesterID)
-			hostName := "Someone"
-			if err == nil && hostUser.Name.Valid {
-				hostName = hostUser.Name.String
-			}
-
-			go func() {
-				err := notifications.SendExpoPushNotification(notifications.ExpoMessage{
-					To:    targetUser.PushToken.String,
-					Title: "You've been added to a split",
-					Body:  fmt.Sprintf("%s added you to \"%s\"", hostName, split.Name),
-					Data:  map[string]string{"splitCode": split.SplitCode},
-				})
-				if err != nil {
-					log.Printf("Failed to send push notification to user %s: %v", req.UserID, err)
-				}
-			}()
-		}
-
 		c.JSON(http.StatusOK, gin.H{
 			"message":           "Member added successfully",
 			"members_count":     len(members),
@@ -577,7 +647,6 @@ func AddMemberToSplit(queries tabmate.Querier) gin.HandlerFunc {
 
 func RemoveMemberFromSplit(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		code := c.Param("code")
 		requesterID, _ := c.Get("user_id")
 		pgRequesterID := requesterID.(pgtype.UUID)
 
@@ -589,31 +658,16 @@ func RemoveMemberFromSplit(queries tabmate.Querier) gin.HandlerFunc {
 		}
 		pgTargetID := pgtype.UUID{Bytes: targetUUID, Valid: true}
 
-		// Get split
-		split, err := queries.GetSplitByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
-			return
-		}
-
-		// Verify requester is the host
-		hostMember, err := queries.GetSplitMember(c, tabmate.GetSplitMemberParams{
-			SplitID: split.ID,
-			UserID:  pgRequesterID,
-		})
-		if err != nil || hostMember.Role != "host" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only the split host can remove members"})
-			return
-		}
+		split, _ := middleware.AuthorizedSplit(c)
 
 		// Prevent host from removing themselves
 		if pgRequesterID == pgTargetID {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Host cannot remove themselves from the split"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot remove yourself from the split"})
 			return
 		}
 
 		// Check target is actually a member
-		_, err = queries.GetSplitMember(c, tabmate.GetSplitMemberParams{
+		target, err := queries.GetSplitMember(c, tabmate.GetSplitMemberParams{
 			SplitID: split.ID,
 			UserID:  pgTargetID,
 		})
@@ -621,6 +675,10 @@ func RemoveMemberFromSplit(queries tabmate.Querier) gin.HandlerFunc {
 			c.JSON(http.StatusNotFound, gin.H{"error": "User is not a member of this split"})
 			return
 		}
+		if target.Role == roles.Host {
+			c.JSON(http.StatusForbidden, gin.H{"error": "The host cannot be removed"})
+			return
+		}
 
 		// Remove the member
 		err = queries.RemoveUserFromSplit(c, tabmate.RemoveUserFromSplitParams{
@@ -647,33 +705,28 @@ func RemoveMemberFromSplit(queries tabmate.Querier) gin.HandlerFunc {
 	}
 }
 
-func MarkPaymentSent(queries tabmate.Querier) gin.HandlerFunc {
+func MarkPaymentSent(pool *pgxpool.Pool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		code := c.Param("code")
 		userID, _ := c.Get("user_id")
 		pgUserID := userID.(pgtype.UUID)
 
-		split, err := queries.GetSplitByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
-			return
-		}
+		split, member := middleware.AuthorizedSplit(c)
 
-		member, err := queries.GetSplitMember(c, tabmate.GetSplitMemberParams{
-			SplitID: split.ID,
-			UserID:  pgUserID,
-		})
-		if err != nil {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You are not a member of this split"})
+		if member.PaymentStatus == "confirmed" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Payment already confirmed"})
 			return
 		}
 
-		if member.PaymentStatus == "confirmed" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Payment already confirmed"})
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update payment status"})
 			return
 		}
+		defer tx.Rollback(c)
+		txQueries := tabmate.New(tx)
 
-		_, err = queries.UpdateSplitMemberPaymentStatus(c, tabmate.UpdateSplitMemberPaymentStatusParams{
+		_, err = txQueries.UpdateSplitMemberPaymentStatus(c, tabmate.UpdateSplitMemberPaymentStatusParams{
 			SplitID:       split.ID,
 			UserID:        pgUserID,
 			PaymentStatus: "marked_sent",
@@ -684,7 +737,7 @@ func MarkPaymentSent(queries tabmate.Querier) gin.HandlerFunc {
 		}
 
 		actorName, _ := c.Get("username")
-		activity.InsertEvent(c, queries, tabmate.InsertActivityEventParams{
+		activity.InsertEvent(c, txQueries, tabmate.InsertActivityEventParams{
 			EventType:  "payment_sent",
 			ActorID:    pgUserID,
 			ActorName:  actorName.(string),
@@ -693,11 +746,32 @@ func MarkPaymentSent(queries tabmate.Querier) gin.HandlerFunc {
 			EntityName: split.Name,
 		})
 
+		if split.CreatedBy != pgUserID {
+			amount, _ := member.AmountOwed.Float64Value()
+			notify(c, txQueries, notifications.Notification{
+				UserID:  split.CreatedBy,
+				SplitID: split.ID,
+				Kind:    notifications.KindPaymentReceived,
+				Title:   "Payment sent 💸",
+				Body:    fmt.Sprintf("%s says they've paid you $%.2f for \"%s\". Confirm it once it arrives.", actorName.(string), amount.Float64, split.Name),
+				Data: map[string]string{
+					"splitCode": split.SplitCode,
+					"splitName": split.Name,
+					"amount":    fmt.Sprintf("%.2f", amount.Float64),
+				},
+			})
+		}
+
+		if err := tx.Commit(c); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update payment status"})
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{"message": "Payment marked as sent"})
 	}
 }
 
-func ConfirmPayment(queries tabmate.Querier) gin.HandlerFunc {
+func ConfirmPayment(pool *pgxpool.Pool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		code := c.Param("code")
 		requesterID, _ := c.Get("user_id")
@@ -710,41 +784,23 @@ func ConfirmPayment(queries tabmate.Querier) gin.HandlerFunc {
 		}
 		pgTargetID := pgtype.UUID{Bytes: targetUUID, Valid: true}
 
-		split, err := queries.GetSplitByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
-			return
-		}
-
-		hostMember, err := queries.GetSplitMember(c, tabmate.GetSplitMemberParams{
-			SplitID: split.ID,
-			UserID:  pgRequesterID,
-		})
-		if err != nil || hostMember.Role != "host" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only the split host can confirm payments"})
-			return
-		}
+		split, _ := middleware.AuthorizedSplit(c)
 
-		_, err = queries.ConfirmSplitMemberPayment(c, tabmate.ConfirmSplitMemberPaymentParams{
-			SplitID: split.ID,
-			UserID:  pgTargetID,
-		})
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm payment"})
 			return
 		}
+		defer tx.Rollback(c)
+		txQueries := tabmate.New(tx)
 
-		// Check if everyone is settled
-		count, err := queries.CountUnsettledSplitMembers(c, split.ID)
-		if err == nil && count == 0 {
-			queries.UpdateSplitStatus(c, tabmate.UpdateSplitStatusParams{
-				ID:     split.ID,
-				Status: "settled",
-			})
+		if err := settleMemberPayment(c, txQueries, split, pgTargetID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm payment"})
+			return
 		}
 
 		actorName, _ := c.Get("username")
-		activity.InsertEvent(c, queries, tabmate.InsertActivityEventParams{
+		activity.InsertEvent(c, txQueries, tabmate.InsertActivityEventParams{
 			EventType:  "payment_confirmed",
 			ActorID:    pgRequesterID,
 			ActorName:  actorName.(string),
@@ -753,6 +809,11 @@ func ConfirmPayment(queries tabmate.Querier) gin.HandlerFunc {
 			EntityName: split.Name,
 		})
 
+		if err := tx.Commit(c); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm payment"})
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{"message": "Payment confirmed"})
 	}
 }
@@ -763,32 +824,15 @@ type UpdatePaymentInstructionsRequest struct {
 
 func UpdatePaymentInstructions(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		code := c.Param("code")
-		requesterID, _ := c.Get("user_id")
-		pgRequesterID := requesterID.(pgtype.UUID)
-
 		var req UpdatePaymentInstructionsRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
 			return
 		}
 
-		split, err := queries.GetSplitByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
-			return
-		}
-
-		hostMember, err := queries.GetSplitMember(c, tabmate.GetSplitMemberParams{
-			SplitID: split.ID,
-			UserID:  pgRequesterID,
-		})
-		if err != nil || hostMember.Role != "host" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only the split host can set payment instructions"})
-			return
-		}
+		split, _ := middleware.AuthorizedSplit(c)
 
-		_, err = queries.UpdateSplitPaymentInstructions(c, tabmate.UpdateSplitPaymentInstructionsParams{
+		_, err := queries.UpdateSplitPaymentInstructions(c, tabmate.UpdateSplitPaymentInstructionsParams{
 			ID:                  split.ID,
 			PaymentInstructions: pgtype.Text{String: req.Instructions, Valid: req.Instructions != ""},
 		})
@@ -804,23 +848,6 @@ func UpdatePaymentInstructions(queries tabmate.Querier) gin.HandlerFunc {
 func DeleteSplit(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		code := c.Param("code")
-		requesterID, _ := c.Get("user_id")
-		pgRequesterID := requesterID.(pgtype.UUID)
-
-		split, err := queries.GetSplitByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Split not found"})
-			return
-		}
-
-		hostMember, err := queries.GetSplitMember(c, tabmate.GetSplitMemberParams{
-			SplitID: split.ID,
-			UserID:  pgRequesterID,
-		})
-		if err != nil || hostMember.Role != "host" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only the split host can delete the split"})
-			return
-		}
 
 		if err := queries.DeleteSplitByCode(c, code); err != nil {
 			log.Printf("Error deleting split %s: %v", code, err)
@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	activity "tabmate/internals/controllers/activity"
+	"tabmate/internals/hosts"
+	"tabmate/internals/middleware"
+	"tabmate/internals/roles"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TransferHostRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+type UpdateMemberRoleRequest struct {
+	Role        string   `json:"role" binding:"required"` // "cohost" or "guest"
+	Permissions []string `json:"permissions"`             // for co-hosts; omitted means every permission
+}
+
+// TransferTableHost makes another member the host of a table. The old host stays on as
+// a co-host with every permission. Host only.
+// POST /api/tables/:code/transfer-host
+func TransferTableHost(pool *pgxpool.Pool, queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		var req TransferHostRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
+			return
+		}
+		targetUUID, err := uuid.Parse(req.UserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		pgTargetID := pgtype.UUID{Bytes: targetUUID, Valid: true}
+
+		table, _ := middleware.AuthorizedTable(c)
+		if pgTargetID == pgUserID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You are already the host"})
+			return
+		}
+		if !canHost(c, queries, table.ID, pgTargetID) {
+			return
+		}
+
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer host"})
+			return
+		}
+		defer tx.Rollback(c)
+
+		err = hosts.HandOverTable(c, tabmate.New(tx), table.ID, pgUserID, pgTargetID, true)
+		if err == nil {
+			err = tx.Commit(c)
+		}
+		if err != nil {
+			log.Printf("[hosts] failed to transfer table %s to %v: %v", table.TableCode, pgTargetID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer host"})
+			return
+		}
+
+		actorName, _ := c.Get("username")
+		metadata, _ := json.Marshal(gin.H{
+			"from": uuid.UUID(pgUserID.Bytes).String(),
+			"to":   req.UserID,
+		})
+		activity.InsertEvent(c, queries, tabmate.InsertActivityEventParams{
+			EventType:  "host_transferred",
+			ActorID:    pgUserID,
+			ActorName:  actorName.(string),
+			EntityType: "table",
+			EntityCode: table.TableCode,
+			EntityName: table.Name.String,
+			Metadata:   metadata,
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Host transferred",
+			"host_id": req.UserID,
+		})
+	}
+}
+
+// UpdateTableMemberRole makes a member a co-host with the given permissions, or turns a
+// co-host back into a guest. Host only.
+// PUT /api/tables/:code/members/:userId/role
+func UpdateTableMemberRole(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		targetUUID, err := uuid.Parse(c.Param("userId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		pgTargetID := pgtype.UUID{Bytes: targetUUID, Valid: true}
+
+		var req UpdateMemberRoleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "role is required"})
+			return
+		}
+		permissions := []string{}
+		switch req.Role {
+		case roles.CoHost:
+			if permissions, err = roles.ParsePermissions(req.Permissions); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		case roles.Guest:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "role must be cohost or guest; use transfer-host to change the host"})
+			return
+		}
+
+		table, _ := middleware.AuthorizedTable(c)
+		if pgTargetID == pgUserID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Use transfer-host to hand over the table"})
+			return
+		}
+		if req.Role == roles.CoHost && !canHost(c, queries, table.ID, pgTargetID) {
+			return
+		}
+
+		member, err := queries.UpdateMemberRoleInTable(c, tabmate.UpdateMemberRoleInTableParams{
+			TableID:     table.ID,
+			UserID:      pgTargetID,
+			Role:        req.Role,
+			Permissions: permissions,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User is not a member of this table"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"user_id":     uuid.UUID(member.UserID.Bytes).String(),
+			"role":        member.Role,
+			"permissions": member.Permissions,
+		})
+	}
+}
+
+// canHost checks userID is a member of the table with an account of their own, since a
+// placeholder can't act for anyone. It writes the error response if not.
+func canHost(c *gin.Context, queries tabmate.Querier, tableID, userID pgtype.UUID) bool {
+	if isMember, err := userIsMember(c, queries, tableID, userID); err != nil || !isMember {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not a member of this table"})
+		return false
+	}
+	user, err := queries.GetUserByID(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return false
+	}
+	if user.IsPlaceholder {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Placeholder members can't host or co-host"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"tabmate/internals/joincodes"
+	"tabmate/internals/middleware"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type JoinCodeRequest struct {
+	ExpiresInHours int `json:"expires_in_hours"` // 0 means the code lasts until rotated or revoked
+}
+
+// GetTableJoinCode returns the table's live join code, or null if it has none.
+// GET /api/tables/:code/join-code
+func GetTableJoinCode(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		table, _ := middleware.AuthorizedTable(c)
+
+		joinCode, err := queries.GetActiveJoinCode(c, tabmate.GetActiveJoinCodeParams{TableID: table.ID})
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusOK, gin.H{"join_code": nil})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch join code"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"join_code": joinCodeResponse(joinCode)})
+	}
+}
+
+// RotateTableJoinCode issues a new join code for a table and revokes the previous one.
+// From then on the permanent table code no longer lets new people join.
+// POST /api/tables/:code/join-code
+func RotateTableJoinCode(pool *pgxpool.Pool, queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		table, _ := middleware.AuthorizedTable(c)
+		var req JoinCodeRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+				return
+			}
+		}
+
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create join code"})
+			return
+		}
+		defer tx.Rollback(c)
+
+		joinCode, err := joincodes.Rotate(c, tabmate.New(tx), tabmate.CreateJoinCodeParams{
+			TableID:   table.ID,
+			CreatedBy: table.CreatedBy,
+		}, time.Duration(req.ExpiresInHours)*time.Hour)
+		if errors.Is(err, joincodes.ErrInvalidTTL) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err == nil {
+			err = tx.Commit(c)
+		}
+		if err != nil {
+			log.Printf("[join-codes] failed to rotate join code for table %s: %v", table.TableCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create join code"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"join_code": joinCodeResponse(joinCode)})
+	}
+}
+
+// RevokeTableJoinCode revokes the table's join code, closing it to new members until
+// the host issues another.
+// DELETE /api/tables/:code/join-code
+func RevokeTableJoinCode(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		table, _ := middleware.AuthorizedTable(c)
+
+		if _, err := queries.RevokeJoinCodes(c, tabmate.RevokeJoinCodesParams{TableID: table.ID}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke join code"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Join code revoked"})
+	}
+}
+
+// tableToJoin finds the table a code lets the caller join. A live join code always
+// works; the permanent table code only works until the host first issues a join code,
+// which locked reports.
+func tableToJoin(c *gin.Context, queries tabmate.Querier, code string) (table tabmate.Tables, locked bool, err error) {
+	code = joincodes.Normalize(code)
+	if joinCode, err := queries.ResolveJoinCode(c, code); err == nil && joinCode.TableID.Valid {
+		table, err := queries.GetTableByID(c, joinCode.TableID)
+		return table, false, err
+	}
+
+	table, err = queries.GetTableByCode(c, code)
+	if err != nil {
+		return table, false, err
+	}
+	locked, err = queries.HasJoinCodes(c, tabmate.HasJoinCodesParams{TableID: table.ID})
+	return table, locked, err
+}
+
+func joinCodeResponse(j tabmate.JoinCodes) gin.H {
+	var expiresAt *time.Time
+	if j.ExpiresAt.Valid {
+		expiresAt = &j.ExpiresAt.Time
+	}
+	return gin.H{
+		"code":       j.Code,
+		"expires_at": expiresAt,
+		"created_at": j.CreatedAt.Time,
+	}
+}
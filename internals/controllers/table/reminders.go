@@ -1,32 +1,30 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"tabmate/internals/middleware"
 	"tabmate/internals/notifications"
 	tabmate "tabmate/internals/store/postgres"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func SendTablePaymentReminder(queries tabmate.Querier) gin.HandlerFunc {
+// SendTablePaymentReminder reminds the table's guests to pay. The reminders are queued
+// together, so a failure queues none of them and the host can simply retry.
+// POST /api/tables/:code/payment-reminder
+func SendTablePaymentReminder(pool *pgxpool.Pool, queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tableCode := c.Param("code")
 		userID, _ := c.Get("user_id")
 		pgUserID := userID.(pgtype.UUID)
 
-		dbTable, err := queries.GetTableByCode(c, tableCode)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Table not found"})
-			return
-		}
-
-		if dbTable.CreatedBy != pgUserID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only the table host can send reminders"})
-			return
-		}
+		dbTable, _ := middleware.AuthorizedTable(c)
 
 		hostUser, err := queries.GetUserByID(c, pgUserID)
 		hostName := "The host"
@@ -50,33 +48,49 @@ func SendTablePaymentReminder(queries tabmate.Querier) gin.HandlerFunc {
 			return
 		}
 
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send reminders"})
+			return
+		}
+		defer tx.Rollback(c)
+		txQueries := tabmate.New(tx)
+
 		sent := 0
 		for _, g := range guests {
-			if !g.PushToken.Valid || g.PushToken.String == "" {
+			if !g.Reachable {
 				continue
 			}
-			token := g.PushToken.String
 			name := "there"
 			if g.UserName.Valid {
 				name = g.UserName.String
 			}
-			go func(token, name string) {
-				err := notifications.SendExpoPushNotification(notifications.ExpoMessage{
-					To:    token,
-					Title: "Time to pay up 💸",
-					Body:  fmt.Sprintf("%s has finalised the bill for \"%s\". Check the app to see your share.", hostName, tableName),
-					Data: map[string]string{
-						"tableCode": tableCode,
-						"type":      "payment_reminder",
-					},
-				})
-				if err != nil {
-					log.Printf("Failed to send table reminder to %s: %v", name, err)
-				}
-			}(token, name)
+			err := notifications.Enqueue(c, txQueries, notifications.Notification{
+				UserID: g.UserID,
+				Kind:   notifications.KindPaymentReminder,
+				Title:  "Time to pay up 💸",
+				Body:   fmt.Sprintf("%s has finalised the bill for \"%s\". Check the app to see your share.", hostName, tableName),
+				Data: map[string]string{
+					"tableCode": tableCode,
+					"type":      "payment_reminder",
+				},
+			})
+			if errors.Is(err, notifications.ErrOptedOut) {
+				continue
+			}
+			if err != nil {
+				log.Printf("Failed to queue table reminder for %s: %v", name, err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send reminders"})
+				return
+			}
 			sent++
 		}
 
+		if err := tx.Commit(c); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send reminders"})
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"message": fmt.Sprintf("Reminders sent to %d guest(s)", sent),
 			"sent":    sent,
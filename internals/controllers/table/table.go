@@ -9,6 +9,11 @@ import (
 	"net/http"
 	"strings"
 	activity "tabmate/internals/controllers/activity"
+	"tabmate/internals/directory"
+	"tabmate/internals/joincodes"
+	"tabmate/internals/middleware"
+	"tabmate/internals/notifications"
+	"tabmate/internals/roles"
 	tabmate "tabmate/internals/store/postgres"
 
 	"github.com/gin-gonic/gin"
@@ -130,7 +135,12 @@ func CreateTable(queries tabmate.Querier) gin.HandlerFunc {
 			return
 		}
 		// Generate a new table code
-		newTableCode := uuid.New().String()[:8]
+		newTableCode, err := joincodes.Unique(c, queries.IsCodeInUse)
+		if err != nil {
+			log.Printf("Error generating code: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create table"})
+			return
+		}
 
 		// Create table in database
 		dbTable, err := queries.CreateTable(c, tabmate.CreateTableParams{
@@ -186,7 +196,7 @@ func JoinTable(queries tabmate.Querier) gin.HandlerFunc {
 		pgUserID := userId.(pgtype.UUID)
 
 		// Fetch Table from Database
-		dbTable, err := queries.GetTableByCode(c, code)
+		dbTable, locked, err := tableToJoin(c, queries, code)
 		if err != nil {
 			log.Printf("Database error fetching table: %v", err)
 			c.JSON(http.StatusNotFound, gin.H{"error": "Table not found in database"})
@@ -200,6 +210,10 @@ func JoinTable(queries tabmate.Querier) gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 			return
 		}
+		if !userExists && locked {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Ask the host for the current join code"})
+			return
+		}
 		if !userExists {
 			_, err := queries.AddUserToTable(c, tabmate.AddUserToTableParams{
 				TableID: dbTable.ID,
@@ -216,13 +230,13 @@ func JoinTable(queries tabmate.Querier) gin.HandlerFunc {
 				ActorID:    pgUserID,
 				ActorName:  actorName.(string),
 				EntityType: "table",
-				EntityCode: code,
+				EntityCode: dbTable.TableCode,
 				EntityName: dbTable.Name.String,
 			})
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"code": code,
+			"code": dbTable.TableCode,
 			"id":   uuid.UUID(dbTable.ID.Bytes).String(),
 			// "usernames": usernames,
 			"tablename":  dbTable.Name,
@@ -235,20 +249,25 @@ func JoinTable(queries tabmate.Querier) gin.HandlerFunc {
 
 func FetchTableMembers(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		code := c.Param("code")
+		dbTable, _ := middleware.AuthorizedTable(c)
 
-		dbTable, err := queries.GetTableByCode(c, code)
+		tableMembers, err := queries.ListMembersWithUserDetailsByTableID(c, dbTable.ID)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Table not found in database"})
+			log.Printf("[FetchTableMembers] ListMembersWithUserDetailsByTableID error for table id=%v: %v", dbTable.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch table members"})
 			return
 		}
 
-		tableMembers, err := queries.ListMembersWithUserDetailsByTableID(c, dbTable.ID)
+		// Only the caller's friends' emails are shown.
+		userID, _ := c.Get("user_id")
+		emails, err := directory.LoadEmailFilter(c, queries, userID.(pgtype.UUID))
 		if err != nil {
-			log.Printf("[FetchTableMembers] ListMembersWithUserDetailsByTableID error for table id=%v: %v", dbTable.ID, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch table members"})
 			return
 		}
+		for i, m := range tableMembers {
+			tableMembers[i].UserEmail = emails.Email(m.UserID, m.UserEmail)
+		}
 
 		c.JSON(http.StatusOK, tableMembers)
 	}
@@ -268,6 +287,36 @@ func userIsMember(ctx context.Context, queries tabmate.Querier, tableID, userID
 	return true, nil
 }
 
+// memberCan reports whether userID belongs to the table and may do p there.
+func memberCan(ctx context.Context, queries tabmate.Querier, tableID, userID pgtype.UUID, p roles.Permission) bool {
+	member, err := queries.GetTableMember(ctx, tabmate.GetTableMemberParams{
+		TableID: tableID,
+		UserID:  userID,
+	})
+	return err == nil && roles.Can(member.Role, member.Permissions, p)
+}
+
+// requireTableMembership checks the caller belongs to the table with tableCode, for
+// routes that name the table in the request body rather than the URL. It writes the
+// error response if not.
+func requireTableMembership(c *gin.Context, queries tabmate.Querier, tableCode string, userID pgtype.UUID) bool {
+	table, err := queries.GetTableByCode(c, tableCode)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Table not found"})
+		return false
+	}
+	isMember, err := userIsMember(c, queries, table.ID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return false
+	}
+	if !isMember {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Table not found"})
+		return false
+	}
+	return true
+}
+
 func AddItemToTable(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Retrieve user_id from context
@@ -299,9 +348,21 @@ func AddItemToTable(queries tabmate.Querier) gin.HandlerFunc {
 		if !req.OriginalParsedText.Valid {
 			req.OriginalParsedText = pgtype.Text{String: req.Name, Valid: true} // Default to item name
 		}
+		if !requireTableMembership(c, queries, req.TableCode, pgUserID) {
+			return
+		}
 
-		// Set the AddedByUserID from the context
+		// Set the AddedByUserID from the context, or the placeholder member the host is
+		// ordering for
 		req.AddedByUserID = pgUserID
+		if onBehalfOf := c.Query("on_behalf_of"); onBehalfOf != "" {
+			placeholderID, status, msg := placeholderOrderer(c, queries, req.TableCode, pgUserID, onBehalfOf)
+			if msg != "" {
+				c.JSON(status, gin.H{"error": msg})
+				return
+			}
+			req.AddedByUserID = placeholderID
+		}
 
 		newItem, err := queries.AddItemToTable(c, req)
 		if err != nil {
@@ -314,9 +375,33 @@ func AddItemToTable(queries tabmate.Querier) gin.HandlerFunc {
 	}
 }
 
+// placeholderOrderer checks that hostID may manage the table's items and that
+// onBehalfOf is one of its placeholder members. On failure it returns the status and message to respond with.
+func placeholderOrderer(ctx context.Context, queries tabmate.Querier, tableCode string, hostID pgtype.UUID, onBehalfOf string) (pgtype.UUID, int, string) {
+	table, err := queries.GetTableByCode(ctx, tableCode)
+	if err != nil {
+		return pgtype.UUID{}, http.StatusNotFound, "Table not found"
+	}
+	if !memberCan(ctx, queries, table.ID, hostID, roles.ManageItems) {
+		return pgtype.UUID{}, http.StatusForbidden, "You aren't allowed to order for other members"
+	}
+	id, err := uuid.Parse(onBehalfOf)
+	if err != nil {
+		return pgtype.UUID{}, http.StatusBadRequest, "Invalid on_behalf_of user ID"
+	}
+	pgID := pgtype.UUID{Bytes: id, Valid: true}
+	if isMember, err := userIsMember(ctx, queries, table.ID, pgID); err != nil || !isMember {
+		return pgtype.UUID{}, http.StatusNotFound, "Member not found in this table"
+	}
+	user, err := queries.GetUserByID(ctx, pgID)
+	if err != nil || !user.IsPlaceholder {
+		return pgtype.UUID{}, http.StatusForbidden, "You can only order for placeholder members"
+	}
+	return pgID, 0, ""
+}
+
 func UpdateItemQuantity(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
-
 		id := c.Param("id")
 		var req struct {
 			Quantity int32 `json:"quantity"`
@@ -341,6 +426,8 @@ func UpdateItemQuantity(queries tabmate.Querier) gin.HandlerFunc {
 
 func AddMenuItemsToDB(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
 
 		var req []tabmate.AddItemToTableParams
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -348,6 +435,18 @@ func AddMenuItemsToDB(queries tabmate.Querier) gin.HandlerFunc {
 			return
 		}
 
+		// Items may be for several tables; the caller has to belong to each of them
+		checked := make(map[string]bool)
+		for _, item := range req {
+			if checked[item.TableCode] {
+				continue
+			}
+			if !requireTableMembership(c, queries, item.TableCode, pgUserID) {
+				return
+			}
+			checked[item.TableCode] = true
+		}
+
 		fmt.Println("Payload", req)
 
 		for _, item := range req {
@@ -361,6 +460,7 @@ func AddMenuItemsToDB(queries tabmate.Querier) gin.HandlerFunc {
 			if !item.OriginalParsedText.Valid {
 				item.OriginalParsedText = pgtype.Text{String: item.Name, Valid: true} // Default to item name
 			}
+			item.AddedByUserID = pgUserID
 
 			_, err := queries.AddItemToTable(c, item)
 			if err != nil {
@@ -559,7 +659,6 @@ func SyncTableItems(pool *pgxpool.Pool) gin.HandlerFunc {
 
 func DeleteItemFromTable(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
-
 		// Convert string to pgtype.UUID
 		var itemID struct {
 			Id pgtype.UUID `json:"id"`
@@ -598,6 +697,17 @@ func ListItemsWithUserDetailsInTable(queries tabmate.Querier) gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list items with user details in table"})
 			return
 		}
+
+		// Only the caller's friends' emails are shown.
+		userID, _ := c.Get("user_id")
+		emails, err := directory.LoadEmailFilter(c, queries, userID.(pgtype.UUID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list items with user details in table"})
+			return
+		}
+		for i, item := range items {
+			items[i].AddedByEmail = emails.Email(item.AddedByUserID, item.AddedByEmail)
+		}
 		c.JSON(http.StatusOK, items)
 	}
 }
@@ -637,12 +747,7 @@ func GetTableHandler(queries tabmate.Querier) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Table code is required"})
 			return
 		}
-		// Check if table exists in database
-		dbTable, err := queries.GetTableByCode(c, code)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Table not found"})
-			return
-		}
+		dbTable, _ := middleware.AuthorizedTable(c)
 
 		// Get or create table instance
 		table := GetTable(code)
@@ -714,30 +819,28 @@ func UpdateTableVat(queries tabmate.Querier) gin.HandlerFunc {
 	}
 }
 
-func CloseTable(queries tabmate.Querier) gin.HandlerFunc {
+func CloseTable(pool *pgxpool.Pool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tableCode := c.Param("code")
 		userID, _ := c.Get("user_id")
 		pgUserID := userID.(pgtype.UUID)
 
-		dbTable, err := queries.GetTableByCode(c, tableCode)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Table not found"})
-			return
-		}
+		dbTable, _ := middleware.AuthorizedTable(c)
 
-		// Only the host can close the table
-		if dbTable.CreatedBy != pgUserID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only the table host can close the table"})
+		if dbTable.Status == "closed" {
+			c.JSON(http.StatusOK, gin.H{"message": "Table already closed"})
 			return
 		}
 
-		if dbTable.Status == "closed" {
-			c.JSON(http.StatusOK, gin.H{"message": "Table already closed"})
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close table"})
 			return
 		}
+		defer tx.Rollback(c)
+		txQueries := tabmate.New(tx)
 
-		_, err = queries.UpdateTableStatus(c, tabmate.UpdateTableStatusParams{
+		_, err = txQueries.UpdateTableStatus(c, tabmate.UpdateTableStatusParams{
 			ID:      dbTable.ID,
 			Column2: "closed",
 		})
@@ -748,7 +851,7 @@ func CloseTable(queries tabmate.Querier) gin.HandlerFunc {
 		}
 
 		actorName, _ := c.Get("username")
-		activity.InsertEvent(c, queries, tabmate.InsertActivityEventParams{
+		activity.InsertEvent(c, txQueries, tabmate.InsertActivityEventParams{
 			EventType:  "table_closed",
 			ActorID:    pgUserID,
 			ActorName:  actorName.(string),
@@ -756,11 +859,46 @@ func CloseTable(queries tabmate.Querier) gin.HandlerFunc {
 			EntityCode: tableCode,
 			EntityName: dbTable.Name.String,
 		})
+		notifyTableClosed(c, txQueries, dbTable, pgUserID, actorName.(string))
+
+		if err := tx.Commit(c); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close table"})
+			return
+		}
 
 		c.JSON(http.StatusOK, gin.H{"message": "Table closed successfully"})
 	}
 }
 
+// notifyTableClosed tells every member but the one who closed the table, queueing the
+// notifications in the transaction that closes it. Failures are logged rather than
+// returned.
+func notifyTableClosed(ctx context.Context, queries tabmate.Querier, dbTable tabmate.Tables, closedBy pgtype.UUID, closedByName string) {
+	members, err := queries.ListMembersByTableID(ctx, dbTable.ID)
+	if err != nil {
+		log.Printf("[notifications] failed to list members of closed table %s: %v", dbTable.TableCode, err)
+		return
+	}
+	for _, m := range members {
+		if m.UserID == closedBy {
+			continue
+		}
+		err := notifications.Enqueue(ctx, queries, notifications.Notification{
+			UserID: m.UserID,
+			Kind:   notifications.KindTableClosed,
+			Title:  "Table closed",
+			Body:   fmt.Sprintf("%s closed \"%s\". Check the app to see your share.", closedByName, dbTable.Name.String),
+			Data: map[string]string{
+				"tableCode": dbTable.TableCode,
+				"type":      "table_closed",
+			},
+		})
+		if err != nil && !errors.Is(err, notifications.ErrOptedOut) {
+			log.Printf("[notifications] failed to queue table_closed notification: %v", err)
+		}
+	}
+}
+
 func (t *Table) Run() {
 	for {
 		select {
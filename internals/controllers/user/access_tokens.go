@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"tabmate/internals/apitokens"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxAccessTokens is how many live tokens a user can have at once.
+const maxAccessTokens = 25
+
+// AccessTokenResponse describes a personal access token. Token is only set in the
+// response to creating it; afterwards only Hint is known.
+type AccessTokenResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Token      string     `json:"token,omitempty"`
+	Hint       string     `json:"hint"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func toAccessTokenResponse(row tabmate.PersonalAccessTokens) AccessTokenResponse {
+	optional := func(ts pgtype.Timestamptz) *time.Time {
+		if !ts.Valid {
+			return nil
+		}
+		return &ts.Time
+	}
+	return AccessTokenResponse{
+		ID:         uuid.UUID(row.ID.Bytes).String(),
+		Name:       row.Name,
+		Hint:       row.TokenHint,
+		Scopes:     row.Scopes,
+		ExpiresAt:  optional(row.ExpiresAt),
+		LastUsedAt: optional(row.LastUsedAt),
+		CreatedAt:  row.CreatedAt.Time,
+	}
+}
+
+// ListAccessTokens returns the caller's personal access tokens, newest first.
+// GET /api/user/tokens
+func ListAccessTokens(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		rows, err := queries.ListPersonalAccessTokensForUser(c, pgUserID)
+		if err != nil {
+			log.Printf("[ListAccessTokens] error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch access tokens"})
+			return
+		}
+
+		tokens := make([]AccessTokenResponse, len(rows))
+		for i, row := range rows {
+			tokens[i] = toAccessTokenResponse(row)
+		}
+		c.JSON(http.StatusOK, tokens)
+	}
+}
+
+type CreateAccessTokenRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"` // 0 never expires
+}
+
+// CreateAccessToken issues a personal access token for scripts. The token is in the
+// response and can't be shown again; only its hash is stored.
+// POST /api/user/tokens
+func CreateAccessToken(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		var req CreateAccessTokenRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name and scopes are required"})
+			return
+		}
+		name := strings.TrimSpace(req.Name)
+		if name == "" || len(name) > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name must be 1 to 100 characters"})
+			return
+		}
+		scopes, err := apitokens.ParseScopes(req.Scopes)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		var expiresAt pgtype.Timestamptz
+		// The days are checked before they become a duration, which a large enough
+		// count would overflow.
+		if req.ExpiresInDays < 0 || req.ExpiresInDays > apitokens.MaxTTLDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": apitokens.ErrInvalidTTL.Error()})
+			return
+		}
+		if req.ExpiresInDays != 0 {
+			ttl := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+			expiresAt = pgtype.Timestamptz{Time: time.Now().Add(ttl), Valid: true}
+		}
+
+		existing, err := queries.ListPersonalAccessTokensForUser(c, pgUserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create access token"})
+			return
+		}
+		if len(existing) >= maxAccessTokens {
+			c.JSON(http.StatusConflict, gin.H{"error": "You have too many access tokens; revoke one first"})
+			return
+		}
+
+		token, err := apitokens.Generate()
+		if err != nil {
+			log.Printf("[CreateAccessToken] generate error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create access token"})
+			return
+		}
+		row, err := queries.CreatePersonalAccessToken(c, tabmate.CreatePersonalAccessTokenParams{
+			UserID:    pgUserID,
+			Name:      name,
+			TokenHash: apitokens.Hash(token),
+			TokenHint: apitokens.Hint(token),
+			Scopes:    scopes,
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			log.Printf("[CreateAccessToken] error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create access token"})
+			return
+		}
+
+		resp := toAccessTokenResponse(row)
+		resp.Token = token
+		c.JSON(http.StatusCreated, resp)
+	}
+}
+
+// RevokeAccessToken revokes one of the caller's personal access tokens. It stops
+// working on the next request.
+// DELETE /api/user/tokens/:id
+func RevokeAccessToken(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		tokenUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token ID"})
+			return
+		}
+
+		revoked, err := queries.RevokePersonalAccessToken(c, tabmate.RevokePersonalAccessTokenParams{
+			ID:     pgtype.UUID{Bytes: tokenUUID, Valid: true},
+			UserID: pgUserID,
+		})
+		if err != nil {
+			log.Printf("[RevokeAccessToken] error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke access token"})
+			return
+		}
+		if revoked == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Access token not found"})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCreateAccessTokenRejectsOutOfRangeExpiry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", alice) })
+	// Every case is refused before the handler touches the database.
+	router.POST("/api/user/tokens", CreateAccessToken(nil))
+
+	for _, days := range []int{-1, 366, 1 << 40} {
+		body := fmt.Sprintf(`{"name":"script","scopes":["splits:read"],"expires_in_days":%d}`, days)
+		call(t, router, alice, http.MethodPost, "/api/user/tokens", body, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"tabmate/internals/accounts"
+	activity "tabmate/internals/controllers/activity"
+	"tabmate/internals/encryption"
+	"tabmate/internals/notifications"
+	"tabmate/internals/payouts"
+	"tabmate/internals/storage"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DeleteAccount deletes the caller's account. Records other members share are
+// anonymized rather than deleted (see accounts.Anonymize), receipt images they uploaded
+// are removed from storage, and removeIdentity deletes their sign-in. It is refused
+// while they owe money on an open split. Money others still owe them is a warning
+// that ?force=true overrides.
+// DELETE /api/me
+func DeleteAccount(pool *pgxpool.Pool, queries tabmate.Querier, blobs storage.BlobStore, removeIdentity func(ctx context.Context, subject string) error) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		user, err := queries.GetUserByID(c, pgUserID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		balances, err := queries.ListOutstandingBalancesForUser(c, pgUserID)
+		if err != nil {
+			log.Printf("[DeleteAccount] ListOutstandingBalancesForUser error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check balances"})
+			return
+		}
+		var owes, owed []gin.H
+		for _, b := range balances {
+			amount, _ := b.Amount.Float64Value()
+			balance := gin.H{"split_code": b.SplitCode, "split_name": b.SplitName, "amount": amount.Float64}
+			if b.Direction == "owes" {
+				owes = append(owes, balance)
+			} else {
+				owed = append(owed, balance)
+			}
+		}
+		if len(owes) > 0 {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":       "Settle what you owe before deleting your account",
+				"you_owe":     owes,
+				"owed_to_you": owed,
+			})
+			return
+		}
+		if len(owed) > 0 && c.Query("force") != "true" {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":       "Members still owe you money. Deleting your account gives it up; repeat with ?force=true to go ahead",
+				"owed_to_you": owed,
+			})
+			return
+		}
+
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+			return
+		}
+		defer tx.Rollback(c)
+		qtx := tabmate.New(tx)
+
+		receiptKeys, err := qtx.DeleteSplitReceiptsByCreator(c, pgUserID)
+		if err == nil {
+			err = accounts.Anonymize(c, qtx, user)
+		}
+		if err == nil {
+			err = tx.Commit(c)
+		}
+		if err != nil {
+			log.Printf("[DeleteAccount] failed to delete user %v: %v", user.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+			return
+		}
+
+		removeReceiptImages(c, blobs, receiptKeys)
+		if err := removeIdentity(c, user.CognitoSub); err != nil {
+			log.Printf("[DeleteAccount] failed to delete sign-in %s: %v", user.CognitoSub, err)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Account deleted"})
+	}
+}
+
+// removeReceiptImages deletes stored receipt images. The rows are already gone, so a
+// failure only leaves an orphaned object behind; it is logged rather than returned.
+func removeReceiptImages(ctx context.Context, blobs storage.BlobStore, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	if blobs == nil {
+		log.Printf("[DeleteAccount] %d receipt images not removed: %v", len(keys), storage.ErrNotConfigured)
+		return
+	}
+	for _, key := range keys {
+		if err := blobs.Delete(ctx, key); err != nil {
+			log.Printf("[DeleteAccount] failed to remove receipt image %s: %v", key, err)
+		}
+	}
+}
+
+// ExportAccount returns a ZIP of everything stored about the caller: their profile,
+// bank details, payout methods and notification settings, the tables and splits they
+// are in, the items they added and claimed, their payments, and their activity.
+// GET /api/me/export
+func ExportAccount(queries tabmate.Querier, cipher *encryption.Envelope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		export, err := buildExport(c, queries, cipher, pgUserID)
+		if err != nil {
+			log.Printf("[ExportAccount] user %v: %v", pgUserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export account"})
+			return
+		}
+
+		now := time.Now()
+		var buf bytes.Buffer
+		if err := export.WriteZip(&buf, now); err != nil {
+			log.Printf("[ExportAccount] user %v: %v", pgUserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export account"})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="tabmate-export-%s.zip"`, now.Format("2006-01-02")))
+		c.Data(http.StatusOK, "application/zip", buf.Bytes())
+	}
+}
+
+func buildExport(c *gin.Context, queries tabmate.Querier, cipher *encryption.Envelope, userID pgtype.UUID) (accounts.Export, error) {
+	var export accounts.Export
+
+	user, err := queries.GetUserByID(c, userID)
+	if err != nil {
+		return export, fmt.Errorf("load user: %w", err)
+	}
+	bank, err := bankDetails(c, cipher, user, true)
+	if err != nil {
+		return export, fmt.Errorf("decrypt bank details: %w", err)
+	}
+	rows, err := queries.ListPayoutMethodsForUser(c, userID)
+	if err != nil {
+		return export, fmt.Errorf("list payout methods: %w", err)
+	}
+	methods := make([]payouts.Method, 0, len(rows))
+	for _, row := range rows {
+		method, err := payouts.Load(c, cipher, row)
+		if err != nil {
+			return export, fmt.Errorf("decrypt payout method: %w", err)
+		}
+		methods = append(methods, method)
+	}
+	prefs, err := notifications.LoadPreferences(c, queries, userID)
+	if err != nil {
+		return export, fmt.Errorf("load notification preferences: %w", err)
+	}
+	export.Profile = gin.H{
+		"id":                       uuid.UUID(user.ID.Bytes).String(),
+		"name":                     user.Name.String,
+		"email":                    user.Email,
+		"username":                 user.Username.String,
+		"phone_number":             user.PhoneNumber.String,
+		"privacy":                  privacySettings(user),
+		"timezone":                 user.Timezone,
+		"created_at":               user.CreatedAt.Time,
+		"bank_details":             bank,
+		"payout_methods":           methods,
+		"notification_preferences": prefs,
+	}
+
+	if export.Tables, err = queries.ListTablesWithMembershipStatusForUser(c, userID); err != nil {
+		return export, fmt.Errorf("list tables: %w", err)
+	}
+	if export.Splits, err = queries.ListSplitsForUser(c, userID); err != nil {
+		return export, fmt.Errorf("list splits: %w", err)
+	}
+	events, err := queries.ListActivityEventsByActor(c, userID)
+	if err != nil {
+		return export, fmt.Errorf("list activity: %w", err)
+	}
+	responses := make([]activity.ActivityEventResponse, len(events))
+	for i, e := range events {
+		responses[i] = activity.ToResponse(e)
+	}
+	export.Activity = responses
+
+	if export.Items, err = queries.ListItemsAddedByUser(c, userID); err != nil {
+		return export, fmt.Errorf("list items: %w", err)
+	}
+	if export.Claims, err = queries.ListSplitClaimsForUser(c, userID); err != nil {
+		return export, fmt.Errorf("list claims: %w", err)
+	}
+	if export.Payments, err = queries.ListSplitPaymentsForUser(c, userID); err != nil {
+		return export, fmt.Errorf("list payments: %w", err)
+	}
+	return export, nil
+}
@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"tabmate/internals/avatars"
+	"tabmate/internals/storage"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UploadAvatar sets the caller's profile picture from an image uploaded as the "avatar"
+// multipart field. The image is checked by its content, turned upright, cropped square
+// and stored as JPEG thumbnails of each of avatars.Sizes with its metadata removed; the
+// profile picture URL points at the largest. The previous avatar's images are deleted
+// by the sweeper a day later.
+// PUT /api/user/avatar
+func UploadAvatar(pool *pgxpool.Pool, queries tabmate.Querier, blobs storage.BlobStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		if blobs == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Avatar uploads are not available"})
+			return
+		}
+
+		file, header, err := c.Request.FormFile("avatar")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "avatar file is required"})
+			return
+		}
+		defer file.Close()
+
+		if header.Size > avatars.MaxUploadBytes {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "image too large, max 10MB"})
+			return
+		}
+		data, err := io.ReadAll(io.LimitReader(file, avatars.MaxUploadBytes+1))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read image"})
+			return
+		}
+
+		thumbs, err := avatars.Process(data)
+		switch {
+		case errors.Is(err, avatars.ErrTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "image too large, max 10MB"})
+			return
+		case errors.Is(err, avatars.ErrUnsupported), errors.Is(err, avatars.ErrTooSmall):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			log.Printf("[UploadAvatar] failed to process image for %v: %v", pgUserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process image"})
+			return
+		}
+
+		avatarID := uuid.New()
+		urls := make(map[string]string, len(thumbs))
+		keys := make([]string, 0, len(thumbs))
+		var profileURL string
+		for _, thumb := range thumbs {
+			key := fmt.Sprintf("avatars/%s/%s/%d.jpg", uuid.UUID(pgUserID.Bytes), avatarID, thumb.Size)
+			object, err := blobs.Upload(c, key, thumb.Data, avatars.MediaType)
+			if err != nil {
+				log.Printf("[UploadAvatar] failed to upload %s: %v", key, err)
+				removeObjects(c, blobs, keys)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store avatar"})
+				return
+			}
+			keys = append(keys, object.Key)
+			urls[strconv.Itoa(thumb.Size)] = object.URL
+			profileURL = object.URL
+		}
+
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
+		if err != nil {
+			removeObjects(c, blobs, keys)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update avatar"})
+			return
+		}
+		defer tx.Rollback(c)
+		qtx := tabmate.New(tx)
+
+		err = qtx.ReplaceAvatarsForUser(c, pgUserID)
+		if err == nil {
+			_, err = qtx.CreateAvatar(c, tabmate.CreateAvatarParams{
+				ID:         pgtype.UUID{Bytes: avatarID, Valid: true},
+				UserID:     pgUserID,
+				ObjectKeys: keys,
+			})
+		}
+		if err == nil {
+			_, err = qtx.UpdateUserProfilePictureURL(c, tabmate.UpdateUserProfilePictureURLParams{
+				ID:                pgUserID,
+				ProfilePictureUrl: pgtype.Text{String: profileURL, Valid: true},
+			})
+		}
+		if err == nil {
+			err = tx.Commit(c)
+		}
+		if err != nil {
+			log.Printf("[UploadAvatar] failed to save avatar for %v: %v", pgUserID, err)
+			removeObjects(c, blobs, keys)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update avatar"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"profile_picture_url": profileURL,
+			"sizes":               urls,
+		})
+	}
+}
+
+// DeleteAvatar removes the caller's profile picture. Its images are deleted by the
+// sweeper a day later.
+// DELETE /api/user/avatar
+func DeleteAvatar(pool *pgxpool.Pool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove avatar"})
+			return
+		}
+		defer tx.Rollback(c)
+		qtx := tabmate.New(tx)
+
+		err = qtx.ReplaceAvatarsForUser(c, pgUserID)
+		if err == nil {
+			_, err = qtx.UpdateUserProfilePictureURL(c, tabmate.UpdateUserProfilePictureURLParams{
+				ID: pgUserID,
+			})
+		}
+		if err == nil {
+			err = tx.Commit(c)
+		}
+		if err != nil {
+			log.Printf("[DeleteAvatar] failed to remove avatar for %v: %v", pgUserID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove avatar"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Avatar removed"})
+	}
+}
+
+// removeObjects deletes objects stored for an upload that could not be saved. Failures
+// only leave orphaned objects behind, so they are logged.
+func removeObjects(ctx context.Context, blobs storage.BlobStore, keys []string) {
+	for _, key := range keys {
+		if err := blobs.Delete(ctx, key); err != nil {
+			log.Printf("[UploadAvatar] failed to remove %s: %v", key, err)
+		}
+	}
+}
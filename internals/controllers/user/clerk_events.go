@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"tabmate/internals/accounts"
+	"tabmate/internals/auth"
+	tabmate "tabmate/internals/store/postgres"
+	"tabmate/internals/webhooks"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ClerkUserEventHandler keeps users rows in step with Clerk. Created and updated users
+// get Clerk's name and email; deleted users are anonymized with accounts.Anonymize.
+// forget is called with the subject afterwards so cached identities are dropped. It is
+// registered with the webhooks dispatcher as "clerk".
+func ClerkUserEventHandler(pool *pgxpool.Pool, queries tabmate.Querier, forget func(subject string)) webhooks.HandlerFunc {
+	return func(ctx context.Context, event *webhooks.Event) error {
+		userEvent, ok := event.Data.(*auth.ClerkUserEvent)
+		if !ok {
+			return fmt.Errorf("unexpected %s event data %T", event.Source, event.Data)
+		}
+		defer forget(userEvent.User.Subject)
+
+		switch userEvent.Type {
+		case auth.ClerkUserCreated, auth.ClerkUserUpdated:
+			return syncClerkUser(ctx, queries, userEvent.User)
+		case auth.ClerkUserDeleted:
+			return deleteClerkUser(ctx, pool, queries, userEvent.User.Subject)
+		default:
+			log.Printf("[users] ignoring clerk event %s", userEvent.Type)
+			return nil
+		}
+	}
+}
+
+func syncClerkUser(ctx context.Context, queries tabmate.Querier, identity auth.Identity) error {
+	user, err := queries.GetUserByCognitoSub(ctx, identity.Subject)
+	if errors.Is(err, pgx.ErrNoRows) {
+		_, err = queries.CreateUser(ctx, tabmate.CreateUserParams{
+			Name:       pgtype.Text{String: identity.Name, Valid: identity.Name != ""},
+			CognitoSub: identity.Subject,
+			Email:      identity.Email,
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if identity.Email != "" && identity.Email != user.Email {
+		if _, err := queries.UpdateUserEmail(ctx, tabmate.UpdateUserEmailParams{
+			ID:    user.ID,
+			Email: identity.Email,
+		}); err != nil {
+			return fmt.Errorf("update email: %w", err)
+		}
+	}
+	if identity.Name != "" && identity.Name != user.Name.String {
+		if _, err := queries.UpdateUserName(ctx, tabmate.UpdateUserNameParams{
+			ID:   user.ID,
+			Name: pgtype.Text{String: identity.Name, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("update name: %w", err)
+		}
+	}
+	return nil
+}
+
+func deleteClerkUser(ctx context.Context, pool *pgxpool.Pool, queries tabmate.Querier, subject string) error {
+	user, err := queries.GetUserByCognitoSub(ctx, subject)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// They never used TabMate, or were already deleted.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := accounts.Anonymize(ctx, tabmate.New(tx), user); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	log.Printf("[users] anonymized user %v after Clerk deleted %s", user.ID, subject)
+	return nil
+}
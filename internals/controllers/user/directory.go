@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"tabmate/internals/directory"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// pgUniqueViolation is Postgres' unique_violation error code.
+const pgUniqueViolation = "23505"
+
+// findUser looks someone up by an exact email, verified phone number or username, and
+// returns them only if their discoverability setting for that identifier lets viewer
+// find them. A hidden user looks the same as one that doesn't exist.
+func findUser(c *gin.Context, queries tabmate.Querier, viewer pgtype.UUID, query string) (tabmate.Users, bool, error) {
+	kind, value, err := directory.Classify(query)
+	if err != nil {
+		return tabmate.Users{}, false, nil
+	}
+
+	var user tabmate.Users
+	var visibility string
+	switch kind {
+	case directory.Email:
+		user, err = queries.FindUserByEmail(c, tabmate.FindUserByEmailParams{Email: value, UserID: viewer})
+		visibility = user.DiscoverableByEmail
+	case directory.Phone:
+		user, err = queries.FindUserByPhone(c, tabmate.FindUserByPhoneParams{PhoneNumber: value, UserID: viewer})
+		visibility = user.DiscoverableByPhone
+	default:
+		user, err = queries.FindUserByUsername(c, tabmate.FindUserByUsernameParams{Username: value, UserID: viewer})
+		visibility = user.DiscoverableByUsername
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return tabmate.Users{}, false, nil
+	}
+	if err != nil {
+		return tabmate.Users{}, false, err
+	}
+
+	switch directory.Visibility(visibility) {
+	case directory.Everyone:
+		return user, true, nil
+	case directory.Contacts:
+		connected, err := queries.IsConnected(c, tabmate.IsConnectedParams{UserID: user.ID, OtherID: viewer})
+		if err != nil {
+			return tabmate.Users{}, false, err
+		}
+		return user, connected, nil
+	}
+	return tabmate.Users{}, false, nil
+}
+
+// LookupUser finds someone to add to a split by their exact email, phone number (with
+// country code) or username, subject to their discoverability settings. The identifier
+// they were found by is not echoed back.
+// GET /api/users/lookup?q=
+func LookupUser(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		user, found, err := findUser(c, queries, pgUserID, c.Query("q"))
+		if err != nil {
+			log.Printf("[LookupUser] error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Lookup failed"})
+			return
+		}
+		if !found {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No one found. Check the email, phone number or username"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"id":                  uuid.UUID(user.ID.Bytes).String(),
+			"name":                user.Name.String,
+			"username":            user.Username.String,
+			"profile_picture_url": user.ProfilePictureUrl.String,
+		})
+	}
+}
+
+type UpdateUsernameRequest struct {
+	Username *string `json:"username"` // null or "" removes it
+}
+
+// UpdateUsername sets or removes the caller's username.
+// PATCH /api/user/username
+func UpdateUsername(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		var req UpdateUsernameRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		var username pgtype.Text
+		if req.Username != nil && *req.Username != "" {
+			normalized, err := directory.NormalizeUsername(*req.Username)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			username = pgtype.Text{String: normalized, Valid: true}
+		}
+
+		updated, err := queries.UpdateUsername(c, tabmate.UpdateUsernameParams{ID: pgUserID, Username: username})
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			c.JSON(http.StatusConflict, gin.H{"error": "That username is taken"})
+			return
+		}
+		if err != nil {
+			log.Printf("[UpdateUsername] error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update username"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"username": updated.Username.String})
+	}
+}
+
+type PrivacySettings struct {
+	DiscoverableByEmail    directory.Visibility `json:"discoverable_by_email"`
+	DiscoverableByPhone    directory.Visibility `json:"discoverable_by_phone"`
+	DiscoverableByUsername directory.Visibility `json:"discoverable_by_username"`
+}
+
+func privacySettings(user tabmate.Users) PrivacySettings {
+	return PrivacySettings{
+		DiscoverableByEmail:    directory.Visibility(user.DiscoverableByEmail),
+		DiscoverableByPhone:    directory.Visibility(user.DiscoverableByPhone),
+		DiscoverableByUsername: directory.Visibility(user.DiscoverableByUsername),
+	}
+}
+
+// GetPrivacySettings returns who can find the caller by their email, phone number and
+// username.
+// GET /api/user/privacy
+func GetPrivacySettings(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		user, err := queries.GetUserByID(c, pgUserID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusOK, privacySettings(user))
+	}
+}
+
+// UpdatePrivacySettings changes who can find the caller. Each setting is "everyone",
+// "contacts" or "nobody"; settings left out keep their value.
+// PUT /api/user/privacy
+func UpdatePrivacySettings(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		user, err := queries.GetUserByID(c, pgUserID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		settings := privacySettings(user)
+		if err := c.ShouldBindJSON(&settings); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		for _, v := range []directory.Visibility{settings.DiscoverableByEmail, settings.DiscoverableByPhone, settings.DiscoverableByUsername} {
+			if _, err := directory.ParseVisibility(string(v)); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		updated, err := queries.UpdateDiscoverability(c, tabmate.UpdateDiscoverabilityParams{
+			ID:                     pgUserID,
+			DiscoverableByEmail:    string(settings.DiscoverableByEmail),
+			DiscoverableByPhone:    string(settings.DiscoverableByPhone),
+			DiscoverableByUsername: string(settings.DiscoverableByUsername),
+		})
+		if err != nil {
+			log.Printf("[UpdatePrivacySettings] error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update privacy settings"})
+			return
+		}
+		c.JSON(http.StatusOK, privacySettings(updated))
+	}
+}
@@ -0,0 +1,339 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Friendship statuses stored in friendships.status.
+const (
+	friendshipPending  = "pending"
+	friendshipAccepted = "accepted"
+)
+
+const (
+	searchResults      = 10
+	suggestionResults  = 20
+	maxConnectionsPage = 200
+)
+
+// Connection is someone the caller knows: a friend, someone they have shared splits or
+// tables with, or both. Email is only shown for friends.
+type Connection struct {
+	ID                string     `json:"id"`
+	Name              string     `json:"name"`
+	Username          string     `json:"username,omitempty"`
+	Email             string     `json:"email,omitempty"`
+	ProfilePictureURL string     `json:"profile_picture_url"`
+	IsFriend          bool       `json:"is_friend"`
+	SharedCount       int32      `json:"shared_count"`
+	LastSharedAt      *time.Time `json:"last_shared_at"`
+}
+
+func toConnection(row tabmate.ListConnectionsForUserRow) Connection {
+	conn := Connection{
+		ID:                uuid.UUID(row.ID.Bytes).String(),
+		Name:              row.Name.String,
+		Username:          row.Username.String,
+		ProfilePictureURL: row.ProfilePictureUrl.String,
+		IsFriend:          row.IsFriend,
+		SharedCount:       row.SharedCount,
+	}
+	if row.IsFriend {
+		conn.Email = row.Email
+	}
+	if row.LastSharedAt.Valid {
+		conn.LastSharedAt = &row.LastSharedAt.Time
+	}
+	return conn
+}
+
+// listConnections runs ListConnectionsForUser and writes the error response itself if
+// it fails.
+func listConnections(c *gin.Context, queries tabmate.Querier, arg tabmate.ListConnectionsForUserParams) ([]Connection, bool) {
+	rows, err := queries.ListConnectionsForUser(c, arg)
+	if err != nil {
+		log.Printf("[ListConnectionsForUser] error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch contacts"})
+		return nil, false
+	}
+	conns := make([]Connection, len(rows))
+	for i, row := range rows {
+		conns[i] = toConnection(row)
+	}
+	return conns, true
+}
+
+// ListContacts returns the caller's friends and the people they have shared splits or
+// tables with, most shared first. ?q filters by name.
+// GET /api/contacts
+func ListContacts(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		conns, ok := listConnections(c, queries, tabmate.ListConnectionsForUserParams{
+			UserID:     pgUserID,
+			Search:     c.Query("q"),
+			MaxResults: maxConnectionsPage,
+		})
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, conns)
+	}
+}
+
+// ListFriends returns the caller's accepted friends.
+// GET /api/friends
+func ListFriends(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		conns, ok := listConnections(c, queries, tabmate.ListConnectionsForUserParams{
+			UserID:      pgUserID,
+			FriendsOnly: true,
+			MaxResults:  maxConnectionsPage,
+		})
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, conns)
+	}
+}
+
+// SuggestMembers returns the people the caller is most likely to add to a new split:
+// those they split with most often, then friends. ?limit caps the list at up to 20.
+// GET /api/users/suggestions
+func SuggestMembers(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		limit := suggestionResults
+		if raw := c.Query("limit"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 || n > suggestionResults {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 20"})
+				return
+			}
+			limit = n
+		}
+
+		conns, ok := listConnections(c, queries, tabmate.ListConnectionsForUserParams{
+			UserID:     pgUserID,
+			MaxResults: int32(limit),
+		})
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, conns)
+	}
+}
+
+// FriendRequestResponse is a pending request the caller sent or received.
+type FriendRequestResponse struct {
+	UserID            string    `json:"user_id"`
+	Name              string    `json:"name"`
+	ProfilePictureURL string    `json:"profile_picture_url"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// ListFriendRequests returns the caller's pending friend requests, split into those
+// waiting on them and those they sent.
+// GET /api/friends/requests
+func ListFriendRequests(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		rows, err := queries.ListFriendRequestsForUser(c, pgUserID)
+		if err != nil {
+			log.Printf("[ListFriendRequests] error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch friend requests"})
+			return
+		}
+
+		incoming := []FriendRequestResponse{}
+		outgoing := []FriendRequestResponse{}
+		for _, row := range rows {
+			req := FriendRequestResponse{
+				UserID:            uuid.UUID(row.OtherID.Bytes).String(),
+				Name:              row.OtherName.String,
+				ProfilePictureURL: row.OtherProfilePictureUrl.String,
+				CreatedAt:         row.CreatedAt.Time,
+			}
+			if row.AddresseeID == pgUserID {
+				incoming = append(incoming, req)
+			} else {
+				outgoing = append(outgoing, req)
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"incoming": incoming, "outgoing": outgoing})
+	}
+}
+
+type SendFriendRequestRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// SendFriendRequest asks another user to be friends. If they have already asked the
+// caller, the two become friends straight away.
+// POST /api/friends/requests
+func SendFriendRequest(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		var req SendFriendRequestRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
+			return
+		}
+		otherUUID, err := uuid.Parse(req.UserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		otherID := pgtype.UUID{Bytes: otherUUID, Valid: true}
+		if otherID == pgUserID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You can't add yourself as a friend"})
+			return
+		}
+
+		other, err := queries.GetUserByID(c, otherID)
+		if err != nil || other.IsPlaceholder {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		existing, err := queries.GetFriendship(c, tabmate.GetFriendshipParams{UserID: pgUserID, OtherID: otherID})
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+		case err != nil:
+			log.Printf("[SendFriendRequest] GetFriendship error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send friend request"})
+			return
+		case existing.Status == friendshipAccepted:
+			c.JSON(http.StatusConflict, gin.H{"error": "You are already friends"})
+			return
+		case existing.RequesterID == pgUserID:
+			c.JSON(http.StatusConflict, gin.H{"error": "Friend request already sent"})
+			return
+		default:
+			// They asked first: sending one back accepts theirs.
+			if _, err := queries.AcceptFriendRequest(c, tabmate.AcceptFriendRequestParams{
+				RequesterID: otherID,
+				AddresseeID: pgUserID,
+			}); err != nil {
+				log.Printf("[SendFriendRequest] AcceptFriendRequest error: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept friend request"})
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"status": friendshipAccepted})
+			return
+		}
+
+		if _, err := queries.CreateFriendRequest(c, tabmate.CreateFriendRequestParams{
+			RequesterID: pgUserID,
+			AddresseeID: otherID,
+		}); errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Friend request already sent"})
+			return
+		} else if err != nil {
+			log.Printf("[SendFriendRequest] CreateFriendRequest error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send friend request"})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"status": friendshipPending})
+	}
+}
+
+// AcceptFriendRequest accepts the request :userId sent the caller.
+// POST /api/friends/requests/:userId/accept
+func AcceptFriendRequest(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		otherID, ok := friendParam(c)
+		if !ok {
+			return
+		}
+
+		accepted, err := queries.AcceptFriendRequest(c, tabmate.AcceptFriendRequestParams{
+			RequesterID: otherID,
+			AddresseeID: pgUserID,
+		})
+		if err != nil {
+			log.Printf("[AcceptFriendRequest] error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept friend request"})
+			return
+		}
+		if accepted == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Friend request not found"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": friendshipAccepted})
+	}
+}
+
+// DeleteFriendRequest declines a request from :userId or cancels one sent to them.
+// DELETE /api/friends/requests/:userId
+func DeleteFriendRequest(queries tabmate.Querier) gin.HandlerFunc {
+	return deleteFriendship(queries, friendshipPending, "Friend request not found")
+}
+
+// RemoveFriend ends the caller's friendship with :userId.
+// DELETE /api/friends/:userId
+func RemoveFriend(queries tabmate.Querier) gin.HandlerFunc {
+	return deleteFriendship(queries, friendshipAccepted, "Friend not found")
+}
+
+func deleteFriendship(queries tabmate.Querier, status, notFound string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		otherID, ok := friendParam(c)
+		if !ok {
+			return
+		}
+
+		deleted, err := queries.DeleteFriendship(c, tabmate.DeleteFriendshipParams{
+			Status:  status,
+			UserID:  pgUserID,
+			OtherID: otherID,
+		})
+		if err != nil {
+			log.Printf("[DeleteFriendship] error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update friends"})
+			return
+		}
+		if deleted == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func friendParam(c *gin.Context) (pgtype.UUID, bool) {
+	otherUUID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return pgtype.UUID{}, false
+	}
+	return pgtype.UUID{Bytes: otherUUID, Valid: true}, true
+}
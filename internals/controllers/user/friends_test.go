@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	alice       = pgtype.UUID{Bytes: [16]byte{1}, Valid: true}
+	bob         = pgtype.UUID{Bytes: [16]byte{2}, Valid: true}
+	placeholder = pgtype.UUID{Bytes: [16]byte{3}, Valid: true}
+)
+
+// friendQueries keeps friendships in memory, following the friendships queries.
+// Methods the friends handlers do not call are left to the embedded nil interface.
+type friendQueries struct {
+	tabmate.Querier
+	rows []tabmate.Friendships
+}
+
+func (q *friendQueries) GetUserByID(ctx context.Context, id pgtype.UUID) (tabmate.Users, error) {
+	switch id {
+	case alice, bob:
+		return tabmate.Users{ID: id}, nil
+	case placeholder:
+		return tabmate.Users{ID: id, IsPlaceholder: true}, nil
+	}
+	return tabmate.Users{}, pgx.ErrNoRows
+}
+
+// find returns the index of the friendship between a and b, whichever of them asked.
+func (q *friendQueries) find(a, b pgtype.UUID) int {
+	for i, f := range q.rows {
+		if (f.RequesterID == a && f.AddresseeID == b) || (f.RequesterID == b && f.AddresseeID == a) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (q *friendQueries) GetFriendship(ctx context.Context, arg tabmate.GetFriendshipParams) (tabmate.Friendships, error) {
+	if i := q.find(arg.UserID, arg.OtherID); i >= 0 {
+		return q.rows[i], nil
+	}
+	return tabmate.Friendships{}, pgx.ErrNoRows
+}
+
+func (q *friendQueries) CreateFriendRequest(ctx context.Context, arg tabmate.CreateFriendRequestParams) (tabmate.Friendships, error) {
+	if q.find(arg.RequesterID, arg.AddresseeID) >= 0 {
+		return tabmate.Friendships{}, pgx.ErrNoRows
+	}
+	f := tabmate.Friendships{RequesterID: arg.RequesterID, AddresseeID: arg.AddresseeID, Status: friendshipPending}
+	q.rows = append(q.rows, f)
+	return f, nil
+}
+
+func (q *friendQueries) AcceptFriendRequest(ctx context.Context, arg tabmate.AcceptFriendRequestParams) (int64, error) {
+	for i, f := range q.rows {
+		if f.RequesterID == arg.RequesterID && f.AddresseeID == arg.AddresseeID && f.Status == friendshipPending {
+			q.rows[i].Status = friendshipAccepted
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (q *friendQueries) DeleteFriendship(ctx context.Context, arg tabmate.DeleteFriendshipParams) (int64, error) {
+	i := q.find(arg.UserID, arg.OtherID)
+	if i < 0 || q.rows[i].Status != arg.Status {
+		return 0, nil
+	}
+	q.rows = append(q.rows[:i], q.rows[i+1:]...)
+	return 1, nil
+}
+
+// friendsRouter serves the friends routes, signed in as whoever the X-User header
+// names.
+func friendsRouter(queries tabmate.Querier) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		id, _ := uuid.Parse(c.GetHeader("X-User"))
+		c.Set("user_id", pgtype.UUID{Bytes: id, Valid: true})
+	})
+	router.POST("/api/friends/requests", SendFriendRequest(queries))
+	router.POST("/api/friends/requests/:userId/accept", AcceptFriendRequest(queries))
+	router.DELETE("/api/friends/requests/:userId", DeleteFriendRequest(queries))
+	router.DELETE("/api/friends/:userId", RemoveFriend(queries))
+	return router
+}
+
+func call(t *testing.T, router *gin.Engine, caller pgtype.UUID, method, path, body string, want int) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User", uuid.UUID(caller.Bytes).String())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != want {
+		t.Fatalf("%s %s = %d, want %d: %s", method, path, w.Code, want, w.Body.String())
+	}
+}
+
+func id(u pgtype.UUID) string {
+	return uuid.UUID(u.Bytes).String()
+}
+
+func TestToConnection(t *testing.T) {
+	met := time.Date(2026, 3, 1, 19, 0, 0, 0, time.UTC)
+	friend := toConnection(tabmate.ListConnectionsForUserRow{
+		ID:           bob,
+		Name:         pgtype.Text{String: "Bob", Valid: true},
+		Email:        "bob@example.com",
+		SharedCount:  3,
+		LastSharedAt: pgtype.Timestamptz{Time: met, Valid: true},
+		IsFriend:     true,
+	})
+	if friend.ID != id(bob) || friend.Name != "Bob" || friend.Email != "bob@example.com" || friend.SharedCount != 3 {
+		t.Fatalf("friend = %+v", friend)
+	}
+	if friend.LastSharedAt == nil || !friend.LastSharedAt.Equal(met) {
+		t.Fatalf("friend.LastSharedAt = %v, want %v", friend.LastSharedAt, met)
+	}
+
+	stranger := toConnection(tabmate.ListConnectionsForUserRow{ID: bob, Email: "bob@example.com"})
+	if stranger.Email != "" {
+		t.Fatalf("a non-friend's email was shown: %q", stranger.Email)
+	}
+	if stranger.LastSharedAt != nil {
+		t.Fatalf("stranger.LastSharedAt = %v, want nil", stranger.LastSharedAt)
+	}
+}
+
+func TestSendFriendRequest(t *testing.T) {
+	q := &friendQueries{}
+	router := friendsRouter(q)
+	send := func(caller, other pgtype.UUID, want int) {
+		t.Helper()
+		call(t, router, caller, http.MethodPost, "/api/friends/requests", `{"user_id":"`+id(other)+`"}`, want)
+	}
+
+	send(alice, alice, http.StatusBadRequest)
+	send(alice, placeholder, http.StatusNotFound)
+	send(alice, bob, http.StatusCreated)
+	send(alice, bob, http.StatusConflict)
+	if len(q.rows) != 1 || q.rows[0].Status != friendshipPending {
+		t.Fatalf("friendships = %+v, want one pending request", q.rows)
+	}
+
+	// Bob asking back accepts Alice's request.
+	send(bob, alice, http.StatusOK)
+	if q.rows[0].Status != friendshipAccepted {
+		t.Fatalf("status = %q, want accepted", q.rows[0].Status)
+	}
+	send(alice, bob, http.StatusConflict)
+}
+
+func TestAcceptFriendRequest(t *testing.T) {
+	q := &friendQueries{}
+	router := friendsRouter(q)
+
+	call(t, router, bob, http.MethodPost, "/api/friends/requests/"+id(alice)+"/accept", "", http.StatusNotFound)
+	call(t, router, alice, http.MethodPost, "/api/friends/requests", `{"user_id":"`+id(bob)+`"}`, http.StatusCreated)
+	// Only the one asked can accept.
+	call(t, router, alice, http.MethodPost, "/api/friends/requests/"+id(bob)+"/accept", "", http.StatusNotFound)
+	call(t, router, bob, http.MethodPost, "/api/friends/requests/not-a-uuid/accept", "", http.StatusBadRequest)
+	call(t, router, bob, http.MethodPost, "/api/friends/requests/"+id(alice)+"/accept", "", http.StatusOK)
+	if q.rows[0].Status != friendshipAccepted {
+		t.Fatalf("status = %q, want accepted", q.rows[0].Status)
+	}
+}
+
+func TestRemoveFriend(t *testing.T) {
+	q := &friendQueries{}
+	router := friendsRouter(q)
+
+	call(t, router, alice, http.MethodPost, "/api/friends/requests", `{"user_id":"`+id(bob)+`"}`, http.StatusCreated)
+	// A pending request isn't a friendship yet.
+	call(t, router, alice, http.MethodDelete, "/api/friends/"+id(bob), "", http.StatusNotFound)
+
+	call(t, router, bob, http.MethodPost, "/api/friends/requests/"+id(alice)+"/accept", "", http.StatusOK)
+	// Either of them can end it, and it is gone for both.
+	call(t, router, bob, http.MethodDelete, "/api/friends/"+id(alice), "", http.StatusNoContent)
+	call(t, router, alice, http.MethodDelete, "/api/friends/"+id(bob), "", http.StatusNotFound)
+	if len(q.rows) != 0 {
+		t.Fatalf("friendships = %+v, want none", q.rows)
+	}
+}
+
+func TestDeleteFriendRequestCancelsPending(t *testing.T) {
+	q := &friendQueries{}
+	router := friendsRouter(q)
+
+	call(t, router, alice, http.MethodPost, "/api/friends/requests", `{"user_id":"`+id(bob)+`"}`, http.StatusCreated)
+	call(t, router, alice, http.MethodDelete, "/api/friends/requests/"+id(bob), "", http.StatusNoContent)
+	call(t, router, bob, http.MethodPost, "/api/friends/requests/"+id(alice)+"/accept", "", http.StatusNotFound)
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+	"tabmate/internals/notifications"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// GetNotificationPreferences returns which events notify the caller on which channels,
+// and their quiet hours.
+// GET /api/user/notification-preferences
+func GetNotificationPreferences(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		prefs, err := notifications.LoadPreferences(c, queries, pgUserID)
+		if err != nil {
+			log.Printf("[GetNotificationPreferences] error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification preferences"})
+			return
+		}
+
+		c.JSON(http.StatusOK, prefs)
+	}
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	Events     map[notifications.Event]map[notifications.Channel]bool `json:"events"`
+	QuietHours *notifications.QuietHours                              `json:"quiet_hours"`
+}
+
+// UpdateNotificationPreferences changes some of the caller's settings. Events and
+// channels left out of the request keep their current value.
+// PUT /api/user/notification-preferences
+func UpdateNotificationPreferences(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		var req UpdateNotificationPreferencesRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		for event, channels := range req.Events {
+			if !slices.Contains(notifications.Events, event) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown event: " + string(event)})
+				return
+			}
+			for channel := range channels {
+				if !slices.Contains(notifications.Channels, channel) {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown channel: " + string(channel)})
+					return
+				}
+			}
+		}
+		if q := req.QuietHours; q != nil && (q.Start < 0 || q.Start > 23 || q.End < 0 || q.End > 23) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Quiet hours must be whole hours between 0 and 23"})
+			return
+		}
+
+		prefs, err := notifications.LoadPreferences(c, queries, pgUserID)
+		if err != nil {
+			log.Printf("[UpdateNotificationPreferences] load error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences"})
+			return
+		}
+		prefs.Merge(req.Events)
+		if req.QuietHours != nil {
+			prefs.QuietHours = *req.QuietHours
+		}
+
+		channels, err := json.Marshal(prefs.Events)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences"})
+			return
+		}
+
+		if _, err := queries.UpsertNotificationPreferences(c, tabmate.UpsertNotificationPreferencesParams{
+			UserID:            pgUserID,
+			Channels:          channels,
+			QuietHoursEnabled: prefs.QuietHours.Enabled,
+			QuietHoursStart:   int32(prefs.QuietHours.Start),
+			QuietHoursEnd:     int32(prefs.QuietHours.End),
+		}); err != nil {
+			log.Printf("[UpdateNotificationPreferences] save error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences"})
+			return
+		}
+
+		c.JSON(http.StatusOK, prefs)
+	}
+}
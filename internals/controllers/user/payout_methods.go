@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"tabmate/internals/encryption"
+	"tabmate/internals/payouts"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ListPayoutMethods returns the caller's payout methods, default first.
+// GET /api/user/payout-methods
+func ListPayoutMethods(queries tabmate.Querier, cipher *encryption.Envelope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		rows, err := queries.ListPayoutMethodsForUser(c, pgUserID)
+		if err != nil {
+			log.Printf("[ListPayoutMethods] error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payout methods"})
+			return
+		}
+
+		methods := make([]payouts.Method, 0, len(rows))
+		for _, row := range rows {
+			method, err := payouts.Load(c, cipher, row)
+			if err != nil {
+				log.Printf("[ListPayoutMethods] decrypt error: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payout methods"})
+				return
+			}
+			methods = append(methods, method)
+		}
+
+		c.JSON(http.StatusOK, methods)
+	}
+}
+
+type CreatePayoutMethodRequest struct {
+	Type      payouts.Type    `json:"type" binding:"required"`
+	Label     string          `json:"label"`
+	Details   payouts.Details `json:"details"`
+	IsDefault bool            `json:"is_default"`
+}
+
+// CreatePayoutMethod validates and stores a new payout method. The first method a
+// user adds becomes their default.
+// POST /api/user/payout-methods
+func CreatePayoutMethod(pool *pgxpool.Pool, queries tabmate.Querier, cipher *encryption.Envelope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		var req CreatePayoutMethodRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "type and details are required"})
+			return
+		}
+		if err := payouts.Validate(req.Type, &req.Details); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		existing, err := queries.ListPayoutMethodsForUser(c, pgUserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create payout method"})
+			return
+		}
+
+		sealed, err := payouts.Seal(c, cipher, req.Details)
+		if err != nil {
+			log.Printf("[CreatePayoutMethod] encrypt error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create payout method"})
+			return
+		}
+
+		label := strings.TrimSpace(req.Label)
+		row, err := queries.CreatePayoutMethod(c, tabmate.CreatePayoutMethodParams{
+			UserID:     pgUserID,
+			MethodType: string(req.Type),
+			Label:      pgtype.Text{String: label, Valid: label != ""},
+			Details:    sealed,
+		})
+		if err != nil {
+			log.Printf("[CreatePayoutMethod] error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create payout method"})
+			return
+		}
+
+		if req.IsDefault || len(existing) == 0 {
+			if err := setDefaultPayoutMethod(c, pool, pgUserID, row.ID); err != nil {
+				log.Printf("[CreatePayoutMethod] set default error: %v", err)
+			} else {
+				row.IsDefault = true
+			}
+		}
+
+		method, err := payouts.Load(c, cipher, row)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load payout method"})
+			return
+		}
+		c.JSON(http.StatusCreated, method)
+	}
+}
+
+// SetDefaultPayoutMethod makes one of the caller's methods their default.
+// PATCH /api/user/payout-methods/:id/default
+func SetDefaultPayoutMethod(pool *pgxpool.Pool, queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		method, ok := ownPayoutMethod(c, queries, pgUserID)
+		if !ok {
+			return
+		}
+
+		err := setDefaultPayoutMethod(c, pool, pgUserID, method.ID)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			c.JSON(http.StatusConflict, gin.H{"error": "Default payout method changed at the same time, try again"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update default payout method"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// DeletePayoutMethod removes one of the caller's methods. Splits that used it fall back
+// to the default, and deleting the default promotes the oldest remaining method.
+// DELETE /api/user/payout-methods/:id
+func DeletePayoutMethod(pool *pgxpool.Pool, queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		method, ok := ownPayoutMethod(c, queries, pgUserID)
+		if !ok {
+			return
+		}
+
+		if err := queries.DeletePayoutMethod(c, tabmate.DeletePayoutMethodParams{
+			ID:     method.ID,
+			UserID: pgUserID,
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete payout method"})
+			return
+		}
+
+		if method.IsDefault {
+			remaining, err := queries.ListPayoutMethodsForUser(c, pgUserID)
+			if err == nil && len(remaining) > 0 {
+				if err := setDefaultPayoutMethod(c, pool, pgUserID, remaining[0].ID); err != nil {
+					log.Printf("[DeletePayoutMethod] set default error: %v", err)
+				}
+			}
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// setDefaultPayoutMethod makes id the user's default. The old default is cleared first
+// in the same transaction, because the unique index on defaults is checked row by row.
+// If a concurrent call sets another default first, the index makes this one fail with
+// a unique violation.
+func setDefaultPayoutMethod(ctx context.Context, pool *pgxpool.Pool, userID, id pgtype.UUID) error {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := tabmate.New(tx)
+
+	err = qtx.ClearDefaultPayoutMethod(ctx, userID)
+	if err == nil {
+		err = qtx.SetDefaultPayoutMethod(ctx, tabmate.SetDefaultPayoutMethodParams{
+			ID:     id,
+			UserID: userID,
+		})
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	return err
+}
+
+// ownPayoutMethod loads :id and checks it belongs to the caller, writing the error response if not.
+func ownPayoutMethod(c *gin.Context, queries tabmate.Querier, pgUserID pgtype.UUID) (tabmate.PayoutMethods, bool) {
+	methodUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payout method ID"})
+		return tabmate.PayoutMethods{}, false
+	}
+
+	method, err := queries.GetPayoutMethod(c, pgtype.UUID{Bytes: methodUUID, Valid: true})
+	if err != nil || method.UserID != pgUserID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payout method not found"})
+		return tabmate.PayoutMethods{}, false
+	}
+	return method, true
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"tabmate/internals/directory"
+	"tabmate/internals/sms"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type StartPhoneVerificationRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required"`
+}
+
+// StartPhoneVerification texts a code to the number the caller wants to add. The
+// number isn't saved until VerifyPhone confirms the code. With no SMS sender
+// configured, phone numbers can't be added.
+// PUT /api/user/phone
+func StartPhoneVerification(queries tabmate.Querier, sender sms.Sender) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		if sender == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Phone verification is not available"})
+			return
+		}
+
+		var req StartPhoneVerificationRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "phone_number is required"})
+			return
+		}
+		phone, err := directory.NormalizePhone(req.PhoneNumber)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		code, err := directory.GenerateCode()
+		if err != nil {
+			log.Printf("[StartPhoneVerification] generate error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send code"})
+			return
+		}
+		if err := queries.UpsertPhoneVerification(c, tabmate.UpsertPhoneVerificationParams{
+			UserID:      pgUserID,
+			PhoneNumber: phone,
+			CodeHash:    directory.HashCode(phone, code),
+			ExpiresAt:   pgtype.Timestamptz{Time: time.Now().Add(directory.CodeTTL), Valid: true},
+		}); err != nil {
+			log.Printf("[StartPhoneVerification] error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send code"})
+			return
+		}
+
+		body := fmt.Sprintf("Your TabMate code is %s. It expires in %d minutes.", code, int(directory.CodeTTL.Minutes()))
+		if err := sender.Send(c, phone, body); err != nil {
+			log.Printf("[StartPhoneVerification] send error: %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to send code"})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{"phone_number": phone, "expires_in_seconds": int(directory.CodeTTL.Seconds())})
+	}
+}
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// VerifyPhone checks the code sent by StartPhoneVerification and saves the number as
+// the caller's verified phone. A number is only ever verified on one account, so it is
+// taken off any account that had it before.
+// POST /api/user/phone/verify
+func VerifyPhone(pool *pgxpool.Pool, queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		var req VerifyPhoneRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "code is required"})
+			return
+		}
+
+		pending, err := queries.GetPhoneVerification(c, pgUserID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No code was sent. Add your phone number first"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify phone number"})
+			return
+		}
+		if time.Now().After(pending.ExpiresAt.Time) || pending.Attempts >= directory.MaxCodeAttempts {
+			c.JSON(http.StatusGone, gin.H{"error": "This code has expired. Ask for a new one"})
+			return
+		}
+		if subtle.ConstantTimeCompare(directory.HashCode(pending.PhoneNumber, req.Code), pending.CodeHash) != 1 {
+			if err := queries.IncrementPhoneVerificationAttempts(c, pgUserID); err != nil {
+				log.Printf("[VerifyPhone] IncrementPhoneVerificationAttempts error: %v", err)
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Wrong code"})
+			return
+		}
+
+		tx, err := pool.BeginTx(c, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify phone number"})
+			return
+		}
+		defer tx.Rollback(c)
+		qtx := tabmate.New(tx)
+
+		phone := pgtype.Text{String: pending.PhoneNumber, Valid: true}
+		err = qtx.ReleasePhoneNumber(c, tabmate.ReleasePhoneNumberParams{PhoneNumber: phone, ID: pgUserID})
+		if err == nil {
+			_, err = qtx.SetVerifiedPhoneNumber(c, tabmate.SetVerifiedPhoneNumberParams{ID: pgUserID, PhoneNumber: phone})
+		}
+		if err == nil {
+			err = qtx.DeletePhoneVerification(c, pgUserID)
+		}
+		if err == nil {
+			err = tx.Commit(c)
+		}
+		if err != nil {
+			log.Printf("[VerifyPhone] error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify phone number"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"phone_number": pending.PhoneNumber, "phone_verified": true})
+	}
+}
+
+// RemovePhone removes the caller's phone number and any code pending for it.
+// DELETE /api/user/phone
+func RemovePhone(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		if err := queries.DeletePhoneVerification(c, pgUserID); err != nil {
+			log.Printf("[RemovePhone] DeletePhoneVerification error: %v", err)
+		}
+		if _, err := queries.ClearPhoneNumber(c, pgUserID); err != nil {
+			log.Printf("[RemovePhone] error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove phone number"})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -3,8 +3,11 @@ package controllers
 import (
 	"log"
 	"net/http"
+	"slices"
 	"strings"
+	"tabmate/internals/encryption"
 	tabmate "tabmate/internals/store/postgres"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -52,9 +55,14 @@ func CreateUser(queries tabmate.Querier) gin.HandlerFunc {
 }
 
 type UpdatePushTokenRequest struct {
-	PushToken string `json:"push_token" binding:"required"`
+	PushToken  string `json:"push_token" binding:"required"`
+	Platform   string `json:"platform"`
+	AppVersion string `json:"app_version"`
 }
 
+// UpdatePushToken registers the calling device for push notifications. Each device
+// keeps its own token, so signing in on a second device does not silence the first.
+// PATCH /api/user/push-token
 func UpdatePushToken(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, _ := c.Get("user_id")
@@ -66,9 +74,21 @@ func UpdatePushToken(queries tabmate.Querier) gin.HandlerFunc {
 			return
 		}
 
-		err := queries.UpdateUserPushToken(c, tabmate.UpdateUserPushTokenParams{
-			PushToken: pgtype.Text{String: req.PushToken, Valid: true},
-			ID:        pgUserID,
+		platform := strings.ToLower(req.Platform)
+		switch platform {
+		case "ios", "android", "web":
+		case "":
+			platform = "unknown"
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "platform must be ios, android or web"})
+			return
+		}
+
+		_, err := queries.UpsertDeviceToken(c, tabmate.UpsertDeviceTokenParams{
+			UserID:     pgUserID,
+			Token:      req.PushToken,
+			Platform:   platform,
+			AppVersion: pgtype.Text{String: req.AppVersion, Valid: req.AppVersion != ""},
 		})
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update push token"})
@@ -79,6 +99,74 @@ func UpdatePushToken(queries tabmate.Querier) gin.HandlerFunc {
 	}
 }
 
+type RemovePushTokenRequest struct {
+	PushToken string `json:"push_token" binding:"required"`
+}
+
+// RemovePushToken unregisters the calling device, e.g. when the user logs out on it.
+// DELETE /api/user/push-token
+func RemovePushToken(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		var req RemovePushTokenRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "push_token is required"})
+			return
+		}
+
+		if _, err := queries.DeleteDeviceToken(c, tabmate.DeleteDeviceTokenParams{
+			UserID: pgUserID,
+			Token:  req.PushToken,
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove push token"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+type UpdateTimezoneRequest struct {
+	Timezone string `json:"timezone" binding:"required"`
+}
+
+// UpdateTimezone stores the caller's IANA timezone, used to keep automatic
+// reminders out of their quiet hours.
+// PATCH /api/user/timezone
+func UpdateTimezone(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		var req UpdateTimezoneRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "timezone is required"})
+			return
+		}
+		if _, err := time.LoadLocation(req.Timezone); err != nil || req.Timezone == "Local" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown timezone"})
+			return
+		}
+
+		err := queries.UpdateUserTimezone(c, tabmate.UpdateUserTimezoneParams{
+			Timezone: req.Timezone,
+			ID:       pgUserID,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update timezone"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// SearchUsers finds people by name among the caller's friends and the people they
+// have shared splits or tables with. Anyone else can only be found by their exact
+// email, phone number or username, and only if their privacy settings allow it.
+// GET /api/users/search
 func SearchUsers(queries tabmate.Querier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, _ := c.Get("user_id")
@@ -90,27 +178,30 @@ func SearchUsers(queries tabmate.Querier) gin.HandlerFunc {
 			return
 		}
 
-		users, err := queries.SearchUsersByName(c, tabmate.SearchUsersByNameParams{
-			Column1: pgtype.Text{String: q, Valid: true},
-			ID:      pgUserID,
+		response, ok := listConnections(c, queries, tabmate.ListConnectionsForUserParams{
+			UserID:     pgUserID,
+			Search:     q,
+			MaxResults: searchResults,
 		})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed"})
+		if !ok {
 			return
 		}
 
-		var response []gin.H
-		for _, u := range users {
-			response = append(response, gin.H{
-				"id":                  uuid.UUID(u.ID.Bytes).String(),
-				"name":                u.Name.String,
-				"email":               u.Email,
-				"profile_picture_url": u.ProfilePictureUrl.String,
-			})
+		u, found, err := findUser(c, queries, pgUserID, q)
+		if err != nil {
+			log.Printf("[SearchUsers] exact lookup error: %v", err)
 		}
-
-		if response == nil {
-			response = []gin.H{}
+		if found {
+			id := uuid.UUID(u.ID.Bytes).String()
+			known := slices.ContainsFunc(response, func(conn Connection) bool { return conn.ID == id })
+			if !known {
+				response = append(response, Connection{
+					ID:                id,
+					Name:              u.Name.String,
+					Username:          u.Username.String,
+					ProfilePictureURL: u.ProfilePictureUrl.String,
+				})
+			}
 		}
 
 		c.JSON(http.StatusOK, response)
@@ -119,7 +210,7 @@ func SearchUsers(queries tabmate.Querier) gin.HandlerFunc {
 
 // GetUser returns the current authenticated user's profile.
 // Must be called on a protected route (AuthMiddleware sets user_id in context).
-func GetUser(queries tabmate.Querier) gin.HandlerFunc {
+func GetUser(queries tabmate.Querier, cipher *encryption.Envelope) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, _ := c.Get("user_id")
 		pgUserID := userID.(pgtype.UUID)
@@ -131,17 +222,100 @@ func GetUser(queries tabmate.Querier) gin.HandlerFunc {
 			return
 		}
 
+		bank, err := bankDetails(c, cipher, user, true)
+		if err != nil {
+			log.Printf("[GetUser] decrypt bank details error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load bank details"})
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"id":             uuid.UUID(user.ID.Bytes).String(),
 			"name":           user.Name.String,
 			"email":          user.Email,
-			"bank_name":      user.BankName.String,
-			"account_name":   user.AccountName.String,
-			"account_number": user.AccountNumber.String,
+			"username":       user.Username.String,
+			"phone_number":   user.PhoneNumber.String,
+			"phone_verified": user.PhoneVerifiedAt.Valid,
+			"privacy":        privacySettings(user),
+			"bank_name":      bank["bank_name"],
+			"account_name":   bank["account_name"],
+			"account_number": bank["account_number"],
 		})
 	}
 }
 
+// GetUserBankDetails returns another user's bank details. The account number is only
+// shown in full to its owner and to members who still owe that user on a split.
+// GET /api/users/:id/bank-details
+func GetUserBankDetails(queries tabmate.Querier, cipher *encryption.Envelope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		pgUserID := userID.(pgtype.UUID)
+
+		targetUUID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		pgTargetID := pgtype.UUID{Bytes: targetUUID, Valid: true}
+
+		user, err := queries.GetUserByID(c, pgTargetID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		full := pgUserID == pgTargetID
+		if !full {
+			full, err = queries.UserOwesUser(c, tabmate.UserOwesUserParams{
+				DebtorID:   pgUserID,
+				CreditorID: pgTargetID,
+			})
+			if err != nil {
+				log.Printf("[GetUserBankDetails] UserOwesUser error: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load bank details"})
+				return
+			}
+		}
+
+		bank, err := bankDetails(c, cipher, user, full)
+		if err != nil {
+			log.Printf("[GetUserBankDetails] decrypt bank details error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load bank details"})
+			return
+		}
+		bank["user_id"] = uuid.UUID(user.ID.Bytes).String()
+		bank["masked"] = !full
+
+		c.JSON(http.StatusOK, bank)
+	}
+}
+
+// bankDetails decrypts a user's bank fields, masking the account number unless full is set.
+func bankDetails(c *gin.Context, cipher *encryption.Envelope, user tabmate.Users, full bool) (gin.H, error) {
+	bankName, err := cipher.DecryptText(c, user.BankName)
+	if err != nil {
+		return nil, err
+	}
+	accountName, err := cipher.DecryptText(c, user.AccountName)
+	if err != nil {
+		return nil, err
+	}
+	accountNumber, err := cipher.DecryptText(c, user.AccountNumber)
+	if err != nil {
+		return nil, err
+	}
+	if !full {
+		accountNumber = encryption.Mask(accountNumber)
+	}
+
+	return gin.H{
+		"bank_name":      bankName,
+		"account_name":   accountName,
+		"account_number": accountNumber,
+	}, nil
+}
+
 type UpdateProfileRequest struct {
 	Name string `json:"name" binding:"required"`
 }
@@ -180,7 +354,7 @@ type UpdateBankDetailsRequest struct {
 	AccountNumber string `json:"account_number" binding:"required"`
 }
 
-func UpdateBankDetails(queries tabmate.Querier) gin.HandlerFunc {
+func UpdateBankDetails(queries tabmate.Querier, cipher *encryption.Envelope) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, _ := c.Get("user_id")
 		pgUserID := userID.(pgtype.UUID)
@@ -191,10 +365,29 @@ func UpdateBankDetails(queries tabmate.Querier) gin.HandlerFunc {
 			return
 		}
 
-		err := queries.UpdateBankDetails(c, tabmate.UpdateBankDetailsParams{
-			BankName:      pgtype.Text{String: req.BankName, Valid: true},
-			AccountName:   pgtype.Text{String: req.AccountName, Valid: true},
-			AccountNumber: pgtype.Text{String: req.AccountNumber, Valid: true},
+		bankName, err := cipher.EncryptText(c, req.BankName)
+		if err != nil {
+			log.Printf("[UpdateBankDetails] encrypt error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update bank details"})
+			return
+		}
+		accountName, err := cipher.EncryptText(c, req.AccountName)
+		if err != nil {
+			log.Printf("[UpdateBankDetails] encrypt error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update bank details"})
+			return
+		}
+		accountNumber, err := cipher.EncryptText(c, req.AccountNumber)
+		if err != nil {
+			log.Printf("[UpdateBankDetails] encrypt error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update bank details"})
+			return
+		}
+
+		err = queries.UpdateBankDetails(c, tabmate.UpdateBankDetailsParams{
+			BankName:      bankName,
+			AccountName:   accountName,
+			AccountNumber: accountNumber,
 			ID:            pgUserID,
 		})
 		if err != nil {
@@ -0,0 +1,49 @@
+package webhookcontroller
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"tabmate/internals/webhooks"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBody caps inbound payloads; provider events are a few kilobytes at most.
+const maxWebhookBody = 1 << 20
+
+// HandleWebhook verifies a signed provider delivery, stores it and dispatches it once.
+// Failures return 5xx so the provider retries; duplicates are acknowledged with 200, and
+// deliveries of an event that is still being processed get 409 so they are retried.
+// POST /api/webhooks/:provider
+func HandleWebhook(dispatcher *webhooks.Dispatcher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		source := c.Param("provider")
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read body"})
+			return
+		}
+
+		result, err := dispatcher.Handle(c.Request.Context(), source, c.Request.Header, body)
+		switch {
+		case err == nil:
+			c.JSON(http.StatusOK, gin.H{"message": "Accepted", "result": result})
+		case errors.Is(err, webhooks.ErrUnknownSource):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unknown webhook provider"})
+		case errors.Is(err, webhooks.ErrInvalidSignature), errors.Is(err, webhooks.ErrStaleTimestamp):
+			log.Printf("[webhooks] %s delivery rejected: %v", source, err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+		case errors.Is(err, webhooks.ErrInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": "Event is already being processed"})
+		case errors.Is(err, webhooks.ErrMalformedPayload):
+			log.Printf("[webhooks] %s delivery rejected: %v", source, err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook payload"})
+		default:
+			log.Printf("[webhooks] %s delivery failed: %v", source, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process webhook"})
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Package directory normalizes the usernames, emails and phone numbers people are
+// looked up by, and decides who may find whom with them.
+package directory
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Visibility is who can find a user by one of their identifiers, stored in the
+// users.discoverable_by_* columns.
+type Visibility string
+
+const (
+	Everyone Visibility = "everyone"
+	Contacts Visibility = "contacts" // friends and people they share splits or tables with
+	Nobody   Visibility = "nobody"
+)
+
+// ParseVisibility checks a visibility from a request.
+func ParseVisibility(s string) (Visibility, error) {
+	v := Visibility(s)
+	if v != Everyone && v != Contacts && v != Nobody {
+		return "", fmt.Errorf("visibility must be %q, %q or %q", Everyone, Contacts, Nobody)
+	}
+	return v, nil
+}
+
+// Allows reports whether someone can find the user, given whether they are one of the
+// user's contacts.
+func (v Visibility) Allows(isContact bool) bool {
+	switch v {
+	case Everyone:
+		return true
+	case Contacts:
+		return isContact
+	}
+	return false
+}
+
+// Kind is what an exact-match lookup is for.
+type Kind string
+
+const (
+	Email    Kind = "email"
+	Phone    Kind = "phone"
+	Username Kind = "username"
+)
+
+var (
+	ErrInvalidUsername = errors.New("usernames are 3 to 30 lowercase letters, digits, dots or underscores, starting with a letter")
+	ErrReservedName    = errors.New("that username is reserved")
+	ErrInvalidPhone    = errors.New("phone numbers need a country code, like +44 7700 900123")
+	ErrInvalidEmail    = errors.New("invalid email address")
+)
+
+const (
+	minUsernameLength = 3
+	maxUsernameLength = 30
+	minPhoneDigits    = 8
+	maxPhoneDigits    = 15 // E.164
+)
+
+// reserved are usernames nobody can take, so they can't be used to pass as the app.
+var reserved = []string{"admin", "administrator", "deleted", "help", "me", "root", "support", "system", "tabmate"}
+
+// Classify decides what an exact-match query is and returns it normalized. Anything
+// with an @ after the first character is an email, anything starting with + or a digit
+// is a phone number, and the rest, with or without a leading @, is a username.
+func Classify(query string) (Kind, string, error) {
+	q := strings.TrimSpace(query)
+	switch {
+	case strings.Index(q, "@") > 0:
+		email, err := NormalizeEmail(q)
+		return Email, email, err
+	case q != "" && (q[0] == '+' || q[0] >= '0' && q[0] <= '9'):
+		phone, err := NormalizePhone(q)
+		return Phone, phone, err
+	}
+	username, err := NormalizeUsername(q)
+	return Username, username, err
+}
+
+// NormalizeUsername lower-cases a username, drops a leading @ and checks it.
+func NormalizeUsername(s string) (string, error) {
+	u := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(s), "@"))
+	if len(u) < minUsernameLength || len(u) > maxUsernameLength || u[0] < 'a' || u[0] > 'z' {
+		return "", ErrInvalidUsername
+	}
+	for _, r := range u {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '.') {
+			return "", ErrInvalidUsername
+		}
+	}
+	if strings.HasSuffix(u, ".") || strings.Contains(u, "..") {
+		return "", ErrInvalidUsername
+	}
+	if slices.Contains(reserved, u) {
+		return "", ErrReservedName
+	}
+	return u, nil
+}
+
+// NormalizePhone returns a phone number in E.164 form. Spaces, dashes, dots and
+// brackets are dropped, as is a bracketed trunk prefix as in +44 (0)20; the number must
+// start with + and its country code.
+func NormalizePhone(s string) (string, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "(0)", "")
+	if !strings.HasPrefix(s, "+") {
+		return "", ErrInvalidPhone
+	}
+	var b strings.Builder
+	b.WriteByte('+')
+	for _, r := range s[1:] {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+	digits := b.Len() - 1
+	if digits < minPhoneDigits || digits > maxPhoneDigits || b.String()[1] == '0' {
+		return "", ErrInvalidPhone
+	}
+	return b.String(), nil
+}
+
+// NormalizeEmail lower-cases an email address for comparison.
+func NormalizeEmail(s string) (string, error) {
+	e := strings.ToLower(strings.TrimSpace(s))
+	at := strings.LastIndex(e, "@")
+	if at < 1 || at == len(e)-1 || strings.ContainsAny(e, " \t") {
+		return "", ErrInvalidEmail
+	}
+	return e, nil
+}
+
+// Phone verification codes are short, so they expire quickly and allow few guesses.
+const (
+	CodeLength      = 6
+	CodeTTL         = 10 * time.Minute
+	MaxCodeAttempts = 5
+)
+
+// GenerateCode returns a random numeric verification code.
+func GenerateCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", CodeLength, n.Int64()), nil
+}
+
+// HashCode returns the value stored for a code sent to phone. Binding the number in
+// means a code only confirms the number it was sent to.
+func HashCode(phone, code string) []byte {
+	sum := sha256.Sum256([]byte(phone + ":" + code))
+	return sum[:]
+}
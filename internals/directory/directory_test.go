@@ -0,0 +1,67 @@
+package directory
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestClassify(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		kind  Kind
+		want  string
+		ok    bool
+	}{
+		{" Sam@Example.com ", Email, "sam@example.com", true},
+		{"+44 (0)7700-900 123", Phone, "+447700900123", true},
+		{"+44 7700 900123", Phone, "+447700900123", true},
+		{"07700900123", Phone, "", false}, // no country code
+		{"@Sam_Smith", Username, "sam_smith", true},
+		{"sam.smith", Username, "sam.smith", true},
+		{"sa", Username, "", false},
+		{"_sam", Username, "", false},
+		{"sam..smith", Username, "", false},
+		{"sam smith", Username, "", false},
+		{"tabmate", Username, "", false},
+	} {
+		kind, got, err := Classify(tc.query)
+		if kind != tc.kind || got != tc.want || (err == nil) != tc.ok {
+			t.Errorf("Classify(%q) = %s %q %v; want %s %q ok=%v", tc.query, kind, got, err, tc.kind, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestVisibilityAllows(t *testing.T) {
+	if !Everyone.Allows(false) || !Contacts.Allows(true) || Contacts.Allows(false) || Nobody.Allows(true) {
+		t.Fatal("visibility rules are wrong")
+	}
+	if _, err := ParseVisibility("friends"); err == nil {
+		t.Fatal("ParseVisibility accepted an unknown value")
+	}
+}
+
+func TestCodes(t *testing.T) {
+	code, err := GenerateCode()
+	if err != nil || len(code) != CodeLength {
+		t.Fatalf("GenerateCode = %q, %v", code, err)
+	}
+	if bytes.Equal(HashCode("+447700900123", code), HashCode("+447700900124", code)) {
+		t.Fatal("a code hash should depend on the phone number")
+	}
+}
+
+func TestEmailFilter(t *testing.T) {
+	viewer := pgtype.UUID{Bytes: [16]byte{1}, Valid: true}
+	friend := pgtype.UUID{Bytes: [16]byte{2}, Valid: true}
+	stranger := pgtype.UUID{Bytes: [16]byte{3}, Valid: true}
+	f := EmailFilter{viewer: viewer, friends: map[[16]byte]bool{friend.Bytes: true}}
+
+	if f.Email(viewer, "me@example.com") == "" || f.Email(friend, "friend@example.com") == "" {
+		t.Error("own and friends' emails should be shown")
+	}
+	if got := f.Email(stranger, "stranger@example.com"); got != "" {
+		t.Errorf("stranger's email shown: %q", got)
+	}
+}
@@ -0,0 +1,37 @@
+package directory
+
+import (
+	"context"
+
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// EmailFilter hides email addresses from a viewer unless they are the viewer's own or
+// a friend's. Sharing a split or table with someone isn't enough to see theirs.
+type EmailFilter struct {
+	viewer  pgtype.UUID
+	friends map[[16]byte]bool
+}
+
+// LoadEmailFilter looks up viewer's friends.
+func LoadEmailFilter(ctx context.Context, queries tabmate.Querier, viewer pgtype.UUID) (EmailFilter, error) {
+	ids, err := queries.ListFriendIDs(ctx, viewer)
+	if err != nil {
+		return EmailFilter{}, err
+	}
+	friends := make(map[[16]byte]bool, len(ids))
+	for _, id := range ids {
+		friends[id.Bytes] = true
+	}
+	return EmailFilter{viewer: viewer, friends: friends}, nil
+}
+
+// Email returns email if the viewer may see the address of userID, and "" otherwise.
+func (f EmailFilter) Email(userID pgtype.UUID, email string) string {
+	if userID == f.viewer || f.friends[userID.Bytes] {
+		return email
+	}
+	return ""
+}
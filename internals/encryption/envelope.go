@@ -0,0 +1,166 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// prefix marks an encrypted value. Anything without it is legacy plaintext.
+const prefix = "enc:v1:"
+
+var ErrMalformedCiphertext = errors.New("malformed ciphertext")
+
+// Envelope encrypts each value with a fresh AES-256-GCM data key and stores that key
+// wrapped by the KeyProvider next to the ciphertext:
+//
+//	enc:v1:<key id>:<wrapped data key>:<nonce+ciphertext>
+//
+// KMS key ids such as ARNs contain colons themselves, so the two base64 fields are
+// read from the right and everything before them is the key id.
+type Envelope struct {
+	keys KeyProvider
+}
+
+func NewEnvelope(keys KeyProvider) *Envelope {
+	return &Envelope{keys: keys}
+}
+
+func (e *Envelope) Encrypt(ctx context.Context, plaintext string) (string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	keyID := e.keys.CurrentKeyID()
+	wrapped, err := e.keys.WrapKey(ctx, keyID, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("wrap data key: %w", err)
+	}
+
+	return prefix + keyID + ":" +
+		base64.RawStdEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawStdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt reverses Encrypt. Legacy plaintext values are returned unchanged.
+func (e *Envelope) Decrypt(ctx context.Context, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	keyID, wrapped, ciphertext, err := split(value)
+	if err != nil {
+		return "", err
+	}
+	dataKey, err := e.keys.UnwrapKey(ctx, keyID, wrapped)
+	if err != nil {
+		return "", fmt.Errorf("unwrap data key: %w", err)
+	}
+	plaintext, err := open(dataKey, ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// EncryptText encrypts s into a nullable column value; empty strings stay NULL.
+func (e *Envelope) EncryptText(ctx context.Context, s string) (pgtype.Text, error) {
+	if s == "" {
+		return pgtype.Text{}, nil
+	}
+	encrypted, err := e.Encrypt(ctx, s)
+	if err != nil {
+		return pgtype.Text{}, err
+	}
+	return pgtype.Text{String: encrypted, Valid: true}, nil
+}
+
+// DecryptText decrypts a nullable column value; NULL decrypts to "".
+func (e *Envelope) DecryptText(ctx context.Context, t pgtype.Text) (string, error) {
+	if !t.Valid {
+		return "", nil
+	}
+	return e.Decrypt(ctx, t.String)
+}
+
+// NeedsRotation reports whether value is plaintext or wrapped with a retired key.
+func (e *Envelope) NeedsRotation(value string) bool {
+	if !IsEncrypted(value) {
+		return true
+	}
+	keyID, _, _, err := split(value)
+	return err != nil || keyID != e.keys.CurrentKeyID()
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+func split(value string) (string, []byte, []byte, error) {
+	rest := strings.TrimPrefix(value, prefix)
+	i := strings.LastIndex(rest, ":")
+	if i < 0 {
+		return "", nil, nil, ErrMalformedCiphertext
+	}
+	rest, encodedCiphertext := rest[:i], rest[i+1:]
+	i = strings.LastIndex(rest, ":")
+	if i <= 0 {
+		return "", nil, nil, ErrMalformedCiphertext
+	}
+	keyID, encodedWrapped := rest[:i], rest[i+1:]
+
+	wrapped, err := base64.RawStdEncoding.DecodeString(encodedWrapped)
+	if err != nil {
+		return "", nil, nil, ErrMalformedCiphertext
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(encodedCiphertext)
+	if err != nil {
+		return "", nil, nil, ErrMalformedCiphertext
+	}
+	return keyID, wrapped, ciphertext, nil
+}
+
+// seal encrypts with AES-GCM and prepends the random nonce.
+func seal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, sealed []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrMalformedCiphertext
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,115 @@
+package encryption
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), 32)))
+}
+
+func TestEnvelopeRoundTripAndRotation(t *testing.T) {
+	ctx := context.Background()
+
+	oldKeys, err := NewLocalKeyProvider("k1:" + testKey('a'))
+	if err != nil {
+		t.Fatalf("NewLocalKeyProvider: %v", err)
+	}
+	old := NewEnvelope(oldKeys)
+
+	encrypted, err := old.Encrypt(ctx, "0123456789")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !IsEncrypted(encrypted) || strings.Contains(encrypted, "0123456789") {
+		t.Fatalf("Encrypt leaked plaintext: %q", encrypted)
+	}
+
+	rotatedKeys, err := NewLocalKeyProvider("k2:" + testKey('b') + ",k1:" + testKey('a'))
+	if err != nil {
+		t.Fatalf("NewLocalKeyProvider: %v", err)
+	}
+	rotated := NewEnvelope(rotatedKeys)
+
+	if !rotated.NeedsRotation(encrypted) || !rotated.NeedsRotation("legacy plaintext") {
+		t.Fatal("values under a retired key or in plaintext should need rotation")
+	}
+	plaintext, err := rotated.Decrypt(ctx, encrypted)
+	if err != nil || plaintext != "0123456789" {
+		t.Fatalf("Decrypt with retired key = %q, %v", plaintext, err)
+	}
+
+	reencrypted, err := rotated.Encrypt(ctx, plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if rotated.NeedsRotation(reencrypted) {
+		t.Fatal("freshly encrypted value should not need rotation")
+	}
+	if _, err := old.Decrypt(ctx, reencrypted); err == nil {
+		t.Fatal("value under k2 should not decrypt without k2")
+	}
+
+	if legacy, err := rotated.Decrypt(ctx, "legacy plaintext"); err != nil || legacy != "legacy plaintext" {
+		t.Fatalf("legacy Decrypt = %q, %v", legacy, err)
+	}
+}
+
+func TestMask(t *testing.T) {
+	for in, want := range map[string]string{
+		"0123456789": "******6789",
+		"1234":       "****",
+		"":           "",
+	} {
+		if got := Mask(in); got != want {
+			t.Errorf("Mask(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// fakeKMS wraps data keys with a local key per KMS key id.
+type fakeKMS struct {
+	keys map[string][]byte
+}
+
+func (k fakeKMS) Encrypt(_ context.Context, keyID string, plaintext []byte) ([]byte, error) {
+	return seal(k.keys[keyID], plaintext)
+}
+
+func (k fakeKMS) Decrypt(_ context.Context, keyID string, ciphertext []byte) ([]byte, error) {
+	return open(k.keys[keyID], ciphertext)
+}
+
+func TestEnvelopeWithARNKeyID(t *testing.T) {
+	ctx := context.Background()
+	const arn = "arn:aws:kms:eu-west-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	const alias = "arn:aws:kms:eu-west-1:111122223333:alias/tabmate-bank"
+	client := fakeKMS{keys: map[string][]byte{
+		arn:   []byte(strings.Repeat("a", 32)),
+		alias: []byte(strings.Repeat("b", 32)),
+	}}
+	envelope := NewEnvelope(NewKMSKeyProvider(client, arn))
+
+	encrypted, err := envelope.Encrypt(ctx, "0123456789")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if plaintext, err := envelope.Decrypt(ctx, encrypted); err != nil || plaintext != "0123456789" {
+		t.Fatalf("Decrypt = %q, %v", plaintext, err)
+	}
+	if envelope.NeedsRotation(encrypted) {
+		t.Fatal("value under the current ARN should not need rotation")
+	}
+	if !NewEnvelope(NewKMSKeyProvider(client, alias)).NeedsRotation(encrypted) {
+		t.Fatal("value under another ARN should need rotation")
+	}
+
+	for _, malformed := range []string{"enc:v1:", "enc:v1:abc", "enc:v1::abc:def", "enc:v1:" + arn + ":!!:!!"} {
+		if _, err := envelope.Decrypt(ctx, malformed); err != ErrMalformedCiphertext {
+			t.Errorf("Decrypt(%q) error = %v, want ErrMalformedCiphertext", malformed, err)
+		}
+	}
+}
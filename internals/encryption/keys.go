@@ -0,0 +1,117 @@
+package encryption
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeyProvider wraps and unwraps per-value data keys with a key-encryption key (KEK).
+// The KEK never leaves the provider, so a cloud KMS can implement this directly.
+type KeyProvider interface {
+	// CurrentKeyID names the KEK new data keys are wrapped with.
+	CurrentKeyID() string
+	WrapKey(ctx context.Context, keyID string, dataKey []byte) ([]byte, error)
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// LocalKeyProvider holds AES-256 KEKs in memory, keyed by id. The first key is current;
+// older keys are kept only so existing values can still be decrypted until rotated.
+type LocalKeyProvider struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewLocalKeyProvider parses a "id:base64key,id:base64key" list. Each key must be 32 bytes.
+func NewLocalKeyProvider(spec string) (*LocalKeyProvider, error) {
+	provider := &LocalKeyProvider{keys: make(map[string][]byte)}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("key entry must be id:base64key")
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("key %q must be 32 bytes, got %d", id, len(key))
+		}
+		if _, dup := provider.keys[id]; dup {
+			return nil, fmt.Errorf("duplicate key id %q", id)
+		}
+		if provider.current == "" {
+			provider.current = id
+		}
+		provider.keys[id] = key
+	}
+	if provider.current == "" {
+		return nil, fmt.Errorf("no keys configured")
+	}
+	return provider, nil
+}
+
+func (p *LocalKeyProvider) CurrentKeyID() string {
+	return p.current
+}
+
+func (p *LocalKeyProvider) WrapKey(_ context.Context, keyID string, dataKey []byte) ([]byte, error) {
+	kek, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", keyID)
+	}
+	return seal(kek, dataKey)
+}
+
+func (p *LocalKeyProvider) UnwrapKey(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	kek, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", keyID)
+	}
+	return open(kek, wrapped)
+}
+
+// KMSClient is the encrypt/decrypt surface shared by AWS KMS, GCP Cloud KMS and Vault
+// transit. Production deployments adapt their SDK client to it.
+type KMSClient interface {
+	Encrypt(ctx context.Context, keyID string, plaintext []byte) ([]byte, error)
+	Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// KMSKeyProvider wraps data keys with a key held in an external KMS.
+type KMSKeyProvider struct {
+	client KMSClient
+	keyID  string
+}
+
+func NewKMSKeyProvider(client KMSClient, keyID string) *KMSKeyProvider {
+	return &KMSKeyProvider{client: client, keyID: keyID}
+}
+
+func (p *KMSKeyProvider) CurrentKeyID() string {
+	return p.keyID
+}
+
+func (p *KMSKeyProvider) WrapKey(ctx context.Context, keyID string, dataKey []byte) ([]byte, error) {
+	return p.client.Encrypt(ctx, keyID, dataKey)
+}
+
+func (p *KMSKeyProvider) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	return p.client.Decrypt(ctx, keyID, wrapped)
+}
+
+// NewKeyProviderFromEnv builds the local provider from BANK_ENCRYPTION_KEYS.
+// List the newest key first; keep retired keys until `cmd/rotatekeys` has run.
+func NewKeyProviderFromEnv() (KeyProvider, error) {
+	spec := os.Getenv("BANK_ENCRYPTION_KEYS")
+	if spec == "" {
+		return nil, fmt.Errorf("BANK_ENCRYPTION_KEYS is not set")
+	}
+	return NewLocalKeyProvider(spec)
+}
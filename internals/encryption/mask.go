@@ -0,0 +1,12 @@
+package encryption
+
+import "strings"
+
+// Mask hides all but the last four characters of a sensitive value, e.g. "******7890".
+func Mask(value string) string {
+	runes := []rune(value)
+	if len(runes) <= 4 {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+}
@@ -0,0 +1,108 @@
+// Package hosts hands the host role of a split or table from one member to another.
+package hosts
+
+import (
+	"context"
+
+	"tabmate/internals/roles"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// HandOverSplit makes newHostID the host of the split and the one who is owed. The new
+// host's own share counts as paid, since nobody owes themselves. If the old host is
+// staying they become a co-host with every permission who owes the new host their
+// share; otherwise their membership is left as it is for the caller to remove. Run it
+// inside a transaction.
+func HandOverSplit(ctx context.Context, queries tabmate.Querier, splitID, oldHostID, newHostID pgtype.UUID, oldHostStays bool) error {
+	if _, err := queries.TransferSplitHost(ctx, tabmate.TransferSplitHostParams{
+		ID:        splitID,
+		CreatedBy: newHostID,
+	}); err != nil {
+		return err
+	}
+	if _, err := queries.UpdateSplitMemberRole(ctx, tabmate.UpdateSplitMemberRoleParams{
+		Role:        roles.Host,
+		Permissions: []string{},
+		SplitID:     splitID,
+		UserID:      newHostID,
+	}); err != nil {
+		return err
+	}
+	if _, err := queries.ConfirmSplitMemberPayment(ctx, tabmate.ConfirmSplitMemberPaymentParams{
+		SplitID: splitID,
+		UserID:  newHostID,
+	}); err != nil {
+		return err
+	}
+	if !oldHostStays {
+		return nil
+	}
+	all, _ := roles.ParsePermissions(nil)
+	old, err := queries.UpdateSplitMemberRole(ctx, tabmate.UpdateSplitMemberRoleParams{
+		Role:        roles.CoHost,
+		Permissions: all,
+		SplitID:     splitID,
+		UserID:      oldHostID,
+	})
+	if err != nil {
+		return err
+	}
+	return oweNewHost(ctx, queries, old)
+}
+
+// oweNewHost turns a former host's membership into an ordinary debt to the new host, or
+// settles it if their share is nothing.
+func oweNewHost(ctx context.Context, queries tabmate.Querier, member tabmate.SplitMembers) error {
+	owed, _ := member.AmountOwed.Float64Value()
+	if owed.Float64 <= 0 {
+		_, err := queries.ConfirmSplitMemberPayment(ctx, tabmate.ConfirmSplitMemberPaymentParams{
+			SplitID: member.SplitID,
+			UserID:  member.UserID,
+		})
+		return err
+	}
+	if _, err := queries.UpdateSplitMemberPaymentStatus(ctx, tabmate.UpdateSplitMemberPaymentStatusParams{
+		SplitID:       member.SplitID,
+		UserID:        member.UserID,
+		PaymentStatus: "unpaid",
+	}); err != nil {
+		return err
+	}
+	_, err := queries.UpdateSplitMemberSettledStatus(ctx, tabmate.UpdateSplitMemberSettledStatusParams{
+		SplitID:   member.SplitID,
+		UserID:    member.UserID,
+		IsSettled: false,
+	})
+	return err
+}
+
+// HandOverTable makes newHostID the host of the table, like HandOverSplit.
+func HandOverTable(ctx context.Context, queries tabmate.Querier, tableID, oldHostID, newHostID pgtype.UUID, oldHostStays bool) error {
+	if _, err := queries.TransferTableHost(ctx, tabmate.TransferTableHostParams{
+		ID:        tableID,
+		CreatedBy: newHostID,
+	}); err != nil {
+		return err
+	}
+	if _, err := queries.UpdateMemberRoleInTable(ctx, tabmate.UpdateMemberRoleInTableParams{
+		TableID:     tableID,
+		UserID:      newHostID,
+		Role:        roles.Host,
+		Permissions: []string{},
+	}); err != nil {
+		return err
+	}
+	if !oldHostStays {
+		return nil
+	}
+	all, _ := roles.ParsePermissions(nil)
+	_, err := queries.UpdateMemberRoleInTable(ctx, tabmate.UpdateMemberRoleInTableParams{
+		TableID:     tableID,
+		UserID:      oldHostID,
+		Role:        roles.CoHost,
+		Permissions: all,
+	})
+	return err
+}
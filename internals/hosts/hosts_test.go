@@ -0,0 +1,203 @@
+package hosts
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"tabmate/internals/roles"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	entityID  = pgtype.UUID{Bytes: [16]byte{1}, Valid: true}
+	oldHostID = pgtype.UUID{Bytes: [16]byte{2}, Valid: true}
+	newHostID = pgtype.UUID{Bytes: [16]byte{3}, Valid: true}
+)
+
+// hostQueries records the host and role changes a hand-over makes, and keeps the
+// split's member rows for the payment queries. Methods it does not call are left to
+// the embedded nil interface.
+type hostQueries struct {
+	tabmate.Querier
+	failTransfer bool
+	host         pgtype.UUID
+	roles        map[pgtype.UUID]string
+	members      map[pgtype.UUID]*tabmate.SplitMembers
+}
+
+// member returns the split member row for user, adding one with nothing owed if there
+// isn't one yet.
+func (q *hostQueries) member(user pgtype.UUID) *tabmate.SplitMembers {
+	if q.members == nil {
+		q.members = map[pgtype.UUID]*tabmate.SplitMembers{}
+	}
+	if q.members[user] == nil {
+		q.members[user] = &tabmate.SplitMembers{SplitID: entityID, UserID: user, PaymentStatus: "unpaid"}
+	}
+	return q.members[user]
+}
+
+func (q *hostQueries) transfer(id, host pgtype.UUID) error {
+	if id != entityID {
+		return errors.New("wrong id")
+	}
+	if q.failTransfer {
+		return errors.New("connection reset")
+	}
+	q.host = host
+	return nil
+}
+
+func (q *hostQueries) setRole(id, user pgtype.UUID, role string, permissions []string) error {
+	if id != entityID {
+		return errors.New("wrong id")
+	}
+	if role == roles.CoHost && len(permissions) != len(roles.All) {
+		return errors.New("co-host not given every permission")
+	}
+	if q.roles == nil {
+		q.roles = map[pgtype.UUID]string{}
+	}
+	q.roles[user] = role
+	return nil
+}
+
+func (q *hostQueries) TransferSplitHost(_ context.Context, arg tabmate.TransferSplitHostParams) (tabmate.Splits, error) {
+	return tabmate.Splits{}, q.transfer(arg.ID, arg.CreatedBy)
+}
+
+func (q *hostQueries) UpdateSplitMemberRole(_ context.Context, arg tabmate.UpdateSplitMemberRoleParams) (tabmate.SplitMembers, error) {
+	if err := q.setRole(arg.SplitID, arg.UserID, arg.Role, arg.Permissions); err != nil {
+		return tabmate.SplitMembers{}, err
+	}
+	m := q.member(arg.UserID)
+	m.Role = arg.Role
+	return *m, nil
+}
+
+func (q *hostQueries) ConfirmSplitMemberPayment(_ context.Context, arg tabmate.ConfirmSplitMemberPaymentParams) (tabmate.SplitMembers, error) {
+	m := q.member(arg.UserID)
+	m.PaymentStatus, m.IsSettled = "confirmed", true
+	return *m, nil
+}
+
+func (q *hostQueries) UpdateSplitMemberPaymentStatus(_ context.Context, arg tabmate.UpdateSplitMemberPaymentStatusParams) (tabmate.SplitMembers, error) {
+	m := q.member(arg.UserID)
+	m.PaymentStatus = arg.PaymentStatus
+	return *m, nil
+}
+
+func (q *hostQueries) UpdateSplitMemberSettledStatus(_ context.Context, arg tabmate.UpdateSplitMemberSettledStatusParams) (tabmate.SplitMembers, error) {
+	m := q.member(arg.UserID)
+	m.IsSettled = arg.IsSettled
+	return *m, nil
+}
+
+func (q *hostQueries) CountUnsettledSplitMembers(_ context.Context, _ pgtype.UUID) (int64, error) {
+	var n int64
+	for _, m := range q.members {
+		if !m.IsSettled {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (q *hostQueries) TransferTableHost(_ context.Context, arg tabmate.TransferTableHostParams) (tabmate.Tables, error) {
+	return tabmate.Tables{}, q.transfer(arg.ID, arg.CreatedBy)
+}
+
+func (q *hostQueries) UpdateMemberRoleInTable(_ context.Context, arg tabmate.UpdateMemberRoleInTableParams) (tabmate.TableMembers, error) {
+	return tabmate.TableMembers{}, q.setRole(arg.TableID, arg.UserID, arg.Role, arg.Permissions)
+}
+
+type handOver func(context.Context, tabmate.Querier, pgtype.UUID, pgtype.UUID, pgtype.UUID, bool) error
+
+var handOvers = map[string]handOver{"split": HandOverSplit, "table": HandOverTable}
+
+func TestHandOverKeepsOldHostAsCoHost(t *testing.T) {
+	for name, handOver := range handOvers {
+		q := &hostQueries{}
+		if err := handOver(context.Background(), q, entityID, oldHostID, newHostID, true); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if q.host != newHostID || q.roles[newHostID] != roles.Host {
+			t.Errorf("%s: host is %v with role %q", name, q.host, q.roles[newHostID])
+		}
+		if q.roles[oldHostID] != roles.CoHost {
+			t.Errorf("%s: old host has role %q, want co-host", name, q.roles[oldHostID])
+		}
+	}
+}
+
+func TestHandOverLeavesDepartingHostAlone(t *testing.T) {
+	for name, handOver := range handOvers {
+		q := &hostQueries{}
+		if err := handOver(context.Background(), q, entityID, oldHostID, newHostID, false); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if q.host != newHostID || q.roles[newHostID] != roles.Host {
+			t.Errorf("%s: host is %v with role %q", name, q.host, q.roles[newHostID])
+		}
+		if _, changed := q.roles[oldHostID]; changed {
+			t.Errorf("%s: departing host's role was changed", name)
+		}
+	}
+}
+
+func TestHandOverStopsWhenTransferFails(t *testing.T) {
+	for name, handOver := range handOvers {
+		q := &hostQueries{failTransfer: true}
+		if err := handOver(context.Background(), q, entityID, oldHostID, newHostID, true); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+		if len(q.roles) != 0 {
+			t.Errorf("%s: roles changed after a failed transfer: %v", name, q.roles)
+		}
+	}
+}
+
+func TestSplitSettlesOnceEveryoneHasPaidTheNewHost(t *testing.T) {
+	ctx := context.Background()
+	guest := pgtype.UUID{Bytes: [16]byte{4}, Valid: true}
+	q := &hostQueries{}
+	for _, id := range []pgtype.UUID{oldHostID, newHostID, guest} {
+		q.member(id).AmountOwed = pgtype.Numeric{Int: big.NewInt(1000), Exp: -2, Valid: true}
+	}
+	// The old host had marked themselves settled while nobody was owed their share.
+	q.member(oldHostID).IsSettled = true
+
+	if err := HandOverSplit(ctx, q, entityID, oldHostID, newHostID, true); err != nil {
+		t.Fatal(err)
+	}
+	if m := q.member(newHostID); m.PaymentStatus != "confirmed" || !m.IsSettled {
+		t.Errorf("new host still owes: %+v", *m)
+	}
+	if m := q.member(oldHostID); m.PaymentStatus != "unpaid" || m.IsSettled {
+		t.Errorf("old host does not owe the new host: %+v", *m)
+	}
+
+	for _, payer := range []pgtype.UUID{guest, oldHostID} {
+		if n, _ := q.CountUnsettledSplitMembers(ctx, entityID); n == 0 {
+			t.Fatalf("split settled before %v paid", payer)
+		}
+		q.ConfirmSplitMemberPayment(ctx, tabmate.ConfirmSplitMemberPaymentParams{SplitID: entityID, UserID: payer})
+	}
+	if n, _ := q.CountUnsettledSplitMembers(ctx, entityID); n != 0 {
+		t.Errorf("%d members still unsettled after everyone paid", n)
+	}
+}
+
+func TestHandOverSettlesAFormerHostWhoOwesNothing(t *testing.T) {
+	q := &hostQueries{}
+	if err := HandOverSplit(context.Background(), q, entityID, oldHostID, newHostID, true); err != nil {
+		t.Fatal(err)
+	}
+	if m := q.member(oldHostID); m.PaymentStatus != "confirmed" || !m.IsSettled {
+		t.Errorf("old host with nothing owed left unsettled: %+v", *m)
+	}
+}
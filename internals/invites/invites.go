@@ -0,0 +1,105 @@
+// Package invites signs and verifies the tokens in shareable invite links.
+package invites
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultTTL = 7 * 24 * time.Hour
+	MaxTTL     = 30 * 24 * time.Hour
+
+	minKeyLength = 32
+	macLength    = 16
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid invite token")
+	ErrExpired      = errors.New("invite link has expired")
+)
+
+// Signer issues invite tokens of the form
+//
+//	base64url(invite id || expiry as unix seconds) "." base64url(truncated HMAC-SHA256)
+//
+// The invite id refers to a row in invite_links, which is still checked for
+// revocation; the signature only stops ids and expiry dates from being guessed or
+// edited.
+type Signer struct {
+	key     []byte
+	baseURL string
+}
+
+// NewSigner returns a signer for key, which must be at least 32 bytes. Links are built
+// on baseURL, or the app's tabmate:// scheme if it is empty.
+func NewSigner(key []byte, baseURL string) (*Signer, error) {
+	if len(key) < minKeyLength {
+		return nil, fmt.Errorf("invite signing key must be at least %d bytes", minKeyLength)
+	}
+	return &Signer{key: key, baseURL: strings.TrimRight(baseURL, "/")}, nil
+}
+
+// NewSignerFromEnv reads INVITE_SIGNING_KEY and APP_URL. It returns nil, and no error,
+// if INVITE_SIGNING_KEY is not set, in which case invite links are disabled.
+func NewSignerFromEnv() (*Signer, error) {
+	key := os.Getenv("INVITE_SIGNING_KEY")
+	if key == "" {
+		return nil, nil
+	}
+	return NewSigner([]byte(key), os.Getenv("APP_URL"))
+}
+
+// Sign returns a token for the invite that stops working at expiresAt.
+func (s *Signer) Sign(id uuid.UUID, expiresAt time.Time) string {
+	payload := make([]byte, 0, len(id)+8)
+	payload = append(payload, id[:]...)
+	payload = binary.BigEndian.AppendUint64(payload, uint64(expiresAt.Unix()))
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(s.mac(payload))
+}
+
+// Verify checks a token's signature and expiry and returns the invite id.
+func (s *Signer) Verify(token string, now time.Time) (uuid.UUID, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(payload) != 24 {
+		return uuid.Nil, ErrInvalidToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, s.mac(payload)) {
+		return uuid.Nil, ErrInvalidToken
+	}
+
+	id, _ := uuid.FromBytes(payload[:16])
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload[16:])), 0)
+	if !now.Before(expiresAt) {
+		return id, ErrExpired
+	}
+	return id, nil
+}
+
+// URL returns the shareable link for token.
+func (s *Signer) URL(token string) string {
+	if s.baseURL == "" {
+		return "tabmate://invite/" + token
+	}
+	return s.baseURL + "/invite/" + token
+}
+
+func (s *Signer) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write(payload)
+	return h.Sum(nil)[:macLength]
+}
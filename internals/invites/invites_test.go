@@ -0,0 +1,58 @@
+package invites
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	signer, err := NewSigner([]byte(strings.Repeat("k", 32)), "https://tabmate.test/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := NewSigner([]byte(strings.Repeat("x", 32)), "")
+
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	id := uuid.New()
+	token := signer.Sign(id, now.Add(time.Hour))
+
+	got, err := signer.Verify(token, now)
+	if err != nil || got != id {
+		t.Fatalf("Verify = %v, %v; want %v", got, err, id)
+	}
+	if _, err := signer.Verify(token, now.Add(time.Hour)); !errors.Is(err, ErrExpired) {
+		t.Errorf("at expiry: err = %v, want ErrExpired", err)
+	}
+	if _, err := other.Verify(token, now); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("other key: err = %v, want ErrInvalidToken", err)
+	}
+
+	// Pushing the expiry out invalidates the signature.
+	later := signer.Sign(id, now.Add(48*time.Hour))
+	forged := later[:strings.Index(later, ".")] + token[strings.Index(token, "."):]
+	if _, err := signer.Verify(forged, now); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("forged expiry: err = %v, want ErrInvalidToken", err)
+	}
+	for _, bad := range []string{"", "abc", "abc.def", token + "x"} {
+		if _, err := signer.Verify(bad, now); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify(%q) err = %v, want ErrInvalidToken", bad, err)
+		}
+	}
+
+	if url := signer.URL(token); url != "https://tabmate.test/invite/"+token {
+		t.Errorf("URL = %q", url)
+	}
+	if url := other.URL("t"); url != "tabmate://invite/t" {
+		t.Errorf("URL without base = %q", url)
+	}
+}
+
+func TestNewSignerRejectsShortKeys(t *testing.T) {
+	if _, err := NewSigner([]byte("short"), ""); err == nil {
+		t.Fatal("expected an error for a short key")
+	}
+}
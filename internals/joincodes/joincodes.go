@@ -0,0 +1,107 @@
+// Package joincodes generates the short codes people type or share to find a split or
+// table, and rotates the revocable join codes hosts hand out.
+package joincodes
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Length is the number of characters in a generated code. With 32 symbols that is 40
+// bits, so guessing a live code takes far more attempts than the join endpoints allow.
+const Length = 8
+
+// alphabet is Crockford's base32 in lower case: no i, l, o or u, so codes survive
+// being read aloud or copied by hand. Older hex codes are a subset of it.
+const alphabet = "0123456789abcdefghjkmnpqrstvwxyz"
+
+// MaxTTL is the longest a host can make a join code last. Codes without an expiry
+// last until they are rotated or revoked.
+const MaxTTL = 30 * 24 * time.Hour
+
+const maxAttempts = 5
+
+var (
+	ErrExhausted  = errors.New("could not find an unused join code")
+	ErrInvalidTTL = fmt.Errorf("join codes can last at most %d hours", int(MaxTTL.Hours()))
+)
+
+// Generate returns a random code.
+func Generate() (string, error) {
+	buf := make([]byte, Length)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = alphabet[b%byte(len(alphabet))]
+	}
+	return string(buf), nil
+}
+
+// Unique generates codes until inUse reports one as free, giving up after a few
+// collisions.
+func Unique(ctx context.Context, inUse func(context.Context, string) (bool, error)) (string, error) {
+	for range maxAttempts {
+		code, err := Generate()
+		if err != nil {
+			return "", err
+		}
+		taken, err := inUse(ctx, code)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return code, nil
+		}
+	}
+	return "", ErrExhausted
+}
+
+// Normalize turns a code as someone typed it into its canonical form: lower case,
+// without spaces or dashes, and with the look-alike letters o, i and l read as 0 and 1.
+func Normalize(code string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(code)) {
+		switch r {
+		case ' ', '-':
+			continue
+		case 'o':
+			r = '0'
+		case 'i', 'l':
+			r = '1'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Rotate revokes the split's or table's live join codes and issues a new one that
+// expires after ttl, or never if ttl is zero. Run it inside a transaction.
+func Rotate(ctx context.Context, queries tabmate.Querier, params tabmate.CreateJoinCodeParams, ttl time.Duration) (tabmate.JoinCodes, error) {
+	if ttl < 0 || ttl > MaxTTL {
+		return tabmate.JoinCodes{}, ErrInvalidTTL
+	}
+	if _, err := queries.RevokeJoinCodes(ctx, tabmate.RevokeJoinCodesParams{
+		SplitID: params.SplitID,
+		TableID: params.TableID,
+	}); err != nil {
+		return tabmate.JoinCodes{}, err
+	}
+	code, err := Unique(ctx, queries.IsCodeInUse)
+	if err != nil {
+		return tabmate.JoinCodes{}, err
+	}
+	params.Code = code
+	if ttl > 0 {
+		params.ExpiresAt = pgtype.Timestamptz{Time: time.Now().Add(ttl), Valid: true}
+	}
+	return queries.CreateJoinCode(ctx, params)
+}
@@ -0,0 +1,60 @@
+package joincodes
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	seen := map[string]bool{}
+	for range 1000 {
+		code, err := Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(code) != Length {
+			t.Fatalf("len(%q) = %d", code, len(code))
+		}
+		if strings.Trim(code, alphabet) != "" {
+			t.Fatalf("%q has characters outside the alphabet", code)
+		}
+		if Normalize(code) != code {
+			t.Fatalf("%q is not in canonical form", code)
+		}
+		seen[code] = true
+	}
+	if len(seen) < 1000 {
+		t.Fatalf("only %d distinct codes in 1000", len(seen))
+	}
+}
+
+func TestUniqueRetriesCollisions(t *testing.T) {
+	calls := 0
+	code, err := Unique(context.Background(), func(context.Context, string) (bool, error) {
+		calls++
+		return calls < 3, nil
+	})
+	if err != nil || code == "" || calls != 3 {
+		t.Fatalf("Unique = %q, %v after %d calls", code, err, calls)
+	}
+
+	_, err = Unique(context.Background(), func(context.Context, string) (bool, error) { return true, nil })
+	if !errors.Is(err, ErrExhausted) {
+		t.Fatalf("err = %v, want ErrExhausted", err)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for in, want := range map[string]string{
+		"ABCD-EFGH":  "abcdefgh",
+		" 7k3m 9pq2": "7k3m9pq2",
+		"OIL0":       "0110",
+		"3f2a9c1e":   "3f2a9c1e", // old hex codes are unchanged
+	} {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -2,19 +2,172 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
+	"tabmate/internals/apitokens"
 	"tabmate/internals/auth"
 	tabmate "tabmate/internals/store/postgres"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-// AuthMiddleware verifies a Clerk session token and resolves/auto-creates the DB user.
-func AuthMiddleware(queries tabmate.Querier) gin.HandlerFunc {
+const (
+	identityCacheSize = 10_000
+	identityCacheTTL  = 5 * time.Minute
+
+	// A personal access token's last_used_at is written at most this often.
+	tokenUseInterval = time.Minute
+)
+
+var errUserNotCreated = errors.New("failed to create user")
+
+// Identities turns bearer tokens into users rows. Rows are cached by subject, so a
+// signed-in user costs no query until the entry expires or their profile changes.
+type Identities struct {
+	queries       tabmate.Querier
+	authenticator auth.Authenticator
+	users         *auth.Cache[cachedUser]
+	tokenUses     *auth.Cache[struct{}]
+}
+
+// cachedUser is a users row and the identity it was last reconciled with.
+type cachedUser struct {
+	user     tabmate.Users
+	identity auth.Identity
+}
+
+// NewIdentities returns an Identities that verifies tokens with authenticator.
+func NewIdentities(queries tabmate.Querier, authenticator auth.Authenticator) *Identities {
+	return &Identities{
+		queries:       queries,
+		authenticator: authenticator,
+		users:         auth.NewCache[cachedUser](identityCacheSize, identityCacheTTL),
+		tokenUses:     auth.NewCache[struct{}](identityCacheSize, tokenUseInterval),
+	}
+}
+
+// Resolve verifies token and returns the caller's users row, creating it on their first
+// request. A name or email that differs from what was last seen is written back.
+func (ids *Identities) Resolve(ctx context.Context, token string) (tabmate.Users, auth.Identity, error) {
+	identity, err := ids.authenticator.Authenticate(ctx, token)
+	if err != nil {
+		return tabmate.Users{}, identity, err
+	}
+
+	cached, ok := ids.users.Get(identity.Subject)
+	if ok && cached.identity == identity {
+		return cached.user, identity, nil
+	}
+
+	var previous *auth.Identity
+	user := cached.user
+	if ok {
+		previous = &cached.identity
+	} else {
+		user, err = ids.queries.GetUserByCognitoSub(ctx, identity.Subject)
+		if err != nil {
+			// Auto-create on first authenticated request.
+			user, err = ids.queries.CreateUser(ctx, tabmate.CreateUserParams{
+				Name:       pgtype.Text{String: identity.Name, Valid: identity.Name != ""},
+				CognitoSub: identity.Subject,
+				Email:      identity.Email,
+			})
+			if err != nil {
+				log.Printf("[AuthMiddleware] CreateUser error for sub=%q email=%q: %v", identity.Subject, identity.Email, err)
+				return tabmate.Users{}, identity, errUserNotCreated
+			}
+			log.Printf("[AuthMiddleware] auto-created user id=%v for sub=%q", user.ID, identity.Subject)
+		}
+	}
+
+	user = ids.syncProfile(ctx, user, previous, identity)
+	ids.users.Set(identity.Subject, cachedUser{user: user, identity: identity})
+	return user, identity, nil
+}
+
+// ResolveAccessToken looks up a personal access token and returns its owner. Tokens
+// are read on every request, so a revoked one stops working straight away.
+func (ids *Identities) ResolveAccessToken(ctx context.Context, token string) (tabmate.Users, tabmate.PersonalAccessTokens, error) {
+	row, err := ids.queries.GetActivePersonalAccessTokenByHash(ctx, apitokens.Hash(token))
+	if err != nil {
+		return tabmate.Users{}, row, err
+	}
+	user, err := ids.queries.GetUserByID(ctx, row.UserID)
+	if err != nil {
+		return tabmate.Users{}, row, err
+	}
+
+	key := uuid.UUID(row.ID.Bytes).String()
+	if _, seen := ids.tokenUses.Get(key); !seen {
+		if err := ids.queries.TouchPersonalAccessToken(ctx, row.ID); err != nil {
+			log.Printf("[AuthMiddleware] failed to record use of token %s: %v", key, err)
+		} else {
+			ids.tokenUses.Set(key, struct{}{})
+		}
+	}
+	return user, row, nil
+}
+
+// Forget drops what is cached for subject, so the next request reads the users row
+// and the provider's profile again. Call it after changing or deleting a user.
+func (ids *Identities) Forget(subject string) {
+	ids.users.Delete(subject)
+	if forgetter, ok := ids.authenticator.(interface{ Forget(string) }); ok {
+		forgetter.Forget(subject)
+	}
+}
+
+// Remove forgets subject and, if the provider keeps accounts, deletes theirs, so a
+// deleted TabMate account can't sign straight back in.
+func (ids *Identities) Remove(ctx context.Context, subject string) error {
+	ids.Forget(subject)
+	if deleter, ok := ids.authenticator.(auth.UserDeleter); ok {
+		return deleter.DeleteUser(ctx, subject)
+	}
+	return nil
+}
+
+// syncProfile copies provider-side profile changes onto user. The email always follows
+// the provider. The name only does when it changed at the provider since it was last
+// seen, or when the user has none, so a name set in the app isn't overwritten.
+func (ids *Identities) syncProfile(ctx context.Context, user tabmate.Users, previous *auth.Identity, identity auth.Identity) tabmate.Users {
+	if identity.Email != "" && identity.Email != user.Email {
+		updated, err := ids.queries.UpdateUserEmail(ctx, tabmate.UpdateUserEmailParams{
+			ID:    user.ID,
+			Email: identity.Email,
+		})
+		if err != nil {
+			log.Printf("[AuthMiddleware] failed to update email for sub=%q: %v", identity.Subject, err)
+		} else {
+			user = updated
+		}
+	}
+
+	nameChanged := previous != nil && previous.Name != identity.Name
+	if identity.Name != "" && identity.Name != user.Name.String && (nameChanged || user.Name.String == "") {
+		updated, err := ids.queries.UpdateUserName(ctx, tabmate.UpdateUserNameParams{
+			ID:   user.ID,
+			Name: pgtype.Text{String: identity.Name, Valid: true},
+		})
+		if err != nil {
+			log.Printf("[AuthMiddleware] failed to update name for sub=%q: %v", identity.Subject, err)
+		} else {
+			user = updated
+		}
+	}
+	return user
+}
+
+// AuthMiddleware verifies the bearer token and resolves/auto-creates the DB user.
+// Personal access tokens are accepted too, on the routes their scopes cover.
+func AuthMiddleware(ids *Identities) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -23,31 +176,22 @@ func AuthMiddleware(queries tabmate.Querier) gin.HandlerFunc {
 			return
 		}
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+		if apitokens.IsToken(tokenString) {
+			authenticateAccessToken(c, ids, tokenString)
+			return
+		}
 
-		userInfo, err := auth.VerifyClerkToken(tokenString)
-		if err != nil {
-			log.Printf("Invalid Clerk token: %v", err)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		user, userInfo, err := ids.Resolve(c, tokenString)
+		if errors.Is(err, errUserNotCreated) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 			c.Abort()
 			return
 		}
-
-		// Look up user by Clerk user ID (stored in cognito_sub column).
-		user, err := queries.GetUserByCognitoSub(c, userInfo.Sub)
 		if err != nil {
-			// Auto-create on first authenticated request.
-			user, err = queries.CreateUser(c, tabmate.CreateUserParams{
-				Name:       pgtype.Text{String: userInfo.Name, Valid: userInfo.Name != ""},
-				CognitoSub: userInfo.Sub,
-				Email:      userInfo.Email,
-			})
-			if err != nil {
-				log.Printf("[AuthMiddleware] CreateUser error for sub=%q email=%q: %v", userInfo.Sub, userInfo.Email, err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
-				c.Abort()
-				return
-			}
-			log.Printf("[AuthMiddleware] auto-created user id=%v for sub=%q", user.ID, userInfo.Sub)
+			log.Printf("Invalid token: %v", err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
 		}
 
 		c.Set("username", userInfo.Name)
@@ -57,27 +201,40 @@ func AuthMiddleware(queries tabmate.Querier) gin.HandlerFunc {
 	}
 }
 
-// VerifyOIDCToken is used by the WebSocket route to authenticate without middleware.
-func VerifyOIDCToken(queries tabmate.Querier, tokenString string) tabmate.Users {
-	userInfo, err := auth.VerifyClerkToken(tokenString)
+func authenticateAccessToken(c *gin.Context, ids *Identities, token string) {
+	user, row, err := ids.ResolveAccessToken(c, token)
 	if err != nil {
-		log.Printf("Invalid Clerk token (WS): %v", err)
-		return tabmate.Users{}
+		log.Printf("Invalid access token: %v", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid, expired or revoked access token"})
+		c.Abort()
+		return
 	}
 
-	ctx := context.Background()
-	user, err := queries.GetUserByCognitoSub(ctx, userInfo.Sub)
-	if err != nil {
-		user, err = queries.CreateUser(ctx, tabmate.CreateUserParams{
-			Name:       pgtype.Text{String: userInfo.Name, Valid: userInfo.Name != ""},
-			CognitoSub: userInfo.Sub,
-			Email:      userInfo.Email,
-		})
-		if err != nil {
-			log.Printf("Error auto-creating user (WS): %v", err)
-			return tabmate.Users{}
-		}
+	scope, ok := apitokens.Required(c.Request.Method, c.FullPath())
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Personal access tokens can't be used for this endpoint"})
+		c.Abort()
+		return
+	}
+	if !apitokens.Allows(row.Scopes, scope) {
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("This token needs the %s scope", scope)})
+		c.Abort()
+		return
 	}
 
+	c.Set("username", user.Name.String)
+	c.Set("email", user.Email)
+	c.Set("user_id", user.ID)
+	c.Set("token_scopes", row.Scopes)
+	c.Next()
+}
+
+// VerifyOIDCToken is used by the WebSocket route to authenticate without middleware.
+func VerifyOIDCToken(ids *Identities, tokenString string) tabmate.Users {
+	user, _, err := ids.Resolve(context.Background(), tokenString)
+	if err != nil {
+		log.Printf("Invalid token (WS): %v", err)
+		return tabmate.Users{}
+	}
 	return user
 }
@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tabmate/internals/apitokens"
+	"tabmate/internals/auth"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// tokenIdentities is an Authenticator whose tokens are keys into a map.
+type tokenIdentities map[string]auth.Identity
+
+func (t tokenIdentities) Authenticate(ctx context.Context, token string) (auth.Identity, error) {
+	id, ok := t[token]
+	if !ok {
+		return auth.Identity{}, auth.ErrInvalidToken
+	}
+	return id, nil
+}
+
+// userStore keeps one users row and counts the queries made against it.
+type userStore struct {
+	tabmate.Querier
+	user    tabmate.Users
+	lookups int
+	updates int
+}
+
+func (s *userStore) GetUserByCognitoSub(ctx context.Context, sub string) (tabmate.Users, error) {
+	s.lookups++
+	return s.user, nil
+}
+
+func (s *userStore) UpdateUserName(ctx context.Context, arg tabmate.UpdateUserNameParams) (tabmate.Users, error) {
+	s.updates++
+	s.user.Name = arg.Name
+	return s.user, nil
+}
+
+func (s *userStore) UpdateUserEmail(ctx context.Context, arg tabmate.UpdateUserEmailParams) (tabmate.Users, error) {
+	s.updates++
+	s.user.Email = arg.Email
+	return s.user, nil
+}
+
+func TestIdentitiesResolve(t *testing.T) {
+	store := &userStore{user: tabmate.Users{
+		ID:         pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		CognitoSub: "user_1",
+		Name:       pgtype.Text{String: "Sammy", Valid: true}, // renamed in the app
+		Email:      "sam@example.com",
+	}}
+	tokens := tokenIdentities{
+		"first":   {Subject: "user_1", Email: "sam@example.com", Name: "Sam"},
+		"renamed": {Subject: "user_1", Email: "sam@new.example.com", Name: "Samuel"},
+	}
+	ids := NewIdentities(store, tokens)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := ids.Resolve(ctx, "first"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if store.lookups != 1 {
+		t.Errorf("lookups = %d, want 1", store.lookups)
+	}
+	if store.updates != 0 || store.user.Name.String != "Sammy" {
+		t.Errorf("the name set in the app was overwritten: %q after %d updates", store.user.Name.String, store.updates)
+	}
+
+	user, _, err := ids.Resolve(ctx, "renamed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Name.String != "Samuel" || user.Email != "sam@new.example.com" {
+		t.Errorf("provider changes not applied: got %q <%s>", user.Name.String, user.Email)
+	}
+	if store.lookups != 1 {
+		t.Errorf("lookups = %d after a profile change, want 1", store.lookups)
+	}
+
+	ids.Forget("user_1")
+	if _, _, err := ids.Resolve(ctx, "renamed"); err != nil {
+		t.Fatal(err)
+	}
+	if store.lookups != 2 {
+		t.Errorf("lookups = %d after Forget, want 2", store.lookups)
+	}
+
+	if _, _, err := ids.Resolve(ctx, "forged"); err == nil {
+		t.Error("an unknown token was accepted")
+	}
+}
+
+// accessTokenStore holds one personal access token and counts last-used writes.
+type accessTokenStore struct {
+	tabmate.Querier
+	user    tabmate.Users
+	token   tabmate.PersonalAccessTokens
+	touches int
+}
+
+func (s *accessTokenStore) GetActivePersonalAccessTokenByHash(ctx context.Context, hash []byte) (tabmate.PersonalAccessTokens, error) {
+	if !bytes.Equal(hash, s.token.TokenHash) {
+		return tabmate.PersonalAccessTokens{}, pgx.ErrNoRows
+	}
+	return s.token, nil
+}
+
+func (s *accessTokenStore) GetUserByID(ctx context.Context, id pgtype.UUID) (tabmate.Users, error) {
+	return s.user, nil
+}
+
+func (s *accessTokenStore) TouchPersonalAccessToken(ctx context.Context, id pgtype.UUID) error {
+	s.touches++
+	return nil
+}
+
+func TestAuthMiddlewareAccessToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	token, err := apitokens.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &accessTokenStore{
+		user: tabmate.Users{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}},
+		token: tabmate.PersonalAccessTokens{
+			ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			TokenHash: apitokens.Hash(token),
+			Scopes:    []string{string(apitokens.ReadSplits)},
+		},
+	}
+
+	router := gin.New()
+	router.Use(AuthMiddleware(NewIdentities(store, tokenIdentities{})))
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	router.GET("/api/splits/:code", ok)
+	router.POST("/api/splits/:code/settle", ok)
+	router.GET("/api/me", ok)
+
+	for _, tc := range []struct {
+		method, path, token string
+		want                int
+	}{
+		{"GET", "/api/splits/abc", token, http.StatusNoContent},
+		{"GET", "/api/splits/abc", token, http.StatusNoContent},
+		{"POST", "/api/splits/abc/settle", token, http.StatusForbidden},
+		{"GET", "/api/me", token, http.StatusForbidden},
+		{"GET", "/api/splits/abc", apitokens.Prefix + "revoked", http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%s %s = %d, want %d", tc.method, tc.path, rec.Code, tc.want)
+		}
+	}
+	if store.touches != 1 {
+		t.Errorf("last_used_at written %d times, want 1", store.touches)
+	}
+}
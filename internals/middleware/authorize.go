@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"net/http"
+
+	"tabmate/internals/roles"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Context keys under which the authorization middleware leaves what it loaded.
+const (
+	SplitKey       = "split"        // tabmate.Splits
+	SplitMemberKey = "split_member" // tabmate.SplitMembers
+	TableKey       = "table"        // tabmate.Tables
+	TableMemberKey = "table_member" // tabmate.TableMembers
+)
+
+// AuthorizedSplit returns the split and the caller's membership loaded by
+// RequireSplitMember, RequireSplitHost or RequireSplitPermission. Handlers behind one
+// of them use it rather than loading both again.
+func AuthorizedSplit(c *gin.Context) (tabmate.Splits, tabmate.SplitMembers) {
+	return c.MustGet(SplitKey).(tabmate.Splits), c.MustGet(SplitMemberKey).(tabmate.SplitMembers)
+}
+
+// AuthorizedTable returns the table and the caller's membership loaded by one of the
+// table middlewares.
+func AuthorizedTable(c *gin.Context) (tabmate.Tables, tabmate.TableMembers) {
+	return c.MustGet(TableKey).(tabmate.Tables), c.MustGet(TableMemberKey).(tabmate.TableMembers)
+}
+
+// RequireSplitMember lets the request through only if the caller belongs to the
+// :code split.
+func RequireSplitMember(queries tabmate.Querier) gin.HandlerFunc {
+	return requireSplit(queries, func(tabmate.SplitMembers) bool { return true }, "")
+}
+
+// RequireSplitHost lets the request through only if the caller hosts the :code split.
+func RequireSplitHost(queries tabmate.Querier) gin.HandlerFunc {
+	return requireSplit(queries, func(m tabmate.SplitMembers) bool {
+		return m.Role == roles.Host
+	}, "Only the split host can do this")
+}
+
+// RequireSplitPermission lets the request through only if the caller hosts the :code
+// split, or co-hosts it with permission p.
+func RequireSplitPermission(queries tabmate.Querier, p roles.Permission) gin.HandlerFunc {
+	return requireSplit(queries, func(m tabmate.SplitMembers) bool {
+		return roles.Can(m.Role, m.Permissions, p)
+	}, "Only the split host, or a co-host allowed to, can do this")
+}
+
+// RequireTableMember lets the request through only if the caller belongs to the
+// :code table.
+func RequireTableMember(queries tabmate.Querier) gin.HandlerFunc {
+	return requireTable(queries, func(tabmate.TableMembers) bool { return true }, "")
+}
+
+// RequireTableHost lets the request through only if the caller hosts the :code table.
+func RequireTableHost(queries tabmate.Querier) gin.HandlerFunc {
+	return requireTable(queries, func(m tabmate.TableMembers) bool {
+		return m.Role == roles.Host
+	}, "Only the table host can do this")
+}
+
+// RequireTablePermission lets the request through only if the caller hosts the :code
+// table, or co-hosts it with permission p.
+func RequireTablePermission(queries tabmate.Querier, p roles.Permission) gin.HandlerFunc {
+	return requireTable(queries, func(m tabmate.TableMembers) bool {
+		return roles.Can(m.Role, m.Permissions, p)
+	}, "Only the table host, or a co-host allowed to, can do this")
+}
+
+// RequireTableItemMember lets the request through only if the caller belongs to the
+// table the :id item was ordered at.
+func RequireTableItemMember(queries tabmate.Querier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+		item, err := queries.GetItemByID(c, pgtype.UUID{Bytes: id, Valid: true})
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+			return
+		}
+		table, err := queries.GetTableByCode(c, item.TableCode)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+			return
+		}
+		authorizeTable(c, queries, table, "Item not found", func(tabmate.TableMembers) bool { return true }, "")
+	}
+}
+
+func requireSplit(queries tabmate.Querier, allowed func(tabmate.SplitMembers) bool, denied string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		split, err := queries.GetSplitByCode(c, c.Param("code"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Split not found"})
+			return
+		}
+		member, err := queries.GetSplitMember(c, tabmate.GetSplitMemberParams{
+			SplitID: split.ID,
+			UserID:  callerID(c),
+		})
+		if err != nil {
+			// Non-members get the same answer as for a code that doesn't exist, so codes
+			// can't be probed for without using up LimitFailedLookups.
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Split not found"})
+			return
+		}
+		if !allowed(member) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": denied})
+			return
+		}
+
+		c.Set(SplitKey, split)
+		c.Set(SplitMemberKey, member)
+		c.Next()
+	}
+}
+
+func requireTable(queries tabmate.Querier, allowed func(tabmate.TableMembers) bool, denied string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		table, err := queries.GetTableByCode(c, c.Param("code"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Table not found"})
+			return
+		}
+		authorizeTable(c, queries, table, "Table not found", allowed, denied)
+	}
+}
+
+// authorizeTable answers non-members with notFound, as if the table did not exist.
+func authorizeTable(c *gin.Context, queries tabmate.Querier, table tabmate.Tables, notFound string, allowed func(tabmate.TableMembers) bool, denied string) {
+	member, err := queries.GetTableMember(c, tabmate.GetTableMemberParams{
+		TableID: table.ID,
+		UserID:  callerID(c),
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": notFound})
+		return
+	}
+	if !allowed(member) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": denied})
+		return
+	}
+
+	c.Set(TableKey, table)
+	c.Set(TableMemberKey, member)
+	c.Next()
+}
+
+func callerID(c *gin.Context) pgtype.UUID {
+	userID, _ := c.Get("user_id")
+	id, _ := userID.(pgtype.UUID)
+	return id
+}
@@ -48,15 +48,47 @@ func RateLimitByUser(name string, limit int, window time.Duration, burst int) gi
 		Limit:  limit,
 		Window: window,
 		Burst:  burst,
-		Key: func(c *gin.Context) string {
-			if userID, ok := c.Get("user_id"); ok {
-				return fmt.Sprintf("user:%v", userID)
-			}
-			return "ip:" + c.ClientIP()
-		},
+		Key:    userOrIP,
 	})
 }
 
+// LimitFailedLookups slows down guessing of codes. Every response with status 404 uses
+// up one of limit attempts per window for the user (or IP, before sign-in), and once
+// they are gone all requests are refused until the allowance refills. Requests that
+// find what they looked for cost nothing. Handlers sharing one instance share the
+// allowance.
+func LimitFailedLookups(name string, limit int, window time.Duration) gin.HandlerFunc {
+	limiter := &rateLimiter{
+		buckets: make(map[string]*rateLimitBucket),
+		config:  RateLimitConfig{Name: name, Limit: limit, Window: window, Burst: limit},
+	}
+	go limiter.cleanup()
+
+	return func(c *gin.Context) {
+		key := name + ":" + userOrIP(c)
+		if ok, retryAfter := limiter.check(key, time.Now()); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "Too many failed attempts. Please try again later.",
+			})
+			return
+		}
+
+		c.Next()
+
+		if c.Writer.Status() == http.StatusNotFound {
+			limiter.allow(key, time.Now())
+		}
+	}
+}
+
+func userOrIP(c *gin.Context) string {
+	if userID, ok := c.Get("user_id"); ok {
+		return fmt.Sprintf("user:%v", userID)
+	}
+	return "ip:" + c.ClientIP()
+}
+
 func NewRateLimiter(config RateLimitConfig) gin.HandlerFunc {
 	if config.Name == "" {
 		config.Name = "default"
@@ -109,6 +141,28 @@ func (l *rateLimiter) allow(key string, now time.Time) (bool, int, time.Duration
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
+	bucket := l.refill(key, now)
+	if bucket.tokens < 1 {
+		return false, 0, l.untilNextToken(bucket)
+	}
+
+	bucket.tokens--
+	return true, int(math.Floor(bucket.tokens)), 0
+}
+
+// check reports whether key has a token left without using it.
+func (l *rateLimiter) check(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket := l.refill(key, now)
+	if bucket.tokens < 1 {
+		return false, l.untilNextToken(bucket)
+	}
+	return true, 0
+}
+
+func (l *rateLimiter) refill(key string, now time.Time) *rateLimitBucket {
 	bucket, ok := l.buckets[key]
 	if !ok {
 		bucket = &rateLimitBucket{
@@ -122,14 +176,12 @@ func (l *rateLimiter) allow(key string, now time.Time) (bool, int, time.Duration
 	refill := elapsed.Seconds() * float64(l.config.Limit) / l.config.Window.Seconds()
 	bucket.tokens = math.Min(float64(l.config.Burst), bucket.tokens+refill)
 	bucket.lastSeen = now
+	return bucket
+}
 
-	if bucket.tokens < 1 {
-		secondsUntilNextToken := (1 - bucket.tokens) * l.config.Window.Seconds() / float64(l.config.Limit)
-		return false, 0, time.Duration(secondsUntilNextToken * float64(time.Second))
-	}
-
-	bucket.tokens--
-	return true, int(math.Floor(bucket.tokens)), 0
+func (l *rateLimiter) untilNextToken(bucket *rateLimitBucket) time.Duration {
+	secondsUntilNextToken := (1 - bucket.tokens) * l.config.Window.Seconds() / float64(l.config.Limit)
+	return time.Duration(secondsUntilNextToken * float64(time.Second))
 }
 
 func (l *rateLimiter) cleanup() {
@@ -1,8 +1,12 @@
 package middleware
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/gin-gonic/gin"
 )
 
 func TestRateLimiterAllowsBurstThenRejects(t *testing.T) {
@@ -49,3 +53,38 @@ func TestRateLimiterRefillsOverTime(t *testing.T) {
 		t.Fatalf("refilled request = allowed %v, remaining %d, retryAfter %s", allowed, remaining, retryAfter)
 	}
 }
+
+func TestLimitFailedLookupsOnlyCountsMisses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/codes/:code", LimitFailedLookups("test-lookups", 2, time.Hour), func(c *gin.Context) {
+		if c.Param("code") == "known" {
+			c.Status(http.StatusOK)
+			return
+		}
+		c.Status(http.StatusNotFound)
+	})
+
+	get := func(code string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/codes/"+code, nil))
+		return w.Code
+	}
+
+	for i := 0; i < 5; i++ {
+		if status := get("known"); status != http.StatusOK {
+			t.Fatalf("hit %d = %d, want 200", i, status)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if status := get("guess"); status != http.StatusNotFound {
+			t.Fatalf("miss %d = %d, want 404", i, status)
+		}
+	}
+	if status := get("guess"); status != http.StatusTooManyRequests {
+		t.Fatalf("third miss = %d, want 429", status)
+	}
+	if status := get("known"); status != http.StatusTooManyRequests {
+		t.Fatalf("hit after lockout = %d, want 429", status)
+	}
+}
@@ -0,0 +1,191 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSMTPPort = "587"
+	smtpTimeout     = 30 * time.Second
+)
+
+// EmailNotifier sends notifications as multipart (HTML and plain text) email over
+// SMTP. It upgrades to TLS when the server offers STARTTLS and authenticates only when
+// a username is set, so it also works against a local catcher such as Mailpit.
+type EmailNotifier struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	// AppURL, when set, is used to link to the split from the email.
+	AppURL string
+}
+
+func NewEmailNotifier(host, port, from string) *EmailNotifier {
+	if port == "" {
+		port = defaultSMTPPort
+	}
+	return &EmailNotifier{Host: host, Port: port, From: from}
+}
+
+// NewEmailNotifierFromEnv configures email from SMTP_HOST, SMTP_PORT, SMTP_USERNAME,
+// SMTP_PASSWORD, SMTP_FROM and APP_URL. It returns nil if SMTP_HOST is not set, in
+// which case notifications go out by push only.
+func NewEmailNotifierFromEnv() *EmailNotifier {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return nil
+	}
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "Tabmate <no-reply@tabmate.app>"
+	}
+	e := NewEmailNotifier(host, os.Getenv("SMTP_PORT"), from)
+	e.Username = os.Getenv("SMTP_USERNAME")
+	e.Password = os.Getenv("SMTP_PASSWORD")
+	e.AppURL = strings.TrimRight(os.Getenv("APP_URL"), "/")
+	return e
+}
+
+func (e *EmailNotifier) Channel() Channel {
+	return ChannelEmail
+}
+
+// Notify renders msg with the template for its kind and sends it to the recipient's
+// email address. It returns ErrUnreachable if they have none.
+func (e *EmailNotifier) Notify(ctx context.Context, to Recipient, msg Message) error {
+	if to.Email == "" {
+		return ErrUnreachable
+	}
+	from, err := mail.ParseAddress(e.From)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP_FROM: %w", err)
+	}
+	body, err := e.compose(from, to, msg)
+	if err != nil {
+		return err
+	}
+	return e.send(ctx, from.Address, to.Email, body)
+}
+
+func (e *EmailNotifier) compose(from *mail.Address, to Recipient, msg Message) ([]byte, error) {
+	html, text, err := renderEmail(msg.Kind, emailData{
+		Name:  to.Name,
+		Title: msg.Title,
+		Body:  msg.Body,
+		Data:  msg.Data,
+		Link:  e.link(msg.Data),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	parts := multipart.NewWriter(&buf)
+	headers := [][2]string{
+		{"From", from.String()},
+		{"To", (&mail.Address{Name: to.Name, Address: to.Email}).String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Title)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + parts.Boundary()},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", h[0], h[1])
+	}
+	buf.WriteString("\r\n")
+
+	// Plain text first: clients show the last alternative they understand.
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (e *EmailNotifier) link(data map[string]string) string {
+	if e.AppURL == "" || data["splitCode"] == "" {
+		return ""
+	}
+	return e.AppURL + "/splits/" + data["splitCode"]
+}
+
+func (e *EmailNotifier) send(ctx context.Context, from, to string, body []byte) error {
+	dialer := net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(e.Host, e.Port))
+	if err != nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: e.Host}); err != nil {
+			return err
+		}
+	}
+	if e.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.Username, e.Password, e.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
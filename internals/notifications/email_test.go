@@ -0,0 +1,174 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// smtpCatcher is a minimal local SMTP server that accepts every message, like Mailpit
+// or MailHog would in development.
+type smtpCatcher struct {
+	mu       sync.Mutex
+	from     []string
+	rcpt     []string
+	messages []string
+}
+
+func newSMTPCatcher(t *testing.T) (*smtpCatcher, *EmailNotifier) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	c := &smtpCatcher{}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go c.serve(conn)
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	return c, NewEmailNotifier(host, port, "Tabmate <no-reply@tabmate.test>")
+}
+
+func (c *smtpCatcher) serve(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 catcher ready")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250 catcher")
+		case "MAIL":
+			c.mu.Lock()
+			c.from = append(c.from, line)
+			c.mu.Unlock()
+			tp.PrintfLine("250 ok")
+		case "RCPT":
+			c.mu.Lock()
+			c.rcpt = append(c.rcpt, line)
+			c.mu.Unlock()
+			tp.PrintfLine("250 ok")
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			data, err := io.ReadAll(tp.DotReader())
+			if err != nil {
+				return
+			}
+			c.mu.Lock()
+			c.messages = append(c.messages, string(data))
+			c.mu.Unlock()
+			tp.PrintfLine("250 queued")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("250 ok")
+		}
+	}
+}
+
+func TestEmailNotifierSendsMultipart(t *testing.T) {
+	catcher, notifier := newSMTPCatcher(t)
+	notifier.AppURL = "https://tabmate.test"
+
+	err := notifier.Notify(context.Background(), Recipient{Name: "Ada <3", Email: "ada@example.com"}, Message{
+		Kind:  KindPaymentReminder,
+		Title: "Payment reminder 💸",
+		Body:  `Tunde is reminding you to pay your share of "Dinner"`,
+		Data:  map[string]string{"splitCode": "ABC123", "amount": "42.50"},
+	})
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if len(catcher.messages) != 1 {
+		t.Fatalf("caught %d messages, want 1", len(catcher.messages))
+	}
+	if catcher.rcpt[0] != "RCPT TO:<ada@example.com>" {
+		t.Errorf("rcpt = %q", catcher.rcpt[0])
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(catcher.messages[0]))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "Payment reminder 💸" {
+		t.Errorf("subject = %q", subject)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("content type = %q, %v", mediaType, err)
+	}
+	bodies := map[string]string{}
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		b, _ := io.ReadAll(part)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		bodies[contentType] = string(b)
+	}
+
+	text, html := bodies["text/plain"], bodies["text/html"]
+	for _, want := range []string{"Hi Ada <3,", "Amount due: $42.50", "Pay now: https://tabmate.test/splits/ABC123"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text body missing %q:\n%s", want, text)
+		}
+	}
+	for _, want := range []string{"Hi Ada &lt;3,", "$42.50", `href="https://tabmate.test/splits/ABC123"`, "&#34;Dinner&#34;"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("html body missing %q:\n%s", want, html)
+		}
+	}
+}
+
+func TestEmailTemplatesRender(t *testing.T) {
+	data := emailData{Name: "Ada", Title: "t", Body: "b", Data: map[string]string{"splitName": "Dinner", "amount": "10.00", "total": "40.00", "members": "4"}}
+	for _, kind := range []string{KindAddedToSplit, KindPaymentReminder, KindPaymentConfirmed, KindSplitSettled, KindTableClosed} {
+		html, text, err := renderEmail(kind, data)
+		if err != nil {
+			t.Fatalf("%s: %v", kind, err)
+		}
+		if !strings.Contains(html, "Hi Ada,") || !strings.Contains(text, "Hi Ada,") {
+			t.Errorf("%s: missing greeting", kind)
+		}
+	}
+}
+
+func TestEmailNotifierWithoutAddress(t *testing.T) {
+	catcher, notifier := newSMTPCatcher(t)
+	err := notifier.Notify(context.Background(), Recipient{Name: "Ada"}, Message{Kind: KindAddedToSplit, Title: "hi"})
+	if !errors.Is(err, ErrUnreachable) {
+		t.Fatalf("err = %v, want ErrUnreachable", err)
+	}
+	if len(catcher.messages) != 0 {
+		t.Fatalf("sent %d messages", len(catcher.messages))
+	}
+}
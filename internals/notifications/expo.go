@@ -2,9 +2,34 @@ package notifications
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultExpoBaseURL is Expo's production push service.
+	DefaultExpoBaseURL = "https://exp.host"
+
+	// Expo accepts at most this many messages per send request and this many ids
+	// per receipts request.
+	expoSendChunkSize    = 100
+	expoReceiptChunkSize = 1000
+)
+
+// Error codes Expo reports in ticket and receipt details that the worker acts on.
+const (
+	ExpoDeviceNotRegistered = "DeviceNotRegistered"
+	ExpoMessageRateExceeded = "MessageRateExceeded"
+)
+
+const (
+	expoStatusOK    = "ok"
+	expoStatusError = "error"
 )
 
 type ExpoMessage struct {
@@ -14,17 +39,137 @@ type ExpoMessage struct {
 	Data  map[string]string `json:"data,omitempty"`
 }
 
-func SendExpoPushNotification(msg ExpoMessage) error {
-	payload, err := json.Marshal(msg)
+type PushDetails struct {
+	Error string `json:"error,omitempty"`
+}
+
+// PushTicket is Expo's immediate answer to one message. An "ok" ticket only means
+// Expo accepted the message; the matching PushReceipt says whether it reached the
+// device's push service.
+type PushTicket struct {
+	Status  string      `json:"status"`
+	ID      string      `json:"id,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Details PushDetails `json:"details,omitempty"`
+}
+
+type PushReceipt struct {
+	Status  string      `json:"status"`
+	Message string      `json:"message,omitempty"`
+	Details PushDetails `json:"details,omitempty"`
+}
+
+func (t PushTicket) OK() bool { return t.Status == expoStatusOK }
+
+// Retryable reports whether sending the message again later may succeed. Transport
+// failures and rate limiting are; a dead token or an oversized message are not.
+func (t PushTicket) Retryable() bool {
+	return !t.OK() && (t.Details.Error == "" || t.Details.Error == ExpoMessageRateExceeded)
+}
+
+// Reason describes why a ticket is not ok, for logging and the outbox's last_error.
+func (t PushTicket) Reason() string { return pushErrorReason(t.Message, t.Details) }
+
+func (r PushReceipt) Reason() string { return pushErrorReason(r.Message, r.Details) }
+
+func pushErrorReason(message string, details PushDetails) string {
+	if details.Error != "" {
+		return details.Error + ": " + message
+	}
+	return message
+}
+
+// ExpoClient talks to the Expo push API. BaseURL can point at a local fake server
+// in tests.
+type ExpoClient struct {
+	BaseURL     string
+	AccessToken string
+	HTTPClient  *http.Client
+}
+
+func NewExpoClient(baseURL, accessToken string) *ExpoClient {
+	if baseURL == "" {
+		baseURL = DefaultExpoBaseURL
+	}
+	return &ExpoClient{
+		BaseURL:     strings.TrimRight(baseURL, "/"),
+		AccessToken: accessToken,
+		HTTPClient:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// NewExpoClientFromEnv uses EXPO_BASE_URL (defaults to Expo's production service)
+// and the optional EXPO_ACCESS_TOKEN for projects with enhanced push security.
+func NewExpoClientFromEnv() *ExpoClient {
+	return NewExpoClient(os.Getenv("EXPO_BASE_URL"), os.Getenv("EXPO_ACCESS_TOKEN"))
+}
+
+// Send delivers messages in chunks of Expo's maximum size and returns one ticket per
+// message, in order. A chunk that could not be sent at all yields error tickets
+// without details, which are retryable.
+func (c *ExpoClient) Send(ctx context.Context, msgs []ExpoMessage) []PushTicket {
+	tickets := make([]PushTicket, 0, len(msgs))
+	for start := 0; start < len(msgs); start += expoSendChunkSize {
+		chunk := msgs[start:min(start+expoSendChunkSize, len(msgs))]
+		tickets = append(tickets, c.sendChunk(ctx, chunk)...)
+	}
+	return tickets
+}
+
+func (c *ExpoClient) sendChunk(ctx context.Context, chunk []ExpoMessage) []PushTicket {
+	var resp struct {
+		Data []PushTicket `json:"data"`
+	}
+	err := c.post(ctx, "/--/api/v2/push/send", chunk, &resp)
+	if err == nil && len(resp.Data) != len(chunk) {
+		err = fmt.Errorf("expected %d tickets, got %d", len(chunk), len(resp.Data))
+	}
+	if err != nil {
+		tickets := make([]PushTicket, len(chunk))
+		for i := range tickets {
+			tickets[i] = PushTicket{Status: expoStatusError, Message: "request to Expo failed: " + err.Error()}
+		}
+		return tickets
+	}
+	return resp.Data
+}
+
+// Receipts fetches the receipts for the given ticket ids. Ids whose receipt is not
+// ready yet are missing from the result.
+func (c *ExpoClient) Receipts(ctx context.Context, ids []string) (map[string]PushReceipt, error) {
+	receipts := make(map[string]PushReceipt, len(ids))
+	for start := 0; start < len(ids); start += expoReceiptChunkSize {
+		var resp struct {
+			Data map[string]PushReceipt `json:"data"`
+		}
+		body := map[string][]string{"ids": ids[start:min(start+expoReceiptChunkSize, len(ids))]}
+		if err := c.post(ctx, "/--/api/v2/push/getReceipts", body, &resp); err != nil {
+			return nil, err
+		}
+		for id, r := range resp.Data {
+			receipts[id] = r
+		}
+	}
+	return receipts, nil
+}
+
+func (c *ExpoClient) post(ctx context.Context, path string, body, out any) error {
+	payload, err := json.Marshal(body)
 	if err != nil {
 		return err
 	}
 
-	resp, err := http.Post(
-		"https://exp.host/--/api/v2/push/send",
-		"application/json",
-		bytes.NewBuffer(payload),
-	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -34,5 +179,5 @@ func SendExpoPushNotification(msg ExpoMessage) error {
 		return fmt.Errorf("expo push API returned status %d", resp.StatusCode)
 	}
 
-	return nil
+	return json.NewDecoder(resp.Body).Decode(out)
 }
@@ -0,0 +1,127 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+const (
+	deadToken = "ExponentPushToken[dead]"
+	busyToken = "ExponentPushToken[busy]"
+)
+
+// fakeExpo is a local stand-in for the Expo push API. Messages to deadToken are
+// rejected as unregistered and messages to busyToken as rate limited; everything else
+// gets an ok ticket.
+type fakeExpo struct {
+	mu         sync.Mutex
+	chunks     []int
+	authHeader string
+	receipts   map[string]PushReceipt
+	failWith   int
+}
+
+func newFakeExpo(t *testing.T) (*fakeExpo, *ExpoClient) {
+	f := &fakeExpo{receipts: map[string]PushReceipt{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, NewExpoClient(srv.URL+"/", "expo-access-token")
+}
+
+func (f *fakeExpo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.authHeader = r.Header.Get("Authorization")
+	if f.failWith != 0 {
+		w.WriteHeader(f.failWith)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/--/api/v2/push/send":
+		var msgs []ExpoMessage
+		if err := json.NewDecoder(r.Body).Decode(&msgs); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.chunks = append(f.chunks, len(msgs))
+
+		tickets := make([]PushTicket, len(msgs))
+		for i, m := range msgs {
+			switch m.To {
+			case deadToken:
+				tickets[i] = PushTicket{Status: "error", Message: "not registered", Details: PushDetails{Error: ExpoDeviceNotRegistered}}
+			case busyToken:
+				tickets[i] = PushTicket{Status: "error", Message: "slow down", Details: PushDetails{Error: ExpoMessageRateExceeded}}
+			default:
+				tickets[i] = PushTicket{Status: "ok", ID: fmt.Sprintf("ticket-%d-%d", len(f.chunks), i)}
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": tickets})
+
+	case "/--/api/v2/push/getReceipts":
+		var req struct {
+			IDs []string `json:"ids"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data := map[string]PushReceipt{}
+		for _, id := range req.IDs {
+			if receipt, ok := f.receipts[id]; ok {
+				data[id] = receipt
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestExpoClientSendsInChunks(t *testing.T) {
+	f, client := newFakeExpo(t)
+
+	msgs := make([]ExpoMessage, 250)
+	for i := range msgs {
+		msgs[i] = ExpoMessage{To: fmt.Sprintf("ExponentPushToken[%d]", i), Title: "hi"}
+	}
+	msgs[120].To = deadToken
+
+	tickets := client.Send(context.Background(), msgs)
+
+	if fmt.Sprint(f.chunks) != "[100 100 50]" {
+		t.Fatalf("chunks = %v, want [100 100 50]", f.chunks)
+	}
+	if f.authHeader != "Bearer expo-access-token" {
+		t.Errorf("Authorization = %q", f.authHeader)
+	}
+	if len(tickets) != len(msgs) {
+		t.Fatalf("got %d tickets for %d messages", len(tickets), len(msgs))
+	}
+	if !tickets[0].OK() || tickets[0].ID == "" {
+		t.Errorf("ticket 0 = %+v, want ok with id", tickets[0])
+	}
+	if tickets[120].OK() || tickets[120].Retryable() || tickets[120].Details.Error != ExpoDeviceNotRegistered {
+		t.Errorf("ticket 120 = %+v, want permanent DeviceNotRegistered", tickets[120])
+	}
+}
+
+func TestExpoClientTransportFailureIsRetryable(t *testing.T) {
+	f, client := newFakeExpo(t)
+	f.failWith = http.StatusBadGateway
+
+	tickets := client.Send(context.Background(), []ExpoMessage{{To: "ExponentPushToken[a]"}, {To: "ExponentPushToken[b]"}})
+	for i, ticket := range tickets {
+		if ticket.OK() || !ticket.Retryable() {
+			t.Errorf("ticket %d = %+v, want retryable error", i, ticket)
+		}
+	}
+}
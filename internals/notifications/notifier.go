@@ -0,0 +1,36 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrUnreachable is returned by a Notifier that has no address for the recipient on
+// its channel, e.g. a user who signed up without an email. The worker moves on to the
+// next channel.
+var ErrUnreachable = errors.New("recipient is not reachable on this channel")
+
+// Recipient is the user a notification is addressed to.
+type Recipient struct {
+	UserID pgtype.UUID
+	Name   string
+	Email  string
+}
+
+// Message is a notification as read back from the outbox.
+type Message struct {
+	Kind  string
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// Notifier delivers one message to one recipient over a single channel. Push goes
+// through the worker's batched Expo path; notifiers are the channels it falls back to,
+// tried in order, when the recipient cannot be reached by push.
+type Notifier interface {
+	Channel() Channel
+	Notify(ctx context.Context, to Recipient, msg Message) error
+}
@@ -0,0 +1,80 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Kinds of notification recorded in the outbox.
+const (
+	KindPaymentReminder  = "payment_reminder"
+	KindAddedToSplit     = "added_to_split"
+	KindItemClaimed      = "item_claimed"
+	KindMemberJoined     = "member_joined"
+	KindPaymentReceived  = "payment_received"
+	KindPaymentConfirmed = "payment_confirmed"
+	KindSplitSettled     = "split_settled"
+	KindTableClosed      = "table_closed"
+)
+
+// ErrOptedOut is returned by Enqueue when the recipient has turned off this kind of
+// notification on every channel. Nothing is recorded.
+var ErrOptedOut = errors.New("recipient has opted out of this notification")
+
+// Notification is a message addressed to a user rather than to a device or mailbox;
+// the worker resolves where to deliver it at send time.
+type Notification struct {
+	UserID  pgtype.UUID
+	SplitID pgtype.UUID // optional; lets the split host see delivery status
+	Kind    string
+	Title   string
+	Body    string
+	Data    map[string]string
+}
+
+// Enqueue records n in the outbox for the delivery worker. Pass queries bound to the
+// transaction of the change that triggered the notification, so that it is sent if
+// and only if that change commits.
+//
+// The recipient's preferences are consulted first: it returns ErrOptedOut if they
+// turned this event off on every channel, and holds delivery until their quiet hours
+// end. Which channel is used is decided by the worker.
+func Enqueue(ctx context.Context, queries tabmate.Querier, n Notification) error {
+	prefs, err := LoadPreferences(ctx, queries, n.UserID)
+	if err != nil {
+		return err
+	}
+	if !prefs.AllowsAny(n.Kind) {
+		return ErrOptedOut
+	}
+
+	data, err := json.Marshal(n.Data)
+	if err != nil {
+		return err
+	}
+	if n.Data == nil {
+		data = []byte("{}")
+	}
+
+	var notBefore pgtype.Timestamptz
+	if until, quiet := prefs.HoldUntil(n.Kind, time.Now()); quiet {
+		notBefore = pgtype.Timestamptz{Time: until, Valid: true}
+	}
+
+	_, err = queries.EnqueueNotification(ctx, tabmate.EnqueueNotificationParams{
+		UserID:        n.UserID,
+		SplitID:       n.SplitID,
+		Kind:          n.Kind,
+		Title:         n.Title,
+		Body:          n.Body,
+		Data:          data,
+		NextAttemptAt: notBefore,
+	})
+	return err
+}
@@ -0,0 +1,204 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	// Quiet hours are evaluated in each user's IANA timezone; embed the database so
+	// this works on hosts without /usr/share/zoneinfo.
+	_ "time/tzdata"
+)
+
+// Event is something a user can choose to be notified about.
+type Event string
+
+const (
+	EventReminders       Event = "reminders"
+	EventItemClaimed     Event = "item_claimed"
+	EventMemberJoined    Event = "member_joined"
+	EventPaymentReceived Event = "payment_received"
+	EventTableClosed     Event = "table_closed"
+)
+
+// Events lists every event users have a setting for.
+var Events = []Event{EventReminders, EventItemClaimed, EventMemberJoined, EventPaymentReceived, EventTableClosed}
+
+// Channel is a way of reaching a user.
+type Channel string
+
+const (
+	ChannelPush  Channel = "push"
+	ChannelEmail Channel = "email"
+)
+
+var Channels = []Channel{ChannelPush, ChannelEmail}
+
+// Quiet hours apply to everyone who has not changed them. They only hold back
+// reminders; see HoldUntil.
+const (
+	DefaultQuietHoursStart = 21
+	DefaultQuietHoursEnd   = 8
+)
+
+type QuietHours struct {
+	Enabled bool `json:"enabled"`
+	Start   int  `json:"start"` // hour of day, 0-23, in the user's timezone
+	End     int  `json:"end"`
+}
+
+// Preferences are a user's notification settings. Every event is enabled on every
+// channel unless the user turned it off.
+type Preferences struct {
+	Events     map[Event]map[Channel]bool `json:"events"`
+	QuietHours QuietHours                 `json:"quiet_hours"`
+	Timezone   string                     `json:"timezone"`
+}
+
+func DefaultPreferences() Preferences {
+	p := Preferences{
+		Events:     make(map[Event]map[Channel]bool, len(Events)),
+		QuietHours: QuietHours{Enabled: true, Start: DefaultQuietHoursStart, End: DefaultQuietHoursEnd},
+		Timezone:   "UTC",
+	}
+	for _, e := range Events {
+		p.Events[e] = make(map[Channel]bool, len(Channels))
+		for _, ch := range Channels {
+			p.Events[e][ch] = true
+		}
+	}
+	return p
+}
+
+// LoadPreferences returns the user's settings merged over the defaults.
+func LoadPreferences(ctx context.Context, queries tabmate.Querier, userID pgtype.UUID) (Preferences, error) {
+	row, err := queries.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return Preferences{}, err
+	}
+
+	p := DefaultPreferences()
+	p.Timezone = row.Timezone
+	if row.QuietHoursEnabled.Valid {
+		p.QuietHours = QuietHours{
+			Enabled: row.QuietHoursEnabled.Bool,
+			Start:   int(row.QuietHoursStart.Int32),
+			End:     int(row.QuietHoursEnd.Int32),
+		}
+	}
+	if len(row.Channels) > 0 {
+		var stored map[Event]map[Channel]bool
+		if err := json.Unmarshal(row.Channels, &stored); err != nil {
+			return Preferences{}, fmt.Errorf("decode notification channels: %w", err)
+		}
+		p.Merge(stored)
+	}
+	return p, nil
+}
+
+// Merge applies the given toggles on top of p, ignoring unknown events and channels.
+func (p Preferences) Merge(toggles map[Event]map[Channel]bool) {
+	for e, channels := range toggles {
+		if _, ok := p.Events[e]; !ok {
+			continue
+		}
+		for ch, on := range channels {
+			if _, ok := p.Events[e][ch]; ok {
+				p.Events[e][ch] = on
+			}
+		}
+	}
+}
+
+// Allows reports whether the user wants to hear about e on ch.
+func (p Preferences) Allows(e Event, ch Channel) bool {
+	channels, ok := p.Events[e]
+	if !ok {
+		return true
+	}
+	on, ok := channels[ch]
+	return !ok || on
+}
+
+// AllowsKind reports whether the user wants notifications of this outbox kind on ch.
+func (p Preferences) AllowsKind(kind string, ch Channel) bool {
+	event, ok := eventForKind(kind)
+	return !ok || p.Allows(event, ch)
+}
+
+// AllowsAny reports whether the user wants notifications of this kind on at least one
+// channel.
+func (p Preferences) AllowsAny(kind string) bool {
+	for _, ch := range Channels {
+		if p.AllowsKind(kind, ch) {
+			return true
+		}
+	}
+	return false
+}
+
+// QuietUntil reports whether t falls inside the user's quiet hours and, if so, when
+// they end. Unknown timezones are treated as UTC.
+func (p Preferences) QuietUntil(t time.Time) (time.Time, bool) {
+	q := p.QuietHours
+	if !q.Enabled || q.Start == q.End {
+		return time.Time{}, false
+	}
+
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	hour := local.Hour()
+
+	var quiet bool
+	if q.Start < q.End {
+		quiet = hour >= q.Start && hour < q.End
+	} else {
+		quiet = hour >= q.Start || hour < q.End
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	end := time.Date(local.Year(), local.Month(), local.Day(), q.End, 0, 0, 0, loc)
+	if !end.After(local) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end, true
+}
+
+// HoldUntil reports whether a notification of this kind queued at t should wait for
+// the user's quiet hours to end, and until when. Only reminders wait: everything else
+// is news of something that just happened, such as a payment arriving, and is sent
+// straight away.
+func (p Preferences) HoldUntil(kind string, t time.Time) (time.Time, bool) {
+	if kind != KindPaymentReminder {
+		return time.Time{}, false
+	}
+	return p.QuietUntil(t)
+}
+
+// eventForKind maps an outbox kind to the setting that governs it. Kinds without an
+// event, such as being added to a split or a split being settled, are always sent.
+func eventForKind(kind string) (Event, bool) {
+	switch kind {
+	case KindPaymentReminder:
+		return EventReminders, true
+	case KindItemClaimed:
+		return EventItemClaimed, true
+	case KindMemberJoined:
+		return EventMemberJoined, true
+	case KindPaymentReceived, KindPaymentConfirmed:
+		return EventPaymentReceived, true
+	case KindTableClosed:
+		return EventTableClosed, true
+	}
+	return "", false
+}
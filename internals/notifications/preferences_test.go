@@ -0,0 +1,80 @@
+package notifications
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietUntil(t *testing.T) {
+	// 21:30 UTC is 22:30 in Lagos, 17:30 in New York (EDT), 06:30 the next morning in
+	// Tokyo and 14:30 in Los Angeles.
+	at := time.Date(2026, 6, 1, 21, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		name  string
+		tz    string
+		quiet QuietHours
+		until time.Time
+	}{
+		{"utc default", "UTC", QuietHours{true, 21, 8}, time.Date(2026, 6, 2, 8, 0, 0, 0, time.UTC)},
+		{"lagos default", "Africa/Lagos", QuietHours{true, 21, 8}, time.Date(2026, 6, 2, 7, 0, 0, 0, time.UTC)},
+		{"new york default", "America/New_York", QuietHours{true, 21, 8}, time.Time{}},
+		{"tokyo early morning", "Asia/Tokyo", QuietHours{true, 21, 8}, time.Date(2026, 6, 1, 23, 0, 0, 0, time.UTC)},
+		{"los angeles afternoon", "America/Los_Angeles", QuietHours{true, 21, 8}, time.Time{}},
+		{"unknown zone is utc", "not/a-zone", QuietHours{true, 21, 8}, time.Date(2026, 6, 2, 8, 0, 0, 0, time.UTC)},
+		{"disabled", "UTC", QuietHours{false, 21, 8}, time.Time{}},
+		{"daytime window", "America/New_York", QuietHours{true, 13, 18}, time.Date(2026, 6, 1, 22, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := DefaultPreferences()
+			p.Timezone = tc.tz
+			p.QuietHours = tc.quiet
+
+			until, quiet := p.QuietUntil(at)
+			if quiet != !tc.until.IsZero() {
+				t.Fatalf("quiet = %v, want %v", quiet, !tc.until.IsZero())
+			}
+			if quiet && !until.Equal(tc.until) {
+				t.Fatalf("until = %v, want %v", until.UTC(), tc.until)
+			}
+		})
+	}
+}
+
+func TestHoldUntilOnlyHoldsReminders(t *testing.T) {
+	at := time.Date(2026, 6, 1, 23, 0, 0, 0, time.UTC)
+	p := DefaultPreferences()
+
+	if until, held := p.HoldUntil(KindPaymentReminder, at); !held || !until.Equal(time.Date(2026, 6, 2, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("reminder: held = %v until %v, want held until 08:00", held, until)
+	}
+	for _, kind := range []string{KindPaymentReceived, KindPaymentConfirmed, KindItemClaimed, KindSplitSettled} {
+		if _, held := p.HoldUntil(kind, at); held {
+			t.Errorf("%s held for quiet hours", kind)
+		}
+	}
+}
+
+func TestPreferencesMerge(t *testing.T) {
+	p := DefaultPreferences()
+	p.Merge(map[Event]map[Channel]bool{
+		EventReminders:   {ChannelPush: false},
+		"unknown_event":  {ChannelPush: false},
+		EventTableClosed: {"carrier_pigeon": false},
+	})
+
+	if p.Allows(EventReminders, ChannelPush) {
+		t.Error("reminders on push should be off")
+	}
+	if !p.Allows(EventReminders, ChannelEmail) {
+		t.Error("reminders on email should stay on")
+	}
+	if !p.Allows(EventTableClosed, ChannelPush) {
+		t.Error("table closed should stay on")
+	}
+	if _, ok := p.Events["unknown_event"]; ok {
+		t.Error("unknown events should be ignored")
+	}
+}
@@ -0,0 +1,82 @@
+package notifications
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"sync"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// emailTemplates maps outbox kinds to the email template that renders them. Kinds not
+// listed here use the generic one.
+var emailTemplates = map[string]string{
+	KindAddedToSplit:     "invite",
+	KindPaymentReminder:  "reminder",
+	KindPaymentConfirmed: "payment_confirmed",
+	KindSplitSettled:     "settled",
+}
+
+// emailData is what the email templates are executed with.
+type emailData struct {
+	Name  string
+	Title string
+	Body  string
+	Data  map[string]string
+	Link  string
+}
+
+type emailTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+var (
+	parsedMu sync.Mutex
+	parsed   = map[string]emailTemplate{}
+)
+
+// renderEmail returns the HTML and plain-text bodies for a notification of the given
+// kind. Each HTML template fills in the shared layout.
+func renderEmail(kind string, data emailData) (string, string, error) {
+	name, ok := emailTemplates[kind]
+	if !ok {
+		name = "generic"
+	}
+	tmpl, err := loadEmailTemplate(name)
+	if err != nil {
+		return "", "", err
+	}
+
+	var html, text bytes.Buffer
+	if err := tmpl.html.ExecuteTemplate(&html, "layout.html", data); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return "", "", err
+	}
+	return html.String(), text.String(), nil
+}
+
+func loadEmailTemplate(name string) (emailTemplate, error) {
+	parsedMu.Lock()
+	defer parsedMu.Unlock()
+
+	if tmpl, ok := parsed[name]; ok {
+		return tmpl, nil
+	}
+	html, err := htmltemplate.ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html")
+	if err != nil {
+		return emailTemplate{}, err
+	}
+	text, err := texttemplate.ParseFS(templateFS, "templates/"+name+".txt")
+	if err != nil {
+		return emailTemplate{}, err
+	}
+	tmpl := emailTemplate{html: html, text: text}
+	parsed[name] = tmpl
+	return tmpl, nil
+}
@@ -0,0 +1,421 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Outbox statuses, as stored in notification_outbox.status.
+const (
+	StatusPending   = "pending"
+	StatusSending   = "sending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// Push ticket statuses, as stored in push_tickets.status.
+const (
+	TicketPending = "pending"
+	TicketOK      = "ok"
+	TicketError   = "error"
+	TicketExpired = "expired"
+)
+
+const (
+	defaultConcurrency  = 4
+	defaultBatchSize    = expoSendChunkSize
+	defaultPollInterval = 5 * time.Second
+	// A claimed notification is handed to another worker if it has not been
+	// resolved within this long, e.g. because the process died mid-send.
+	defaultLockTimeout = 2 * time.Minute
+
+	// Expo recommends waiting about 15 minutes before fetching receipts, and keeps
+	// them for a day.
+	receiptDelay    = 15 * time.Minute
+	receiptExpiry   = 24 * time.Hour
+	receiptInterval = 5 * time.Minute
+	receiptBatch    = expoReceiptChunkSize
+
+	baseBackoff = 30 * time.Second
+	maxBackoff  = time.Hour
+)
+
+// ErrNoDevices is recorded against notifications whose recipient has no active
+// device to deliver to and no other channel reaches them. It is not retried.
+var ErrNoDevices = errors.New("recipient has no registered device")
+
+// errPushOff means the recipient turned push off for this kind of notification.
+var errPushOff = errors.New("recipient turned off push for this notification")
+
+// Worker delivers notifications from the outbox to every device of each recipient, in
+// Expo-sized batches on a fixed pool of senders, retrying failures with exponential
+// backoff until each row's max_attempts. When push cannot reach someone it falls back
+// to the other notifiers it was given, such as email. It also polls push receipts and
+// forgets tokens Expo reports as dead.
+type Worker struct {
+	queries      tabmate.Querier
+	expo         *ExpoClient
+	fallbacks    []Notifier
+	concurrency  int
+	batchSize    int
+	pollInterval time.Duration
+	lockTimeout  time.Duration
+	now          func() time.Time
+}
+
+// NewWorker returns a worker that sends by push, then tries fallbacks in order.
+func NewWorker(queries tabmate.Querier, expo *ExpoClient, fallbacks ...Notifier) *Worker {
+	return &Worker{
+		queries:      queries,
+		expo:         expo,
+		fallbacks:    fallbacks,
+		concurrency:  defaultConcurrency,
+		batchSize:    defaultBatchSize,
+		pollInterval: defaultPollInterval,
+		lockTimeout:  defaultLockTimeout,
+		now:          time.Now,
+	}
+}
+
+// Run delivers notifications and checks receipts until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < w.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.sendLoop(ctx)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.receiptLoop(ctx)
+	}()
+	wg.Wait()
+}
+
+// sendLoop claims batches until the outbox is drained, then polls every pollInterval.
+// Each loop claims its own rows, so several can run side by side.
+func (w *Worker) sendLoop(ctx context.Context) {
+	for {
+		n, err := w.RunOnce(ctx)
+		if err != nil {
+			log.Printf("[outbox] run failed: %v", err)
+		}
+		if err == nil && n == w.batchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
+
+func (w *Worker) receiptLoop(ctx context.Context) {
+	ticker := time.NewTicker(receiptInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := w.CheckReceipts(ctx); err != nil {
+			log.Printf("[outbox] receipt check failed: %v", err)
+		}
+	}
+}
+
+// RunOnce claims one batch of due notifications and sends them to every active
+// device of each recipient in as few Expo requests as possible, returning how many
+// notifications were claimed.
+func (w *Worker) RunOnce(ctx context.Context) (int, error) {
+	batch, err := w.queries.ClaimDueNotifications(ctx, tabmate.ClaimDueNotificationsParams{
+		LockSeconds: int32(w.lockTimeout / time.Second),
+		BatchSize:   int32(w.batchSize),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var msgs []ExpoMessage
+	var owners []int // owners[i] is the batch index msgs[i] was built from
+	for i, n := range batch {
+		fanout, err := w.messages(ctx, n)
+		if errors.Is(err, ErrNoDevices) || errors.Is(err, errPushOff) {
+			w.fallback(ctx, n, err)
+			continue
+		}
+		if err != nil {
+			w.fail(ctx, n, err)
+			continue
+		}
+		for _, msg := range fanout {
+			msgs = append(msgs, msg)
+			owners = append(owners, i)
+		}
+	}
+	if len(msgs) == 0 {
+		return len(batch), nil
+	}
+
+	tickets := w.expo.Send(ctx, msgs)
+	for start := 0; start < len(msgs); {
+		end := start
+		for end < len(msgs) && owners[end] == owners[start] {
+			end++
+		}
+		w.handleTickets(ctx, batch[owners[start]], msgs[start:end], tickets[start:end])
+		start = end
+	}
+
+	return len(batch), nil
+}
+
+// messages builds one Expo message per active device of the recipient, unless they
+// turned push off for this kind of notification.
+func (w *Worker) messages(ctx context.Context, n tabmate.NotificationOutbox) ([]ExpoMessage, error) {
+	prefs, err := LoadPreferences(ctx, w.queries, n.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !prefs.AllowsKind(n.Kind, ChannelPush) {
+		return nil, errPushOff
+	}
+
+	devices, err := w.queries.ListActiveDeviceTokens(ctx, n.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return nil, ErrNoDevices
+	}
+
+	msg, err := outboxMessage(n)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := make([]ExpoMessage, len(devices))
+	for i, d := range devices {
+		msgs[i] = ExpoMessage{
+			To:    d.Token,
+			Title: msg.Title,
+			Body:  msg.Body,
+			Data:  msg.Data,
+		}
+	}
+	return msgs, nil
+}
+
+func outboxMessage(n tabmate.NotificationOutbox) (Message, error) {
+	msg := Message{Kind: n.Kind, Title: n.Title, Body: n.Body}
+	if len(n.Data) > 0 {
+		if err := json.Unmarshal(n.Data, &msg.Data); err != nil {
+			return Message{}, err
+		}
+	}
+	return msg, nil
+}
+
+// handleTickets resolves one notification from the tickets of all its devices. It
+// counts as delivered if any device accepted it; it is only retried if none did, so a
+// flaky tablet never causes a duplicate on the phone. If push has failed for good, the
+// fallback channels get a turn.
+func (w *Worker) handleTickets(ctx context.Context, n tabmate.NotificationOutbox, msgs []ExpoMessage, tickets []PushTicket) {
+	delivered, retryable := false, false
+	var reasons []string
+	for i, ticket := range tickets {
+		switch {
+		case ticket.OK():
+			delivered = true
+			if err := w.queries.CreatePushTicket(ctx, tabmate.CreatePushTicketParams{
+				ID:             ticket.ID,
+				NotificationID: n.ID,
+				PushToken:      msgs[i].To,
+			}); err != nil {
+				log.Printf("[outbox] failed to store push ticket %s: %v", ticket.ID, err)
+			}
+		case ticket.Details.Error == ExpoDeviceNotRegistered:
+			w.forgetToken(ctx, msgs[i].To)
+			reasons = append(reasons, ticket.Reason())
+		default:
+			retryable = retryable || ticket.Retryable()
+			reasons = append(reasons, ticket.Reason())
+		}
+	}
+
+	if delivered {
+		w.delivered(ctx, n)
+		return
+	}
+
+	cause := errors.New(strings.Join(reasons, "; "))
+	if !retryable || n.Attempts >= n.MaxAttempts {
+		w.fallback(ctx, n, cause)
+		return
+	}
+	w.retry(ctx, n, cause)
+}
+
+// fallback offers a notification push could not deliver to each fallback notifier
+// the recipient allows for its kind, stopping at the first that sends it. If none can
+// reach them, n fails with cause.
+func (w *Worker) fallback(ctx context.Context, n tabmate.NotificationOutbox, cause error) {
+	if len(w.fallbacks) == 0 {
+		w.fail(ctx, n, cause)
+		return
+	}
+
+	prefs, err := LoadPreferences(ctx, w.queries, n.UserID)
+	if err != nil {
+		w.retry(ctx, n, err)
+		return
+	}
+	user, err := w.queries.GetUserByID(ctx, n.UserID)
+	if err != nil {
+		w.retry(ctx, n, err)
+		return
+	}
+	msg, err := outboxMessage(n)
+	if err != nil {
+		w.fail(ctx, n, err)
+		return
+	}
+
+	to := Recipient{UserID: user.ID, Name: user.Name.String, Email: user.Email}
+	for _, notifier := range w.fallbacks {
+		if !prefs.AllowsKind(n.Kind, notifier.Channel()) {
+			continue
+		}
+		err := notifier.Notify(ctx, to, msg)
+		if errors.Is(err, ErrUnreachable) {
+			continue
+		}
+		if err != nil {
+			w.retry(ctx, n, fmt.Errorf("%s: %w", notifier.Channel(), err))
+			return
+		}
+		w.delivered(ctx, n)
+		return
+	}
+	w.fail(ctx, n, cause)
+}
+
+func (w *Worker) delivered(ctx context.Context, n tabmate.NotificationOutbox) {
+	if err := w.queries.MarkNotificationDelivered(ctx, n.ID); err != nil {
+		log.Printf("[outbox] failed to mark notification %s delivered: %v", n.ID.String(), err)
+	}
+}
+
+// retry schedules another attempt after the backoff for n, or fails it if it has used
+// all its attempts.
+func (w *Worker) retry(ctx context.Context, n tabmate.NotificationOutbox, cause error) {
+	if n.Attempts >= n.MaxAttempts {
+		w.fail(ctx, n, cause)
+		return
+	}
+
+	next := w.now().Add(Backoff(int(n.Attempts)))
+	if err := w.queries.RescheduleNotification(ctx, tabmate.RescheduleNotificationParams{
+		ID:            n.ID,
+		NextAttemptAt: pgtype.Timestamptz{Time: next, Valid: true},
+		LastError:     pgtype.Text{String: cause.Error(), Valid: true},
+	}); err != nil {
+		log.Printf("[outbox] failed to reschedule notification %s: %v", n.ID.String(), err)
+	}
+}
+
+func (w *Worker) fail(ctx context.Context, n tabmate.NotificationOutbox, cause error) {
+	log.Printf("[outbox] giving up on notification %s after %d attempt(s): %v", n.ID.String(), n.Attempts, cause)
+	if err := w.queries.MarkNotificationFailed(ctx, tabmate.MarkNotificationFailedParams{
+		ID:        n.ID,
+		LastError: pgtype.Text{String: cause.Error(), Valid: true},
+	}); err != nil {
+		log.Printf("[outbox] failed to mark notification %s failed: %v", n.ID.String(), err)
+	}
+}
+
+func (w *Worker) forgetToken(ctx context.Context, token string) {
+	if err := w.queries.DeleteDeviceTokenByToken(ctx, token); err != nil {
+		log.Printf("[outbox] failed to clear unregistered push token: %v", err)
+	}
+}
+
+// CheckReceipts fetches receipts for tickets that are old enough to have one, and
+// forgets tokens whose device is no longer registered.
+func (w *Worker) CheckReceipts(ctx context.Context) error {
+	now := w.now()
+	tickets, err := w.queries.ListPendingPushTickets(ctx, tabmate.ListPendingPushTicketsParams{
+		CreatedBefore: pgtype.Timestamptz{Time: now.Add(-receiptDelay), Valid: true},
+		BatchSize:     receiptBatch,
+	})
+	if err != nil || len(tickets) == 0 {
+		return err
+	}
+
+	ids := make([]string, len(tickets))
+	for i, t := range tickets {
+		ids[i] = t.ID
+	}
+	receipts, err := w.expo.Receipts(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tickets {
+		receipt, ok := receipts[t.ID]
+		status, reason := TicketOK, pgtype.Text{}
+		switch {
+		case !ok && now.Sub(t.CreatedAt.Time) < receiptExpiry:
+			continue
+		case !ok:
+			status = TicketExpired
+		case receipt.Status != expoStatusOK:
+			status = TicketError
+			reason = pgtype.Text{String: receipt.Reason(), Valid: true}
+			if receipt.Details.Error == ExpoDeviceNotRegistered {
+				w.forgetToken(ctx, t.PushToken)
+			}
+		}
+
+		if err := w.queries.MarkPushTicketChecked(ctx, tabmate.MarkPushTicketCheckedParams{
+			ID:     t.ID,
+			Status: status,
+			Error:  reason,
+		}); err != nil {
+			log.Printf("[outbox] failed to record receipt for ticket %s: %v", t.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// Backoff returns how long to wait before retrying after the given number of failed
+// attempts: 30s, 1m, 2m, 4m, ... capped at an hour.
+func Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	d := baseBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
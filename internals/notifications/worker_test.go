@@ -0,0 +1,283 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// outboxQueries is a Querier backed by in-memory state. Methods the worker does not
+// call are left to the embedded nil interface.
+type outboxQueries struct {
+	tabmate.Querier
+	batch   []tabmate.NotificationOutbox
+	devices []string
+	status  map[pgtype.UUID]string
+	nextAt  map[pgtype.UUID]time.Time
+	tickets map[string]tabmate.PushTickets
+	cleared []string
+	email   string
+	prefs   []byte // stored channel toggles, as JSON
+}
+
+func newOutboxQueries(devices []string, batch ...tabmate.NotificationOutbox) *outboxQueries {
+	return &outboxQueries{
+		batch:   batch,
+		devices: devices,
+		status:  map[pgtype.UUID]string{},
+		nextAt:  map[pgtype.UUID]time.Time{},
+		tickets: map[string]tabmate.PushTickets{},
+	}
+}
+
+func (q *outboxQueries) ClaimDueNotifications(ctx context.Context, arg tabmate.ClaimDueNotificationsParams) ([]tabmate.NotificationOutbox, error) {
+	batch := q.batch
+	q.batch = nil
+	return batch, nil
+}
+
+func (q *outboxQueries) GetNotificationPreferences(ctx context.Context, id pgtype.UUID) (tabmate.GetNotificationPreferencesRow, error) {
+	return tabmate.GetNotificationPreferencesRow{Timezone: "UTC", Channels: q.prefs}, nil
+}
+
+func (q *outboxQueries) GetUserByID(ctx context.Context, id pgtype.UUID) (tabmate.Users, error) {
+	return tabmate.Users{ID: id, Name: pgtype.Text{String: "Ada", Valid: true}, Email: q.email}, nil
+}
+
+func (q *outboxQueries) ListActiveDeviceTokens(ctx context.Context, userID pgtype.UUID) ([]tabmate.DeviceTokens, error) {
+	devices := make([]tabmate.DeviceTokens, len(q.devices))
+	for i, token := range q.devices {
+		devices[i] = tabmate.DeviceTokens{UserID: userID, Token: token}
+	}
+	return devices, nil
+}
+
+func (q *outboxQueries) MarkNotificationDelivered(ctx context.Context, id pgtype.UUID) error {
+	q.status[id] = StatusDelivered
+	return nil
+}
+
+func (q *outboxQueries) MarkNotificationFailed(ctx context.Context, arg tabmate.MarkNotificationFailedParams) error {
+	q.status[arg.ID] = StatusFailed
+	return nil
+}
+
+func (q *outboxQueries) RescheduleNotification(ctx context.Context, arg tabmate.RescheduleNotificationParams) error {
+	q.status[arg.ID] = StatusPending
+	q.nextAt[arg.ID] = arg.NextAttemptAt.Time
+	return nil
+}
+
+func (q *outboxQueries) CreatePushTicket(ctx context.Context, arg tabmate.CreatePushTicketParams) error {
+	q.tickets[arg.ID] = tabmate.PushTickets{ID: arg.ID, NotificationID: arg.NotificationID, PushToken: arg.PushToken, Status: TicketPending}
+	return nil
+}
+
+func (q *outboxQueries) ListPendingPushTickets(ctx context.Context, arg tabmate.ListPendingPushTicketsParams) ([]tabmate.PushTickets, error) {
+	var pending []tabmate.PushTickets
+	for _, t := range q.tickets {
+		if t.Status == TicketPending && !t.CreatedAt.Time.After(arg.CreatedBefore.Time) {
+			pending = append(pending, t)
+		}
+	}
+	return pending, nil
+}
+
+func (q *outboxQueries) MarkPushTicketChecked(ctx context.Context, arg tabmate.MarkPushTicketCheckedParams) error {
+	t := q.tickets[arg.ID]
+	t.Status = arg.Status
+	t.Error = arg.Error
+	q.tickets[arg.ID] = t
+	return nil
+}
+
+func (q *outboxQueries) DeleteDeviceTokenByToken(ctx context.Context, token string) error {
+	q.cleared = append(q.cleared, token)
+	return nil
+}
+
+func TestWorkerDelivery(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	id := pgtype.UUID{Bytes: [16]byte{1}, Valid: true}
+
+	phone := "ExponentPushToken[phone]"
+	cases := []struct {
+		name     string
+		attempts int32
+		devices  []string
+		status   string
+		tickets  int
+		retryIn  time.Duration
+		cleared  bool
+	}{
+		{"delivered", 1, []string{phone}, StatusDelivered, 1, 0, false},
+		{"fans out to every device", 1, []string{phone, "ExponentPushToken[tablet]"}, StatusDelivered, 2, 0, false},
+		{"rate limited is retried with backoff", 3, []string{busyToken}, StatusPending, 0, 2 * time.Minute, false},
+		{"gives up at max attempts", 5, []string{busyToken}, StatusFailed, 0, 0, false},
+		{"one device accepting is enough", 1, []string{busyToken, phone}, StatusDelivered, 1, 0, false},
+		{"unregistered device", 1, []string{deadToken}, StatusFailed, 0, 0, true},
+		{"unregistered device beside a live one", 1, []string{deadToken, phone}, StatusDelivered, 1, 0, true},
+		{"no devices", 1, nil, StatusFailed, 0, 0, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, client := newFakeExpo(t)
+			q := newOutboxQueries(tc.devices, tabmate.NotificationOutbox{
+				ID:          id,
+				Title:       "Payment reminder",
+				Data:        []byte(`{"splitCode":"ABC123"}`),
+				Attempts:    tc.attempts,
+				MaxAttempts: 5,
+			})
+			w := NewWorker(q, client)
+			w.now = func() time.Time { return now }
+
+			if n, err := w.RunOnce(context.Background()); err != nil || n != 1 {
+				t.Fatalf("RunOnce = %d, %v", n, err)
+			}
+			if got := q.status[id]; got != tc.status {
+				t.Fatalf("status = %q, want %q", got, tc.status)
+			}
+			if tc.retryIn > 0 && !q.nextAt[id].Equal(now.Add(tc.retryIn)) {
+				t.Fatalf("next attempt = %v, want %v", q.nextAt[id], now.Add(tc.retryIn))
+			}
+			if len(q.tickets) != tc.tickets {
+				t.Fatalf("stored %d tickets, want %d", len(q.tickets), tc.tickets)
+			}
+			if cleared := len(q.cleared) > 0; cleared != tc.cleared {
+				t.Fatalf("cleared tokens = %v, want cleared=%v", q.cleared, tc.cleared)
+			}
+		})
+	}
+}
+
+// recordingNotifier stands in for email, failing with err if set.
+type recordingNotifier struct {
+	sent []Recipient
+	err  error
+}
+
+func (n *recordingNotifier) Channel() Channel { return ChannelEmail }
+
+func (n *recordingNotifier) Notify(ctx context.Context, to Recipient, msg Message) error {
+	if to.Email == "" {
+		return ErrUnreachable
+	}
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, to)
+	return nil
+}
+
+func TestWorkerEmailFallback(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	id := pgtype.UUID{Bytes: [16]byte{2}, Valid: true}
+
+	phone := "ExponentPushToken[phone]"
+	cases := []struct {
+		name    string
+		devices []string
+		email   string
+		prefs   string
+		sendErr error
+		status  string
+		emailed bool
+	}{
+		{"no devices", nil, "ada@example.com", "", nil, StatusDelivered, true},
+		{"push is preferred", []string{phone}, "ada@example.com", "", nil, StatusDelivered, false},
+		{"push turned off", []string{phone}, "ada@example.com", `{"reminders":{"push":false}}`, nil, StatusDelivered, true},
+		{"email turned off", nil, "ada@example.com", `{"reminders":{"email":false}}`, nil, StatusFailed, false},
+		{"no email address", nil, "", "", nil, StatusFailed, false},
+		{"only unregistered devices", []string{deadToken}, "ada@example.com", "", nil, StatusDelivered, true},
+		{"smtp failure is retried", nil, "ada@example.com", "", errors.New("421 try later"), StatusPending, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, client := newFakeExpo(t)
+			q := newOutboxQueries(tc.devices, tabmate.NotificationOutbox{
+				ID:          id,
+				Kind:        KindPaymentReminder,
+				Title:       "Payment reminder",
+				Attempts:    1,
+				MaxAttempts: 5,
+			})
+			q.email = tc.email
+			q.prefs = []byte(tc.prefs)
+			email := &recordingNotifier{err: tc.sendErr}
+			w := NewWorker(q, client, email)
+			w.now = func() time.Time { return now }
+
+			if _, err := w.RunOnce(context.Background()); err != nil {
+				t.Fatalf("RunOnce: %v", err)
+			}
+			if got := q.status[id]; got != tc.status {
+				t.Fatalf("status = %q, want %q", got, tc.status)
+			}
+			if emailed := len(email.sent) > 0; emailed != tc.emailed {
+				t.Fatalf("emailed = %v, want %v", emailed, tc.emailed)
+			}
+		})
+	}
+}
+
+func TestWorkerCheckReceipts(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	f, client := newFakeExpo(t)
+	q := newOutboxQueries(nil)
+	w := NewWorker(q, client)
+	w.now = func() time.Time { return now }
+
+	sentAt := func(ago time.Duration) pgtype.Timestamptz {
+		return pgtype.Timestamptz{Time: now.Add(-ago), Valid: true}
+	}
+	q.tickets = map[string]tabmate.PushTickets{
+		"ok":      {ID: "ok", PushToken: "ExponentPushToken[a]", Status: TicketPending, CreatedAt: sentAt(time.Hour)},
+		"dead":    {ID: "dead", PushToken: "ExponentPushToken[b]", Status: TicketPending, CreatedAt: sentAt(time.Hour)},
+		"waiting": {ID: "waiting", PushToken: "ExponentPushToken[c]", Status: TicketPending, CreatedAt: sentAt(time.Hour)},
+		"lost":    {ID: "lost", PushToken: "ExponentPushToken[d]", Status: TicketPending, CreatedAt: sentAt(25 * time.Hour)},
+		"recent":  {ID: "recent", PushToken: "ExponentPushToken[e]", Status: TicketPending, CreatedAt: sentAt(time.Minute)},
+	}
+	f.receipts["ok"] = PushReceipt{Status: "ok"}
+	f.receipts["dead"] = PushReceipt{Status: "error", Message: "gone", Details: PushDetails{Error: ExpoDeviceNotRegistered}}
+	f.receipts["recent"] = PushReceipt{Status: "ok"}
+
+	if err := w.CheckReceipts(context.Background()); err != nil {
+		t.Fatalf("CheckReceipts: %v", err)
+	}
+
+	want := map[string]string{
+		"ok":      TicketOK,
+		"dead":    TicketError,
+		"waiting": TicketPending,
+		"lost":    TicketExpired,
+		"recent":  TicketPending, // too new to ask Expo about
+	}
+	for id, status := range want {
+		if got := q.tickets[id].Status; got != status {
+			t.Errorf("ticket %s status = %q, want %q", id, got, status)
+		}
+	}
+	if len(q.cleared) != 1 || q.cleared[0] != "ExponentPushToken[b]" {
+		t.Errorf("cleared = %v, want the unregistered token only", q.cleared)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute}
+	for i, d := range want {
+		if got := Backoff(i + 1); got != d {
+			t.Errorf("Backoff(%d) = %v, want %v", i+1, got, d)
+		}
+	}
+	if got := Backoff(20); got != time.Hour {
+		t.Errorf("Backoff(20) = %v, want cap of 1h", got)
+	}
+}
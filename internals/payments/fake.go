@@ -0,0 +1,137 @@
+package payments
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"tabmate/internals/webhooks"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// FakeSignatureHeader carries the hex HMAC-SHA256 of "<timestamp>.<body>".
+	FakeSignatureHeader = "X-Fake-Signature"
+	// FakeTimestampHeader carries the unix time the fake webhook was signed at.
+	FakeTimestampHeader = "X-Fake-Timestamp"
+)
+
+// FakeWebhookVerifier returns the signing scheme used by fake provider webhooks.
+func FakeWebhookVerifier(secret string) webhooks.HMACVerifier {
+	return webhooks.HMACVerifier{
+		Secret:          []byte(secret),
+		Hash:            sha256.New,
+		SignatureHeader: FakeSignatureHeader,
+		TimestampHeader: FakeTimestampHeader,
+	}
+}
+
+// FakeProvider is an in-memory provider for local development and tests.
+// Payments stay pending until Complete or Fail is called, which returns the
+// signed webhook the real provider would have sent.
+type FakeProvider struct {
+	mu       sync.Mutex
+	verifier webhooks.HMACVerifier
+	baseURL  string
+	payments map[string]*fakePayment
+}
+
+type fakePayment struct {
+	request LinkRequest
+	status  Status
+}
+
+func NewFakeProvider(secret, baseURL string) *FakeProvider {
+	if baseURL == "" {
+		baseURL = "http://localhost:8080/fake-checkout"
+	}
+	return &FakeProvider{
+		verifier: FakeWebhookVerifier(secret),
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		payments: make(map[string]*fakePayment),
+	}
+}
+
+func (f *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (f *FakeProvider) CreatePaymentLink(_ context.Context, req LinkRequest) (*Link, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.payments[req.Reference]; exists {
+		return nil, fmt.Errorf("duplicate reference %q", req.Reference)
+	}
+	f.payments[req.Reference] = &fakePayment{request: req, status: StatusPending}
+
+	return &Link{Reference: req.Reference, CheckoutURL: f.baseURL + "/" + req.Reference}, nil
+}
+
+func (f *FakeProvider) GetPaymentStatus(_ context.Context, reference string) (Status, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	payment, ok := f.payments[reference]
+	if !ok {
+		return "", fmt.Errorf("unknown reference %q", reference)
+	}
+	return payment.status, nil
+}
+
+func (f *FakeProvider) ParseWebhook(header http.Header, body []byte) (*WebhookEvent, error) {
+	if err := f.verifier.Verify(header, body); err != nil {
+		return nil, err
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("decode fake webhook: %w", err)
+	}
+	event.Provider = f.Name()
+	return &event, nil
+}
+
+// Complete marks a payment as paid and returns the signed webhook body and headers.
+func (f *FakeProvider) Complete(reference string) ([]byte, http.Header, error) {
+	return f.settle(reference, StatusSucceeded)
+}
+
+// Fail marks a payment as failed and returns the signed webhook body and headers.
+func (f *FakeProvider) Fail(reference string) ([]byte, http.Header, error) {
+	return f.settle(reference, StatusFailed)
+}
+
+// Sign returns the headers the fake provider attaches to a webhook body.
+func (f *FakeProvider) Sign(body []byte) http.Header {
+	return f.verifier.Sign(body)
+}
+
+func (f *FakeProvider) settle(reference string, status Status) ([]byte, http.Header, error) {
+	f.mu.Lock()
+	payment, ok := f.payments[reference]
+	if !ok {
+		f.mu.Unlock()
+		return nil, nil, fmt.Errorf("unknown reference %q", reference)
+	}
+	payment.status = status
+	event := WebhookEvent{
+		EventID:   "evt_" + uuid.New().String(),
+		Type:      "payment." + string(status),
+		Reference: reference,
+		Status:    status,
+		Amount:    payment.request.Amount,
+		Currency:  payment.request.Currency,
+	}
+	f.mu.Unlock()
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, f.Sign(body), nil
+}
@@ -0,0 +1,45 @@
+package payments
+
+import (
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+// NormalizeIBAN strips spaces and upper-cases an IBAN as typed by a user.
+func NormalizeIBAN(iban string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(iban), ""))
+}
+
+// ValidIBAN reports whether iban is well formed and passes the ISO 13616 mod-97 check.
+func ValidIBAN(iban string) bool {
+	iban = NormalizeIBAN(iban)
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	for i, r := range iban {
+		switch {
+		case i < 2 && !unicode.IsUpper(r):
+			return false
+		case i >= 2 && i < 4 && !unicode.IsDigit(r):
+			return false
+		case r > unicode.MaxASCII || !(unicode.IsUpper(r) || unicode.IsDigit(r)):
+			return false
+		}
+	}
+
+	// Move the country code and check digits to the end and turn letters into numbers.
+	var digits strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if unicode.IsDigit(r) {
+			digits.WriteRune(r)
+		} else {
+			digits.WriteString(big.NewInt(int64(r - 'A' + 10)).String())
+		}
+	}
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	if !ok {
+		return false
+	}
+	return new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
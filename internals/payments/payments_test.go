@@ -0,0 +1,157 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"tabmate/internals/webhooks"
+	"testing"
+)
+
+func TestFakeProviderCompleteProducesVerifiableWebhook(t *testing.T) {
+	provider := NewFakeProvider("secret", "")
+
+	link, err := provider.CreatePaymentLink(context.Background(), LinkRequest{
+		Reference: "TM-abcd1234-0001",
+		Amount:    12.5,
+		Currency:  "NGN",
+	})
+	if err != nil {
+		t.Fatalf("CreatePaymentLink: %v", err)
+	}
+
+	body, header, err := provider.Complete(link.Reference)
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	event, err := provider.ParseWebhook(header, body)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if event.Reference != link.Reference || event.Status != StatusSucceeded || event.Amount != 12.5 {
+		t.Fatalf("event = %+v", event)
+	}
+
+	status, _ := provider.GetPaymentStatus(context.Background(), link.Reference)
+	if status != StatusSucceeded {
+		t.Fatalf("status = %s, want %s", status, StatusSucceeded)
+	}
+
+	header.Set(FakeSignatureHeader, "tampered")
+	if _, err := provider.ParseWebhook(header, body); !errors.Is(err, webhooks.ErrInvalidSignature) {
+		t.Fatalf("tampered signature error = %v", err)
+	}
+}
+
+func TestPaystackProviderAgainstLocalServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]any{"status": false, "message": "bad key"})
+			return
+		}
+		switch r.URL.Path {
+		case "/transaction/initialize":
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["amount"] != float64(1050) {
+				t.Errorf("amount = %v, want 1050 minor units", body["amount"])
+			}
+			json.NewEncoder(w).Encode(map[string]any{
+				"status": true,
+				"data": map[string]any{
+					"authorization_url": "https://checkout.example/abc",
+					"reference":         body["reference"],
+				},
+			})
+		case "/transaction/verify/TM-ref":
+			json.NewEncoder(w).Encode(map[string]any{
+				"status": true,
+				"data":   map[string]any{"reference": "TM-ref", "status": "success"},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]any{"status": false, "message": "not found"})
+		}
+	}))
+	defer server.Close()
+
+	provider := NewPaystackProvider("sk_test", server.URL, server.Client())
+
+	link, err := provider.CreatePaymentLink(context.Background(), LinkRequest{Reference: "TM-ref", Amount: 10.5, Currency: "NGN"})
+	if err != nil {
+		t.Fatalf("CreatePaymentLink: %v", err)
+	}
+	if link.CheckoutURL != "https://checkout.example/abc" || link.Reference != "TM-ref" {
+		t.Fatalf("link = %+v", link)
+	}
+
+	status, err := provider.GetPaymentStatus(context.Background(), "TM-ref")
+	if err != nil || status != StatusSucceeded {
+		t.Fatalf("GetPaymentStatus = %s, %v", status, err)
+	}
+
+	body := []byte(`{"event":"charge.success","data":{"id":42,"reference":"TM-ref","status":"success","amount":1050,"currency":"NGN"}}`)
+	header := PaystackWebhookVerifier("sk_test").Sign(body)
+
+	event, err := provider.ParseWebhook(header, body)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if event.Status != StatusSucceeded || event.Amount != 10.5 || event.EventID != "charge.success:42" {
+		t.Fatalf("event = %+v", event)
+	}
+}
+
+func TestValidIBAN(t *testing.T) {
+	for iban, want := range map[string]bool{
+		"DE89 3704 0044 0532 0130 00": true,
+		"GB82WEST12345698765432":      true,
+		"GB82WEST12345698765433":      false,
+		"0123456789":                  false,
+		"de89370400440532013000":      true,
+	} {
+		if got := ValidIBAN(iban); got != want {
+			t.Errorf("ValidIBAN(%q) = %v, want %v", iban, got, want)
+		}
+	}
+}
+
+func TestPaymentRequestFormats(t *testing.T) {
+	req := PaymentRequest{
+		PayeeName:     "Ada Obi",
+		AccountNumber: "DE89 3704 0044 0532 0130 00",
+		Amount:        12.5,
+		Currency:      "EUR",
+		Reference:     "TM-abcd1234-0f1e2d3c",
+	}
+
+	epc, err := req.EPCPayload()
+	if err != nil {
+		t.Fatalf("EPCPayload: %v", err)
+	}
+	want := "BCD\n002\n1\nSCT\n\nAda Obi\nDE89370400440532013000\nEUR12.50\n\n\nTM-abcd1234-0f1e2d3c"
+	if epc != want {
+		t.Fatalf("EPCPayload = %q, want %q", epc, want)
+	}
+
+	payto, err := req.PaytoURI()
+	if err != nil || payto != "payto://iban/DE89370400440532013000?amount=EUR%3A12.50&message=TM-abcd1234-0f1e2d3c&receiver-name=Ada+Obi" {
+		t.Fatalf("PaytoURI = %q, %v", payto, err)
+	}
+
+	local := PaymentRequest{PayeeName: "Ada Obi", AccountNumber: "0123456789", Amount: 5000, Currency: "NGN", Reference: "TM-x"}
+	if _, err := local.EPCPayload(); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("EPCPayload for NUBAN account error = %v", err)
+	}
+	if link := local.DeepLink(); link != "tabmate://pay?account=0123456789&amount=5000.00&currency=NGN&name=Ada+Obi&reference=TM-x" {
+		t.Fatalf("DeepLink = %q", link)
+	}
+
+	if _, err := QRCodePNG(req.DeepLink(), 256); err != nil {
+		t.Fatalf("QRCodePNG: %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"tabmate/internals/webhooks"
+	"time"
+)
+
+const defaultPaystackBaseURL = "https://api.paystack.co"
+
+// PaystackWebhookVerifier returns Paystack's webhook scheme: a hex HMAC-SHA512 of the
+// raw body keyed with the secret key. Paystack sends no signed timestamp, so replays
+// are caught by event id deduplication in the webhooks dispatcher instead.
+func PaystackWebhookVerifier(secretKey string) webhooks.HMACVerifier {
+	return webhooks.HMACVerifier{
+		Secret:          []byte(secretKey),
+		Hash:            sha512.New,
+		SignatureHeader: "X-Paystack-Signature",
+	}
+}
+
+// PaystackProvider collects payments through Paystack hosted checkout links,
+// which accept card, bank transfer and USSD payments.
+type PaystackProvider struct {
+	secretKey string
+	baseURL   string
+	client    *http.Client
+}
+
+// NewPaystackProvider returns a Paystack client. baseURL and client are optional
+// and exist so tests can point the provider at a local server.
+func NewPaystackProvider(secretKey, baseURL string, client *http.Client) *PaystackProvider {
+	if baseURL == "" {
+		baseURL = defaultPaystackBaseURL
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &PaystackProvider{
+		secretKey: secretKey,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		client:    client,
+	}
+}
+
+func (p *PaystackProvider) Name() string {
+	return "paystack"
+}
+
+type paystackEnvelope struct {
+	Status  bool            `json:"status"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type paystackTransaction struct {
+	ID        int64  `json:"id"`
+	Reference string `json:"reference"`
+	Status    string `json:"status"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+}
+
+func (p *PaystackProvider) CreatePaymentLink(ctx context.Context, req LinkRequest) (*Link, error) {
+	metadata := map[string]any{}
+	for k, v := range req.Metadata {
+		metadata[k] = v
+	}
+	if req.Description != "" {
+		metadata["description"] = req.Description
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"email":     req.Email,
+		"amount":    toMinorUnits(req.Amount),
+		"currency":  req.Currency,
+		"reference": req.Reference,
+		"metadata":  metadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		AuthorizationURL string `json:"authorization_url"`
+		Reference        string `json:"reference"`
+	}
+	if err := p.do(ctx, http.MethodPost, "/transaction/initialize", payload, &data); err != nil {
+		return nil, err
+	}
+
+	return &Link{Reference: data.Reference, CheckoutURL: data.AuthorizationURL}, nil
+}
+
+func (p *PaystackProvider) GetPaymentStatus(ctx context.Context, reference string) (Status, error) {
+	var tx paystackTransaction
+	if err := p.do(ctx, http.MethodGet, "/transaction/verify/"+url.PathEscape(reference), nil, &tx); err != nil {
+		return "", err
+	}
+	return paystackStatus(tx.Status), nil
+}
+
+func (p *PaystackProvider) ParseWebhook(header http.Header, body []byte) (*WebhookEvent, error) {
+	if err := PaystackWebhookVerifier(p.secretKey).Verify(header, body); err != nil {
+		return nil, err
+	}
+
+	var event struct {
+		Event string              `json:"event"`
+		Data  paystackTransaction `json:"data"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("decode paystack webhook: %w", err)
+	}
+
+	return &WebhookEvent{
+		Provider:  p.Name(),
+		EventID:   event.Event + ":" + strconv.FormatInt(event.Data.ID, 10),
+		Type:      event.Event,
+		Reference: event.Data.Reference,
+		Status:    paystackStatus(event.Data.Status),
+		Amount:    fromMinorUnits(event.Data.Amount),
+		Currency:  event.Data.Currency,
+	}, nil
+}
+
+func (p *PaystackProvider) do(ctx context.Context, method, path string, body []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.secretKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var envelope paystackEnvelope
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("paystack %s %s: decode response: %w", method, path, err)
+	}
+	if resp.StatusCode >= 300 || !envelope.Status {
+		return fmt.Errorf("paystack %s %s returned status %d: %s", method, path, resp.StatusCode, envelope.Message)
+	}
+
+	return json.Unmarshal(envelope.Data, out)
+}
+
+func paystackStatus(status string) Status {
+	switch status {
+	case "success":
+		return StatusSucceeded
+	case "failed", "abandoned", "reversed":
+		return StatusFailed
+	default:
+		return StatusPending
+	}
+}
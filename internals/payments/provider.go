@@ -0,0 +1,134 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"tabmate/internals/webhooks"
+
+	"github.com/google/uuid"
+)
+
+// Status is the lifecycle state of a payment as reported by a provider.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// LinkRequest describes a single debtor payment to collect through a provider.
+type LinkRequest struct {
+	Reference   string
+	Amount      float64
+	Currency    string
+	Email       string
+	Description string
+	Metadata    map[string]string
+}
+
+// Link is a hosted checkout page the debtor can open to pay.
+type Link struct {
+	Reference   string
+	CheckoutURL string
+}
+
+// WebhookEvent is a provider notification normalised to the fields TabMate cares about.
+type WebhookEvent struct {
+	Provider  string  `json:"provider"`
+	EventID   string  `json:"event_id"`
+	Type      string  `json:"type"`
+	Reference string  `json:"reference"`
+	Status    Status  `json:"status"`
+	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency"`
+}
+
+// Provider is implemented by every payment backend (bank transfer, payment link, fake).
+type Provider interface {
+	// Name is the stable identifier stored alongside payments and used in webhook URLs.
+	Name() string
+	// CreatePaymentLink registers a payment with the provider and returns a checkout URL.
+	CreatePaymentLink(ctx context.Context, req LinkRequest) (*Link, error)
+	// GetPaymentStatus asks the provider for the current state of a payment.
+	GetPaymentStatus(ctx context.Context, reference string) (Status, error)
+	// ParseWebhook verifies an inbound webhook and returns the event it describes.
+	// Signature failures are reported as webhooks.ErrInvalidSignature.
+	ParseWebhook(header http.Header, body []byte) (*WebhookEvent, error)
+}
+
+// WebhookSource adapts a provider so its callbacks can be registered with a webhooks.Dispatcher.
+// The parsed *WebhookEvent is carried in the dispatched event's Data.
+func WebhookSource(provider Provider) webhooks.Source {
+	return webhookSource{provider: provider}
+}
+
+type webhookSource struct {
+	provider Provider
+}
+
+func (s webhookSource) Name() string {
+	return s.provider.Name()
+}
+
+func (s webhookSource) ParseWebhook(header http.Header, body []byte) (*webhooks.Event, error) {
+	event, err := s.provider.ParseWebhook(header, body)
+	if err != nil {
+		return nil, err
+	}
+	return &webhooks.Event{
+		Source: s.provider.Name(),
+		ID:     event.EventID,
+		Type:   event.Type,
+		Data:   event,
+	}, nil
+}
+
+// NewProviderFromEnv builds the provider selected by PAYMENT_PROVIDER.
+// It returns (nil, nil) when no provider is configured so payments stay optional.
+func NewProviderFromEnv() (Provider, error) {
+	switch strings.ToLower(os.Getenv("PAYMENT_PROVIDER")) {
+	case "":
+		return nil, nil
+	case "paystack":
+		secretKey := os.Getenv("PAYSTACK_SECRET_KEY")
+		if secretKey == "" {
+			return nil, fmt.Errorf("PAYSTACK_SECRET_KEY is required when PAYMENT_PROVIDER=paystack")
+		}
+		return NewPaystackProvider(secretKey, os.Getenv("PAYSTACK_BASE_URL"), nil), nil
+	case "fake":
+		secret := os.Getenv("FAKE_PAYMENT_SECRET")
+		if secret == "" {
+			secret = "fake-payment-secret"
+		}
+		return NewFakeProvider(secret, os.Getenv("FAKE_PAYMENT_BASE_URL")), nil
+	default:
+		return nil, fmt.Errorf("unknown PAYMENT_PROVIDER %q", os.Getenv("PAYMENT_PROVIDER"))
+	}
+}
+
+// Currency returns the ISO currency code payments are requested in.
+func Currency() string {
+	if currency := os.Getenv("PAYMENT_CURRENCY"); currency != "" {
+		return strings.ToUpper(currency)
+	}
+	return "NGN"
+}
+
+// NewReference builds a unique payment reference that embeds the split code.
+func NewReference(splitCode string) string {
+	return fmt.Sprintf("TM-%s-%s", splitCode, uuid.New().String()[:8])
+}
+
+// toMinorUnits converts a decimal amount to the smallest currency unit (kobo, cents).
+func toMinorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromMinorUnits(amount int64) float64 {
+	return float64(amount) / 100
+}
@@ -0,0 +1,103 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DeepLinkScheme is the URL scheme registered by the TabMate mobile app.
+const DeepLinkScheme = "tabmate"
+
+// ErrUnsupportedFormat is returned when a payment request cannot be expressed in a format,
+// e.g. an EPC QR code for a non-EUR amount or a non-IBAN account.
+var ErrUnsupportedFormat = errors.New("payment request not supported in this format")
+
+// PaymentRequest is everything a debtor's banking app needs to pay the payee.
+type PaymentRequest struct {
+	PayeeName     string
+	BankName      string
+	AccountNumber string
+	BIC           string
+	Amount        float64
+	Currency      string
+	Reference     string
+}
+
+// MemberReference is the stable transfer reference for one member's share of a split.
+// It embeds the split code so the payee can match incoming transfers by hand.
+func MemberReference(splitCode string, userID string) string {
+	return fmt.Sprintf("TM-%s-%s", splitCode, strings.ReplaceAll(userID, "-", "")[:8])
+}
+
+// EPCPayload renders the request as an EPC069-12 SEPA credit transfer QR payload,
+// which most European banking apps can scan directly.
+func (r PaymentRequest) EPCPayload() (string, error) {
+	iban := NormalizeIBAN(r.AccountNumber)
+	if !strings.EqualFold(r.Currency, "EUR") || !ValidIBAN(iban) {
+		return "", ErrUnsupportedFormat
+	}
+	if r.Amount < 0.01 || r.Amount > 999999999.99 {
+		return "", fmt.Errorf("%w: amount out of range", ErrUnsupportedFormat)
+	}
+
+	lines := []string{
+		"BCD",
+		"002",
+		"1", // UTF-8
+		"SCT",
+		strings.ToUpper(r.BIC),
+		truncate(r.PayeeName, 70),
+		iban,
+		fmt.Sprintf("EUR%.2f", r.Amount),
+		"", // purpose
+		"", // structured (RF) reference
+		truncate(r.Reference, 140),
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// PaytoURI renders the request as an RFC 8905 payto URI. Only IBAN accounts have a
+// registered payto target type.
+func (r PaymentRequest) PaytoURI() (string, error) {
+	iban := NormalizeIBAN(r.AccountNumber)
+	if !ValidIBAN(iban) {
+		return "", ErrUnsupportedFormat
+	}
+
+	path := "payto://iban/"
+	if r.BIC != "" {
+		path += strings.ToUpper(r.BIC) + "/"
+	}
+	query := url.Values{}
+	query.Set("amount", fmt.Sprintf("%s:%.2f", strings.ToUpper(r.Currency), r.Amount))
+	query.Set("receiver-name", r.PayeeName)
+	query.Set("message", r.Reference)
+	return path + iban + "?" + query.Encode(), nil
+}
+
+// DeepLink renders the request as a TabMate app link that works for any account type.
+func (r PaymentRequest) DeepLink() string {
+	query := url.Values{}
+	query.Set("name", r.PayeeName)
+	query.Set("account", r.AccountNumber)
+	if r.BankName != "" {
+		query.Set("bank", r.BankName)
+	}
+	if r.BIC != "" {
+		query.Set("bic", r.BIC)
+	}
+	query.Set("amount", fmt.Sprintf("%.2f", r.Amount))
+	query.Set("currency", strings.ToUpper(r.Currency))
+	query.Set("reference", r.Reference)
+	return DeepLinkScheme + "://pay?" + query.Encode()
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) > max {
+		return string(runes[:max])
+	}
+	return s
+}
@@ -0,0 +1,128 @@
+package payouts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"tabmate/internals/encryption"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Type is the kind of account a user can be paid into.
+type Type string
+
+const (
+	TypeBankAccount Type = "bank_account"
+	TypeMobileMoney Type = "mobile_money"
+	TypePayPal      Type = "paypal"
+	TypeIBAN        Type = "iban"
+)
+
+// Details holds the fields used by every payout method type; each type uses a subset.
+// It is stored as envelope-encrypted JSON in payout_methods.details.
+type Details struct {
+	BankName      string `json:"bank_name,omitempty"`
+	BankCode      string `json:"bank_code,omitempty"`
+	Country       string `json:"country,omitempty"`
+	AccountName   string `json:"account_name,omitempty"`
+	AccountNumber string `json:"account_number,omitempty"`
+	Provider      string `json:"provider,omitempty"`
+	PhoneNumber   string `json:"phone_number,omitempty"`
+	Handle        string `json:"handle,omitempty"`
+	IBAN          string `json:"iban,omitempty"`
+	BIC           string `json:"bic,omitempty"`
+}
+
+// Method is a decrypted payout method as returned by the API.
+type Method struct {
+	ID        string      `json:"id"`
+	UserID    pgtype.UUID `json:"-"`
+	Type      Type        `json:"type"`
+	Label     string      `json:"label"`
+	Details   Details     `json:"details"`
+	IsDefault bool        `json:"is_default"`
+	Masked    bool        `json:"masked"`
+}
+
+// Mask hides all but the last four characters of account identifiers.
+func (m Method) Mask() Method {
+	m.Details.AccountNumber = encryption.Mask(m.Details.AccountNumber)
+	m.Details.PhoneNumber = encryption.Mask(m.Details.PhoneNumber)
+	m.Details.IBAN = encryption.Mask(m.Details.IBAN)
+	m.Details.Handle = encryption.Mask(m.Details.Handle)
+	m.Masked = true
+	return m
+}
+
+// Payee returns the fields a payment request needs to pay into this method.
+func (m Method) Payee() (name, bank, account, bic string) {
+	d := m.Details
+	switch m.Type {
+	case TypeIBAN:
+		return d.AccountName, d.BankName, d.IBAN, d.BIC
+	case TypeMobileMoney:
+		return d.AccountName, d.Provider, d.PhoneNumber, ""
+	case TypePayPal:
+		return d.AccountName, "PayPal", "paypal.me/" + d.Handle, ""
+	default:
+		return d.AccountName, d.BankName, d.AccountNumber, ""
+	}
+}
+
+// Seal encrypts details for storage.
+func Seal(ctx context.Context, cipher *encryption.Envelope, d Details) (string, error) {
+	raw, err := json.Marshal(d)
+	if err != nil {
+		return "", err
+	}
+	return cipher.Encrypt(ctx, string(raw))
+}
+
+// Load decrypts a stored payout method.
+func Load(ctx context.Context, cipher *encryption.Envelope, row tabmate.PayoutMethods) (Method, error) {
+	raw, err := cipher.Decrypt(ctx, row.Details)
+	if err != nil {
+		return Method{}, err
+	}
+	var details Details
+	if err := json.Unmarshal([]byte(raw), &details); err != nil {
+		return Method{}, err
+	}
+	return Method{
+		ID:        uuid.UUID(row.ID.Bytes).String(),
+		UserID:    row.UserID,
+		Type:      Type(row.MethodType),
+		Label:     row.Label.String,
+		Details:   details,
+		IsDefault: row.IsDefault,
+	}, nil
+}
+
+// ForSplit returns the method debtors of a split should pay into: the one the host
+// picked for the split, otherwise the host's default. It returns nil when the host
+// has no payout methods.
+func ForSplit(ctx context.Context, queries tabmate.Querier, cipher *encryption.Envelope, split tabmate.Splits) (*Method, error) {
+	var row tabmate.PayoutMethods
+	var err error
+	if split.PayoutMethodID.Valid {
+		row, err = queries.GetPayoutMethod(ctx, split.PayoutMethodID)
+	} else {
+		row, err = queries.GetDefaultPayoutMethod(ctx, split.CreatedBy)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	method, err := Load(ctx, cipher, row)
+	if err != nil {
+		return nil, err
+	}
+	return &method, nil
+}
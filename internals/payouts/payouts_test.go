@@ -0,0 +1,35 @@
+package payouts
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		typ     Type
+		details Details
+		ok      bool
+	}{
+		{"nuban", TypeBankAccount, Details{BankName: "GTBank", AccountName: "Ada Obi", AccountNumber: "0123456789"}, true},
+		{"nuban too short", TypeBankAccount, Details{BankName: "GTBank", AccountName: "Ada Obi", AccountNumber: "12345678"}, false},
+		{"nuban check digit", TypeBankAccount, Details{BankName: "GTBank", BankCode: "058", AccountName: "Ada Obi", AccountNumber: "0000000018"}, true},
+		{"nuban bad check digit", TypeBankAccount, Details{BankName: "GTBank", BankCode: "058", AccountName: "Ada Obi", AccountNumber: "0000000012"}, false},
+		{"foreign account", TypeBankAccount, Details{BankName: "Chase", AccountName: "Ada Obi", AccountNumber: "123456789012", Country: "us"}, true},
+		{"mobile money", TypeMobileMoney, Details{Provider: "M-Pesa", PhoneNumber: "+254 712 345 678"}, true},
+		{"mobile money local number", TypeMobileMoney, Details{Provider: "mtn", PhoneNumber: "0712345678"}, false},
+		{"paypal.me", TypePayPal, Details{Handle: "https://paypal.me/adaobi"}, true},
+		{"paypal email", TypePayPal, Details{Handle: "ada@example.com"}, true},
+		{"paypal junk", TypePayPal, Details{Handle: "ada obi!"}, false},
+		{"iban", TypeIBAN, Details{AccountName: "Ada Obi", IBAN: "de89 3704 0044 0532 0130 00", BIC: "cobadeffxxx"}, true},
+		{"iban checksum", TypeIBAN, Details{AccountName: "Ada Obi", IBAN: "DE89370400440532013001"}, false},
+		{"unknown type", Type("cash"), Details{}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.typ, &tc.details)
+			if (err == nil) != tc.ok {
+				t.Fatalf("Validate = %v, want ok=%v", err, tc.ok)
+			}
+		})
+	}
+}
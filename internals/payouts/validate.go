@@ -0,0 +1,126 @@
+package payouts
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"tabmate/internals/payments"
+)
+
+var (
+	digitsOnly = regexp.MustCompile(`^[0-9]+$`)
+	e164       = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+	paypalMe   = regexp.MustCompile(`^[A-Za-z0-9]{1,20}$`)
+	bicFormat  = regexp.MustCompile(`^[A-Z]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+	separators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+)
+
+// Validate normalises d in place and checks it against the rules for t.
+// Every error it returns is safe to show to the user.
+func Validate(t Type, d *Details) error {
+	d.AccountName = strings.TrimSpace(d.AccountName)
+	d.BankName = strings.TrimSpace(d.BankName)
+
+	switch t {
+	case TypeBankAccount:
+		return validateBankAccount(d)
+	case TypeMobileMoney:
+		return validateMobileMoney(d)
+	case TypePayPal:
+		return validatePayPal(d)
+	case TypeIBAN:
+		return validateIBAN(d)
+	default:
+		return fmt.Errorf("type must be one of bank_account, mobile_money, paypal, iban")
+	}
+}
+
+func validateBankAccount(d *Details) error {
+	d.Country = strings.ToUpper(strings.TrimSpace(d.Country))
+	if d.Country == "" {
+		d.Country = "NG"
+	}
+	d.AccountNumber = separators.Replace(d.AccountNumber)
+	d.BankCode = strings.TrimSpace(d.BankCode)
+
+	if d.BankName == "" || d.AccountName == "" {
+		return errors.New("bank_name and account_name are required")
+	}
+
+	if d.Country == "NG" {
+		if len(d.AccountNumber) != 10 || !digitsOnly.MatchString(d.AccountNumber) {
+			return errors.New("Nigerian account numbers (NUBAN) must be exactly 10 digits")
+		}
+		if len(d.BankCode) == 3 && digitsOnly.MatchString(d.BankCode) && !validNUBAN(d.BankCode, d.AccountNumber) {
+			return errors.New("account_number does not match bank_code (NUBAN check digit failed)")
+		}
+		return nil
+	}
+
+	if len(d.AccountNumber) < 4 || len(d.AccountNumber) > 34 {
+		return errors.New("account_number must be between 4 and 34 characters")
+	}
+	return nil
+}
+
+// validNUBAN applies the CBN check digit algorithm to a 3-digit bank code and a
+// 10-digit account number (9-digit serial + check digit).
+func validNUBAN(bankCode, accountNumber string) bool {
+	weights := []int{3, 7, 3, 3, 7, 3, 3, 7, 3, 3, 7, 3}
+	digits := bankCode + accountNumber[:9]
+
+	sum := 0
+	for i, r := range digits {
+		sum += int(r-'0') * weights[i]
+	}
+	check := (10 - sum%10) % 10
+	return int(accountNumber[9]-'0') == check
+}
+
+func validateMobileMoney(d *Details) error {
+	d.Provider = strings.ToLower(strings.TrimSpace(d.Provider))
+	d.PhoneNumber = separators.Replace(d.PhoneNumber)
+
+	if d.Provider == "" {
+		return errors.New("provider is required for mobile money (e.g. mpesa, mtn, airtel)")
+	}
+	if !e164.MatchString(d.PhoneNumber) {
+		return errors.New("phone_number must be in international format, e.g. +254712345678")
+	}
+	return nil
+}
+
+func validatePayPal(d *Details) error {
+	handle := strings.TrimSpace(d.Handle)
+	handle = strings.TrimPrefix(handle, "https://")
+	handle = strings.TrimPrefix(handle, "www.")
+	handle = strings.TrimPrefix(handle, "paypal.me/")
+	handle = strings.TrimPrefix(handle, "@")
+	d.Handle = handle
+
+	if paypalMe.MatchString(handle) {
+		return nil
+	}
+	if addr, err := mail.ParseAddress(handle); err == nil && addr.Address == handle {
+		return nil
+	}
+	return errors.New("handle must be a paypal.me username or PayPal email address")
+}
+
+func validateIBAN(d *Details) error {
+	d.IBAN = payments.NormalizeIBAN(d.IBAN)
+	d.BIC = strings.ToUpper(strings.TrimSpace(d.BIC))
+
+	if d.AccountName == "" {
+		return errors.New("account_name is required")
+	}
+	if !payments.ValidIBAN(d.IBAN) {
+		return errors.New("iban is not valid (checksum failed)")
+	}
+	if d.BIC != "" && !bicFormat.MatchString(d.BIC) {
+		return errors.New("bic must be 8 or 11 characters")
+	}
+	return nil
+}
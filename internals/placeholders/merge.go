@@ -0,0 +1,74 @@
+// Package placeholders handles members who stand in for someone without an account.
+package placeholders
+
+import (
+	"context"
+	"errors"
+
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrOverlap is returned by Merge when the user already shares a split or table with
+// the placeholder, since one of the two memberships would have to be thrown away.
+var ErrOverlap = errors.New("user is already in a split or table with this placeholder")
+
+// Merge hands everything a placeholder holds — split and table memberships with their
+// balances, item claims, added items, activity and queued notifications — to userID,
+// then deletes the placeholder. Run it inside a transaction.
+func Merge(ctx context.Context, queries tabmate.Querier, placeholderID, userID pgtype.UUID) error {
+	shared, err := queries.CountSharedMemberships(ctx, tabmate.CountSharedMembershipsParams{
+		PlaceholderID: placeholderID,
+		UserID:        userID,
+	})
+	if err != nil {
+		return err
+	}
+	if shared > 0 {
+		return ErrOverlap
+	}
+	if err := queries.MovePlaceholderSplitMemberships(ctx, tabmate.MovePlaceholderSplitMembershipsParams{
+		UserID:        userID,
+		PlaceholderID: placeholderID,
+	}); err != nil {
+		return err
+	}
+	if err := queries.MovePlaceholderItemClaims(ctx, tabmate.MovePlaceholderItemClaimsParams{
+		UserID:        userID,
+		PlaceholderID: placeholderID,
+	}); err != nil {
+		return err
+	}
+	if err := queries.MovePlaceholderTableMemberships(ctx, tabmate.MovePlaceholderTableMembershipsParams{
+		UserID:        userID,
+		PlaceholderID: placeholderID,
+	}); err != nil {
+		return err
+	}
+	if err := queries.MovePlaceholderItems(ctx, tabmate.MovePlaceholderItemsParams{
+		UserID:        userID,
+		PlaceholderID: placeholderID,
+	}); err != nil {
+		return err
+	}
+	if err := queries.MovePlaceholderSplitItems(ctx, tabmate.MovePlaceholderSplitItemsParams{
+		UserID:        userID,
+		PlaceholderID: placeholderID,
+	}); err != nil {
+		return err
+	}
+	if err := queries.MovePlaceholderActivityEvents(ctx, tabmate.MovePlaceholderActivityEventsParams{
+		UserID:        userID,
+		PlaceholderID: placeholderID,
+	}); err != nil {
+		return err
+	}
+	if err := queries.MovePlaceholderNotifications(ctx, tabmate.MovePlaceholderNotificationsParams{
+		UserID:        userID,
+		PlaceholderID: placeholderID,
+	}); err != nil {
+		return err
+	}
+	return queries.DeletePlaceholderUser(ctx, placeholderID)
+}
@@ -0,0 +1,148 @@
+package placeholders
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	placeholderID = pgtype.UUID{Bytes: [16]byte{1}, Valid: true}
+	userID        = pgtype.UUID{Bytes: [16]byte{2}, Valid: true}
+)
+
+// mergeQueries records the queries Merge runs, in order. Methods Merge does not call
+// are left to the embedded nil interface.
+type mergeQueries struct {
+	tabmate.Querier
+	t      *testing.T
+	shared int64
+	failOn string
+	calls  []string
+	// actors holds the actor of each activity event, which the database deletes
+	// along with its user.
+	actors []pgtype.UUID
+}
+
+// call records name and checks it was given the right users.
+func (q *mergeQueries) call(name string, to, from pgtype.UUID) error {
+	q.calls = append(q.calls, name)
+	if to != userID || from != placeholderID {
+		q.t.Errorf("%s moved from %v to %v", name, from, to)
+	}
+	if name == q.failOn {
+		return errors.New("connection reset")
+	}
+	return nil
+}
+
+func (q *mergeQueries) CountSharedMemberships(ctx context.Context, arg tabmate.CountSharedMembershipsParams) (int64, error) {
+	return q.shared, q.call("CountSharedMemberships", arg.UserID, arg.PlaceholderID)
+}
+
+func (q *mergeQueries) MovePlaceholderSplitMemberships(ctx context.Context, arg tabmate.MovePlaceholderSplitMembershipsParams) error {
+	return q.call("MovePlaceholderSplitMemberships", arg.UserID, arg.PlaceholderID)
+}
+
+func (q *mergeQueries) MovePlaceholderItemClaims(ctx context.Context, arg tabmate.MovePlaceholderItemClaimsParams) error {
+	return q.call("MovePlaceholderItemClaims", arg.UserID, arg.PlaceholderID)
+}
+
+func (q *mergeQueries) MovePlaceholderTableMemberships(ctx context.Context, arg tabmate.MovePlaceholderTableMembershipsParams) error {
+	return q.call("MovePlaceholderTableMemberships", arg.UserID, arg.PlaceholderID)
+}
+
+func (q *mergeQueries) MovePlaceholderItems(ctx context.Context, arg tabmate.MovePlaceholderItemsParams) error {
+	return q.call("MovePlaceholderItems", arg.UserID, arg.PlaceholderID)
+}
+
+func (q *mergeQueries) MovePlaceholderSplitItems(ctx context.Context, arg tabmate.MovePlaceholderSplitItemsParams) error {
+	return q.call("MovePlaceholderSplitItems", arg.UserID, arg.PlaceholderID)
+}
+
+func (q *mergeQueries) MovePlaceholderActivityEvents(ctx context.Context, arg tabmate.MovePlaceholderActivityEventsParams) error {
+	if err := q.call("MovePlaceholderActivityEvents", arg.UserID, arg.PlaceholderID); err != nil {
+		return err
+	}
+	for i, actor := range q.actors {
+		if actor == arg.PlaceholderID {
+			q.actors[i] = arg.UserID
+		}
+	}
+	return nil
+}
+
+func (q *mergeQueries) MovePlaceholderNotifications(ctx context.Context, arg tabmate.MovePlaceholderNotificationsParams) error {
+	return q.call("MovePlaceholderNotifications", arg.UserID, arg.PlaceholderID)
+}
+
+func (q *mergeQueries) DeletePlaceholderUser(ctx context.Context, id pgtype.UUID) error {
+	if err := q.call("DeletePlaceholderUser", userID, id); err != nil {
+		return err
+	}
+	q.actors = slices.DeleteFunc(q.actors, func(actor pgtype.UUID) bool { return actor == id })
+	return nil
+}
+
+var mergeSequence = []string{
+	"CountSharedMemberships",
+	"MovePlaceholderSplitMemberships",
+	"MovePlaceholderItemClaims",
+	"MovePlaceholderTableMemberships",
+	"MovePlaceholderItems",
+	"MovePlaceholderSplitItems",
+	"MovePlaceholderActivityEvents",
+	"MovePlaceholderNotifications",
+	"DeletePlaceholderUser",
+}
+
+func TestMergeMovesEverythingBeforeDeleting(t *testing.T) {
+	q := &mergeQueries{t: t}
+	if err := Merge(context.Background(), q, placeholderID, userID); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if !slices.Equal(q.calls, mergeSequence) {
+		t.Errorf("calls = %v, want %v", q.calls, mergeSequence)
+	}
+}
+
+func TestMergeKeepsActivityHistory(t *testing.T) {
+	other := pgtype.UUID{Bytes: [16]byte{3}, Valid: true}
+	q := &mergeQueries{t: t, actors: []pgtype.UUID{placeholderID, other, placeholderID}}
+	if err := Merge(context.Background(), q, placeholderID, userID); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	want := []pgtype.UUID{userID, other, userID}
+	if !slices.Equal(q.actors, want) {
+		t.Errorf("actors = %v, want %v", q.actors, want)
+	}
+}
+
+func TestMergeRefusesOverlap(t *testing.T) {
+	q := &mergeQueries{t: t, shared: 1}
+	if err := Merge(context.Background(), q, placeholderID, userID); !errors.Is(err, ErrOverlap) {
+		t.Fatalf("Merge error = %v, want ErrOverlap", err)
+	}
+	if !slices.Equal(q.calls, mergeSequence[:1]) {
+		t.Errorf("calls = %v, want nothing moved", q.calls)
+	}
+}
+
+func TestMergeStopsAtFirstError(t *testing.T) {
+	for i, failing := range mergeSequence {
+		t.Run(failing, func(t *testing.T) {
+			q := &mergeQueries{t: t, failOn: failing}
+			if err := Merge(context.Background(), q, placeholderID, userID); err == nil {
+				t.Fatal("Merge succeeded")
+			}
+			if !slices.Equal(q.calls, mergeSequence[:i+1]) {
+				t.Errorf("calls = %v, want %v", q.calls, mergeSequence[:i+1])
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"tabmate/internals/notifications"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultInterval is how often the scheduler looks for due reminders.
+const DefaultInterval = 5 * time.Minute
+
+// Scheduler queues the automatic payment reminders configured by split hosts on the
+// notification outbox.
+type Scheduler struct {
+	pool     *pgxpool.Pool
+	queries  tabmate.Querier
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewScheduler(pool *pgxpool.Pool) *Scheduler {
+	return &Scheduler{
+		pool:     pool,
+		queries:  tabmate.New(pool),
+		interval: DefaultInterval,
+		now:      time.Now,
+	}
+}
+
+// Run processes due reminders every interval until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunOnce(ctx); err != nil {
+			log.Printf("[reminders] run failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce queues every reminder that is currently due.
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	now := s.now()
+	due, err := s.queries.ListDueSplitReminders(ctx, pgtype.Timestamptz{Time: now, Valid: true})
+	if err != nil {
+		return fmt.Errorf("list due reminders: %w", err)
+	}
+
+	for _, r := range due {
+		// Reminders that fall due in the member's quiet hours wait until they end
+		// instead of being dropped.
+		prefs, err := notifications.LoadPreferences(ctx, s.queries, r.UserID)
+		if err != nil {
+			log.Printf("[reminders] failed to load preferences for split %s: %v", r.SplitCode, err)
+			continue
+		}
+		if _, quiet := prefs.QuietUntil(now); quiet {
+			continue
+		}
+
+		if err := s.queue(ctx, r); err != nil {
+			log.Printf("[reminders] failed to queue reminder for split %s: %v", r.SplitCode, err)
+		}
+	}
+
+	return nil
+}
+
+// queue counts the reminder against the member and puts it on the outbox in one
+// transaction. The count is conditional on the value ListDueSplitReminders read, so a
+// second worker (or a slow previous run) that saw the same row cannot queue it again.
+func (s *Scheduler) queue(ctx context.Context, r tabmate.ListDueSplitRemindersRow) error {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	q := tabmate.New(tx)
+	claimed, err := q.ClaimSplitReminder(ctx, tabmate.ClaimSplitReminderParams{
+		SplitID:       r.SplitID,
+		UserID:        r.UserID,
+		RemindersSent: r.RemindersSent,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	amount, _ := r.AmountOwed.Float64Value()
+	title, body := Message(int(claimed.RemindersSent), int(r.MaxReminders), r.HostName.String, r.SplitName, amount.Float64)
+	// A member who turned reminders off still uses up the schedule, so they are not
+	// reconsidered on every run.
+	err = notifications.Enqueue(ctx, q, notifications.Notification{
+		UserID:  r.UserID,
+		SplitID: r.SplitID,
+		Kind:    notifications.KindPaymentReminder,
+		Title:   title,
+		Body:    body,
+		Data: map[string]string{
+			"splitCode": r.SplitCode,
+			"splitName": r.SplitName,
+			"amount":    fmt.Sprintf("%.2f", amount.Float64),
+			"type":      "payment_reminder",
+		},
+	})
+	if err != nil && !errors.Is(err, notifications.ErrOptedOut) {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Message returns the notification copy for the nth reminder (1-based) out of max.
+// The tone firms up as reminders go unanswered, and the last one says so.
+func Message(n, max int, hostName, splitName string, amount float64) (title, body string) {
+	if hostName == "" {
+		hostName = "The host"
+	}
+
+	switch {
+	case n >= max:
+		return "Final reminder ⏰", fmt.Sprintf(
+			"This is the last reminder: you still owe %s $%.2f for \"%s\". Please settle up today.",
+			hostName, amount, splitName,
+		)
+	case n == 1:
+		return "Friendly reminder 💸", fmt.Sprintf(
+			"Just a nudge — your share of \"%s\" is $%.2f. %s will appreciate it!",
+			splitName, amount, hostName,
+		)
+	default:
+		return "Payment still pending", fmt.Sprintf(
+			"You still owe %s $%.2f for \"%s\". Please pay when you can.",
+			hostName, amount, splitName,
+		)
+	}
+}
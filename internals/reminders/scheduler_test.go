@@ -0,0 +1,22 @@
+package reminders
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMessageEscalates(t *testing.T) {
+	first, _ := Message(1, 3, "Ada", "Dinner", 12.5)
+	middle, _ := Message(2, 3, "Ada", "Dinner", 12.5)
+	last, body := Message(3, 3, "Ada", "Dinner", 12.5)
+
+	if first == middle || middle == last {
+		t.Fatalf("expected distinct titles, got %q, %q, %q", first, middle, last)
+	}
+	if !strings.HasPrefix(last, "Final reminder") {
+		t.Errorf("last title = %q, want final reminder", last)
+	}
+	if !strings.Contains(body, "$12.50") || !strings.Contains(body, "Ada") {
+		t.Errorf("last body = %q, missing amount or host", body)
+	}
+}
@@ -0,0 +1,68 @@
+// Package roles decides what a split or table member may do. The host can do
+// everything; a co-host can do what the host granted them; guests can only act on
+// their own share.
+package roles
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Roles stored in split_members.role and table_members.role.
+const (
+	Host   = "host"
+	CoHost = "cohost"
+	Guest  = "guest"
+)
+
+// Permission is something the host can let a co-host do. Anything without one, such
+// as deleting a split, choosing where money is paid or handing over the host role,
+// stays with the host.
+type Permission string
+
+const (
+	ManageMembers   Permission = "manage_members"   // add and remove members and placeholders, invites and join codes
+	ManageItems     Permission = "manage_items"     // receipts, items, VAT and menus, and claims for placeholders
+	ConfirmPayments Permission = "confirm_payments" // see and confirm other members' payments
+	SendReminders   Permission = "send_reminders"   // reminders, reminder policy and notification history
+	Close           Permission = "close"            // close the split or table
+)
+
+// All lists every permission, in the order they are shown. A co-host appointed without
+// a list gets all of them.
+var All = []Permission{ManageMembers, ManageItems, ConfirmPayments, SendReminders, Close}
+
+// Can reports whether a member with role and granted permissions may do p.
+func Can(role string, granted []string, p Permission) bool {
+	switch role {
+	case Host:
+		return true
+	case CoHost:
+		return slices.Contains(granted, string(p))
+	}
+	return false
+}
+
+// ParsePermissions checks a list of permission names from a request and returns it in
+// canonical order without duplicates. A nil list means every permission; an empty one
+// means none.
+func ParsePermissions(names []string) ([]string, error) {
+	if names == nil {
+		names = make([]string, len(All))
+		for i, p := range All {
+			names[i] = string(p)
+		}
+	}
+	for _, name := range names {
+		if !slices.Contains(All, Permission(name)) {
+			return nil, fmt.Errorf("unknown permission %q", name)
+		}
+	}
+	granted := []string{}
+	for _, p := range All {
+		if slices.Contains(names, string(p)) {
+			granted = append(granted, string(p))
+		}
+	}
+	return granted, nil
+}
@@ -0,0 +1,47 @@
+package roles
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCan(t *testing.T) {
+	granted := []string{string(ConfirmPayments)}
+	for _, tc := range []struct {
+		role    string
+		granted []string
+		p       Permission
+		want    bool
+	}{
+		{Host, nil, Close, true},
+		{CoHost, granted, ConfirmPayments, true},
+		{CoHost, granted, Close, false},
+		{Guest, granted, ConfirmPayments, false}, // stale permissions on a demoted member count for nothing
+		{"member", nil, ManageItems, false},
+	} {
+		if got := Can(tc.role, tc.granted, tc.p); got != tc.want {
+			t.Errorf("Can(%q, %v, %q) = %v, want %v", tc.role, tc.granted, tc.p, got, tc.want)
+		}
+	}
+}
+
+func TestParsePermissions(t *testing.T) {
+	got, err := ParsePermissions([]string{"close", "manage_members", "close"})
+	if err != nil || !slices.Equal(got, []string{"manage_members", "close"}) {
+		t.Fatalf("ParsePermissions = %v, %v", got, err)
+	}
+
+	got, err = ParsePermissions(nil)
+	if err != nil || len(got) != len(All) {
+		t.Fatalf("ParsePermissions(nil) = %v, %v; want every permission", got, err)
+	}
+
+	got, err = ParsePermissions([]string{})
+	if err != nil || got == nil || len(got) != 0 {
+		t.Fatalf("ParsePermissions([]) = %#v, %v; want an empty list", got, err)
+	}
+
+	if _, err := ParsePermissions([]string{"delete_split"}); err == nil {
+		t.Fatal("ParsePermissions accepted an unknown permission")
+	}
+}
@@ -0,0 +1,92 @@
+// Package sms sends the text messages used to verify phone numbers.
+package sms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const DefaultTwilioBaseURL = "https://api.twilio.com"
+
+// Sender sends a text message to a phone number in E.164 form.
+type Sender interface {
+	Send(ctx context.Context, to, body string) error
+}
+
+// NewSenderFromEnv picks a sender from SMS_PROVIDER: "twilio" (TWILIO_ACCOUNT_SID,
+// TWILIO_AUTH_TOKEN, TWILIO_FROM_NUMBER) or "log", which only writes messages to the
+// server log for local development. It returns nil if SMS_PROVIDER is not set, in
+// which case phone numbers can't be verified.
+func NewSenderFromEnv() (Sender, error) {
+	switch provider := os.Getenv("SMS_PROVIDER"); provider {
+	case "":
+		return nil, nil
+	case "log":
+		return Log{}, nil
+	case "twilio":
+		t := NewTwilio(os.Getenv("TWILIO_ACCOUNT_SID"), os.Getenv("TWILIO_AUTH_TOKEN"), os.Getenv("TWILIO_FROM_NUMBER"))
+		if t.AccountSID == "" || t.AuthToken == "" || t.From == "" {
+			return nil, fmt.Errorf("SMS_PROVIDER=twilio needs TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_FROM_NUMBER")
+		}
+		return t, nil
+	default:
+		return nil, fmt.Errorf("unknown SMS_PROVIDER %q", provider)
+	}
+}
+
+// Log writes messages to the server log instead of sending them.
+type Log struct{}
+
+func (Log) Send(ctx context.Context, to, body string) error {
+	log.Printf("[sms] to %s: %s", to, body)
+	return nil
+}
+
+// Twilio sends messages through Twilio's Messages API. BaseURL can point at a local
+// fake server in tests.
+type Twilio struct {
+	BaseURL    string
+	AccountSID string
+	AuthToken  string
+	From       string
+	HTTPClient *http.Client
+}
+
+func NewTwilio(accountSID, authToken, from string) *Twilio {
+	return &Twilio{
+		BaseURL:    DefaultTwilioBaseURL,
+		AccountSID: accountSID,
+		AuthToken:  authToken,
+		From:       from,
+		HTTPClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (t *Twilio) Send(ctx context.Context, to, body string) error {
+	form := url.Values{"To": {to}, "From": {t.From}, "Body": {body}}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", strings.TrimRight(t.BaseURL, "/"), url.PathEscape(t.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(t.AccountSID, t.AuthToken)
+
+	resp, err := t.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("twilio: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// DefaultLocalDir is where the local store keeps files when STORAGE_LOCAL_DIR is
+	// not set.
+	DefaultLocalDir = "data/blobs"
+
+	// LocalRoute is the path the API serves a LocalStore's files under.
+	LocalRoute = "/blobs"
+)
+
+// LocalStore is a BlobStore that keeps objects as files under a directory. It is meant
+// for development and tests; the API serves the files itself at LocalRoute.
+type LocalStore struct {
+	root      string
+	publicURL string
+}
+
+func NewLocalStore(root, publicURL string) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("create %s: %w", root, err)
+	}
+	return &LocalStore{root: root, publicURL: publicURL}, nil
+}
+
+// Root is the directory objects are stored under.
+func (s *LocalStore) Root() string {
+	return s.root
+}
+
+func (s *LocalStore) Upload(ctx context.Context, key string, body []byte, mediaType string) (*UploadedObject, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	// Write to a temporary file and rename it, so the file being served is never
+	// half-written.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+
+	return &UploadedObject{Key: key, URL: publicObjectURL(s.publicURL, key)}, nil
+}
+
+// Delete removes an object. Deleting a key that doesn't exist is not an error.
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path returns the file for key, refusing keys that would reach outside root.
+func (s *LocalStore) path(key string) (string, error) {
+	rel := filepath.FromSlash(key)
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(s.root, rel), nil
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Config describes a bucket on S3 or a service compatible with it.
+type S3Config struct {
+	// Endpoint is the service's base URL. Empty means Amazon S3 in Region.
+	Endpoint string
+	Region   string
+	Bucket   string
+	// AccessKeyID and SecretAccessKey are optional; without them the AWS default
+	// credential chain is used.
+	AccessKeyID     string
+	SecretAccessKey string
+	// PublicURL is where clients fetch objects: the bucket's public URL or a custom
+	// domain in front of it.
+	PublicURL string
+	// UsePathStyle addresses the bucket as a path of Endpoint rather than a subdomain.
+	UsePathStyle bool
+}
+
+// S3Store is a BlobStore backed by an S3-compatible bucket: Amazon S3, Cloudflare R2
+// or MinIO.
+type S3Store struct {
+	bucket    string
+	publicURL string
+	client    *s3.Client
+}
+
+func NewS3Store(ctx context.Context, cfg S3Config) (*S3Store, error) {
+	if cfg.Region == "" || cfg.Bucket == "" || cfg.PublicURL == "" {
+		return nil, fmt.Errorf("S3_REGION, S3_BUCKET and S3_PUBLIC_URL are required")
+	}
+
+	opts := []func(*config.LoadOptions) error{config.WithRegion(cfg.Region)}
+	if cfg.AccessKeyID != "" || cfg.SecretAccessKey != "" {
+		opts = append(opts, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(cfg.AccessKeyID, cfg.SecretAccessKey, "")))
+	}
+	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+		o.UsePathStyle = cfg.UsePathStyle
+	})
+
+	return &S3Store{bucket: cfg.Bucket, publicURL: cfg.PublicURL, client: client}, nil
+}
+
+// NewR2Store returns a store for a Cloudflare R2 bucket.
+func NewR2Store(ctx context.Context, accountID, accessKeyID, secretAccessKey, bucket, publicURL string) (*S3Store, error) {
+	if accountID == "" || accessKeyID == "" || secretAccessKey == "" || bucket == "" || publicURL == "" {
+		return nil, fmt.Errorf("R2_ACCOUNT_ID, R2_ACCESS_KEY_ID, R2_SECRET_ACCESS_KEY, R2_BUCKET, and R2_PUBLIC_URL are required")
+	}
+
+	return NewS3Store(ctx, S3Config{
+		Endpoint:        fmt.Sprintf("https://%s.r2.cloudflarestorage.com", accountID),
+		Region:          "auto",
+		Bucket:          bucket,
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		PublicURL:       publicURL,
+	})
+}
+
+func (s *S3Store) Upload(ctx context.Context, key string, body []byte, mediaType string) (*UploadedObject, error) {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String(mediaType),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &UploadedObject{Key: key, URL: publicObjectURL(s.publicURL, key)}, nil
+}
+
+// Delete removes an object. Deleting a key that doesn't exist is not an error.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
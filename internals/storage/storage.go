@@ -0,0 +1,105 @@
+// Package storage keeps uploaded files such as receipt images and avatars in a blob
+// store that clients read from directly by URL.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// BlobStore stores objects under keys like "receipts/splits/<code>/<id>.jpg".
+type BlobStore interface {
+	// Upload stores body under key, replacing any object already there, and returns
+	// the URL clients can fetch it from.
+	Upload(ctx context.Context, key string, body []byte, mediaType string) (*UploadedObject, error)
+	// Delete removes an object. Deleting a key that doesn't exist is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+type UploadedObject struct {
+	Key string
+	URL string
+}
+
+// ErrNotConfigured is returned by code that needs a blob store when none is set up.
+var ErrNotConfigured = errors.New("file storage is not configured")
+
+// NewBlobStoreFromEnv picks a store from STORAGE_BACKEND:
+//
+//   - "r2": Cloudflare R2, from R2_ACCOUNT_ID, R2_ACCESS_KEY_ID, R2_SECRET_ACCESS_KEY,
+//     R2_BUCKET and R2_PUBLIC_URL.
+//   - "s3": Amazon S3, from S3_REGION, S3_BUCKET and S3_PUBLIC_URL. S3_ACCESS_KEY_ID and
+//     S3_SECRET_ACCESS_KEY are optional; without them the AWS default credential chain
+//     is used. S3_ENDPOINT points it at another S3-compatible service.
+//   - "minio": MinIO or another self-hosted S3-compatible service, from the same S3_*
+//     variables with S3_ENDPOINT and the keys required.
+//   - "local": files on disk under STORAGE_LOCAL_DIR, served by the API itself at
+//     LocalRoute, for development and tests.
+//
+// If STORAGE_BACKEND is not set, R2 is used when R2_ACCOUNT_ID is, for deployments
+// configured before the other backends existed. Otherwise it returns nil, and uploads
+// fail with ErrNotConfigured.
+func NewBlobStoreFromEnv(ctx context.Context) (BlobStore, error) {
+	backend := os.Getenv("STORAGE_BACKEND")
+	if backend == "" && os.Getenv("R2_ACCOUNT_ID") != "" {
+		backend = "r2"
+	}
+
+	switch backend {
+	case "":
+		return nil, nil
+	case "r2":
+		store, err := NewR2Store(ctx, os.Getenv("R2_ACCOUNT_ID"), os.Getenv("R2_ACCESS_KEY_ID"), os.Getenv("R2_SECRET_ACCESS_KEY"), os.Getenv("R2_BUCKET"), os.Getenv("R2_PUBLIC_URL"))
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "s3", "minio":
+		cfg := S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PublicURL:       os.Getenv("S3_PUBLIC_URL"),
+		}
+		if backend == "minio" {
+			if cfg.Endpoint == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+				return nil, fmt.Errorf("STORAGE_BACKEND=minio needs S3_ENDPOINT, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY")
+			}
+			// MinIO serves buckets as paths unless it is set up with a domain.
+			cfg.UsePathStyle = true
+			if cfg.Region == "" {
+				cfg.Region = "us-east-1"
+			}
+		}
+		store, err := NewS3Store(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = DefaultLocalDir
+		}
+		publicURL := os.Getenv("STORAGE_PUBLIC_URL")
+		if publicURL == "" {
+			publicURL = "http://localhost:8080" + LocalRoute
+		}
+		store, err := NewLocalStore(dir, publicURL)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", backend)
+	}
+}
+
+func publicObjectURL(publicURL, key string) string {
+	return fmt.Sprintf("%s/%s", strings.TrimRight(publicURL, "/"), strings.TrimLeft(key, "/"))
+}
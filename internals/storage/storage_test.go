@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store, err := NewLocalStore(root, "http://localhost:8080/blobs/")
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+
+	object, err := store.Upload(ctx, "receipts/splits/ABC/1.jpg", []byte("jpeg"), "image/jpeg")
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if object.URL != "http://localhost:8080/blobs/receipts/splits/ABC/1.jpg" {
+		t.Errorf("URL = %q", object.URL)
+	}
+	path := filepath.Join(root, "receipts", "splits", "ABC", "1.jpg")
+	if data, err := os.ReadFile(path); err != nil || string(data) != "jpeg" {
+		t.Fatalf("stored file = %q, %v", data, err)
+	}
+
+	if _, err := store.Upload(ctx, "receipts/splits/ABC/1.jpg", []byte("png"), "image/png"); err != nil {
+		t.Fatalf("replacing Upload: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "png" {
+		t.Errorf("replaced file = %q", data)
+	}
+
+	if err := store.Delete(ctx, "receipts/splits/ABC/1.jpg"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file still exists after Delete: %v", err)
+	}
+	if err := store.Delete(ctx, "receipts/splits/ABC/1.jpg"); err != nil {
+		t.Errorf("deleting a missing key: %v", err)
+	}
+
+	for _, key := range []string{"../outside.jpg", "/etc/passwd", "a/../../b"} {
+		if _, err := store.Upload(ctx, key, []byte("x"), "image/jpeg"); err == nil {
+			t.Errorf("Upload(%q) succeeded", key)
+		}
+	}
+}
+
+// fakeS3 records the requests an S3 client makes and answers them as S3 would.
+type fakeS3 struct {
+	mu       sync.Mutex
+	requests []string
+	bodies   map[string]string
+	types    map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.bodies[r.URL.Path] = string(body)
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		delete(f.bodies, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3StorePathStyle(t *testing.T) {
+	fake := &fakeS3{bodies: map[string]string{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	t.Setenv("STORAGE_BACKEND", "minio")
+	t.Setenv("S3_ENDPOINT", server.URL)
+	t.Setenv("S3_BUCKET", "tabmate")
+	t.Setenv("S3_ACCESS_KEY_ID", "minio")
+	t.Setenv("S3_SECRET_ACCESS_KEY", "minio-secret")
+	t.Setenv("S3_PUBLIC_URL", "http://localhost:9000/tabmate")
+
+	ctx := context.Background()
+	store, err := NewBlobStoreFromEnv(ctx)
+	if err != nil {
+		t.Fatalf("NewBlobStoreFromEnv: %v", err)
+	}
+
+	object, err := store.Upload(ctx, "avatars/u/a/96.jpg", []byte("jpeg"), "image/jpeg")
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if object.URL != "http://localhost:9000/tabmate/avatars/u/a/96.jpg" {
+		t.Errorf("URL = %q", object.URL)
+	}
+	if got := fake.bodies["/tabmate/avatars/u/a/96.jpg"]; got != "jpeg" {
+		t.Errorf("stored body = %q; requests %v", got, fake.requests)
+	}
+	if got := fake.types["/tabmate/avatars/u/a/96.jpg"]; got != "image/jpeg" {
+		t.Errorf("stored content type = %q", got)
+	}
+
+	if err := store.Delete(ctx, "avatars/u/a/96.jpg"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := fake.bodies["/tabmate/avatars/u/a/96.jpg"]; ok {
+		t.Errorf("object still stored after Delete")
+	}
+}
+
+func TestNewBlobStoreFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    string
+		wantErr bool
+	}{
+		{name: "unset", want: "<nil>"},
+		{name: "local", env: map[string]string{"STORAGE_BACKEND": "local", "STORAGE_LOCAL_DIR": t.TempDir()}, want: "*storage.LocalStore"},
+		{name: "r2 by its variables", env: map[string]string{
+			"R2_ACCOUNT_ID": "acct", "R2_ACCESS_KEY_ID": "key", "R2_SECRET_ACCESS_KEY": "secret",
+			"R2_BUCKET": "tabmate", "R2_PUBLIC_URL": "https://receipts.example.com",
+		}, want: "*storage.S3Store"},
+		{name: "r2 incomplete", env: map[string]string{"STORAGE_BACKEND": "r2", "R2_ACCOUNT_ID": "acct"}, wantErr: true},
+		{name: "s3", env: map[string]string{
+			"STORAGE_BACKEND": "s3", "S3_REGION": "eu-west-2", "S3_BUCKET": "tabmate", "S3_PUBLIC_URL": "https://cdn.example.com",
+		}, want: "*storage.S3Store"},
+		{name: "minio without endpoint", env: map[string]string{"STORAGE_BACKEND": "minio", "S3_BUCKET": "tabmate"}, wantErr: true},
+		{name: "unknown", env: map[string]string{"STORAGE_BACKEND": "ftp"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{
+				"STORAGE_BACKEND", "STORAGE_LOCAL_DIR", "STORAGE_PUBLIC_URL",
+				"R2_ACCOUNT_ID", "R2_ACCESS_KEY_ID", "R2_SECRET_ACCESS_KEY", "R2_BUCKET", "R2_PUBLIC_URL",
+				"S3_ENDPOINT", "S3_REGION", "S3_BUCKET", "S3_ACCESS_KEY_ID", "S3_SECRET_ACCESS_KEY", "S3_PUBLIC_URL",
+			} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			store, err := NewBlobStoreFromEnv(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %T, want an error", store)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewBlobStoreFromEnv: %v", err)
+			}
+			if got := fmt.Sprintf("%T", store); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -11,6 +11,35 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const anonymizeActivityEvents = `-- name: AnonymizeActivityEvents :exec
+UPDATE activity_events
+SET
+    actor_name = CASE WHEN actor_id = $1 THEN $2::text ELSE actor_name END,
+    metadata = CASE
+        WHEN metadata->>'added_by' = $3::text THEN jsonb_set(metadata, '{added_by}', to_jsonb($2::text))
+        ELSE metadata
+    END
+WHERE actor_id = $1
+   OR ($3::text <> '' AND metadata->>'added_by' = $3::text AND entity_code IN (
+        SELECT s.split_code FROM splits s JOIN split_members sm ON sm.split_id = s.id WHERE sm.user_id = $1
+        UNION
+        SELECT t.table_code FROM tables t JOIN table_members tm ON tm.table_id = t.id WHERE tm.user_id = $1
+   ))
+`
+
+type AnonymizeActivityEventsParams struct {
+	UserID  pgtype.UUID `json:"user_id"`
+	Name    string      `json:"name"`
+	OldName string      `json:"old_name"`
+}
+
+// Replaces a deleted user's name on the events they caused, and on events in their
+// splits and tables that name them as the one who added a placeholder.
+func (q *Queries) AnonymizeActivityEvents(ctx context.Context, arg AnonymizeActivityEventsParams) error {
+	_, err := q.db.Exec(ctx, anonymizeActivityEvents, arg.UserID, arg.Name, arg.OldName)
+	return err
+}
+
 const insertActivityEvent = `-- name: InsertActivityEvent :one
 INSERT INTO activity_events (
     event_type,
@@ -59,6 +88,42 @@ func (q *Queries) InsertActivityEvent(ctx context.Context, arg InsertActivityEve
 	return i, err
 }
 
+const listActivityEventsByActor = `-- name: ListActivityEventsByActor :many
+SELECT id, event_type, actor_id, actor_name, entity_type, entity_code, entity_name, metadata, created_at FROM activity_events
+WHERE actor_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListActivityEventsByActor(ctx context.Context, actorID pgtype.UUID) ([]ActivityEvents, error) {
+	rows, err := q.db.Query(ctx, listActivityEventsByActor, actorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ActivityEvents{}
+	for rows.Next() {
+		var i ActivityEvents
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventType,
+			&i.ActorID,
+			&i.ActorName,
+			&i.EntityType,
+			&i.EntityCode,
+			&i.EntityName,
+			&i.Metadata,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listActivityEventsForUser = `-- name: ListActivityEventsForUser :many
 SELECT e.id, e.event_type, e.actor_id, e.actor_name, e.entity_type, e.entity_code, e.entity_name, e.metadata, e.created_at
 FROM activity_events e
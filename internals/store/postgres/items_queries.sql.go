@@ -129,6 +129,68 @@ func (q *Queries) DeleteItemFromTable(ctx context.Context, id pgtype.UUID) error
 	return err
 }
 
+const getItemByID = `-- name: GetItemByID :one
+SELECT id, table_code, added_by_user_id, name, price, quantity, description, source, original_parsed_text, created_at, updated_at FROM items
+WHERE id = $1
+`
+
+func (q *Queries) GetItemByID(ctx context.Context, id pgtype.UUID) (Items, error) {
+	row := q.db.QueryRow(ctx, getItemByID, id)
+	var i Items
+	err := row.Scan(
+		&i.ID,
+		&i.TableCode,
+		&i.AddedByUserID,
+		&i.Name,
+		&i.Price,
+		&i.Quantity,
+		&i.Description,
+		&i.Source,
+		&i.OriginalParsedText,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listItemsAddedByUser = `-- name: ListItemsAddedByUser :many
+SELECT id, table_code, added_by_user_id, name, price, quantity, description, source, original_parsed_text, created_at, updated_at FROM items
+WHERE added_by_user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListItemsAddedByUser(ctx context.Context, addedByUserID pgtype.UUID) ([]Items, error) {
+	rows, err := q.db.Query(ctx, listItemsAddedByUser, addedByUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Items{}
+	for rows.Next() {
+		var i Items
+		if err := rows.Scan(
+			&i.ID,
+			&i.TableCode,
+			&i.AddedByUserID,
+			&i.Name,
+			&i.Price,
+			&i.Quantity,
+			&i.Description,
+			&i.Source,
+			&i.OriginalParsedText,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listItemsInTable = `-- name: ListItemsInTable :many
 SELECT id, table_code, added_by_user_id, name, price, quantity, description, source, original_parsed_text, created_at, updated_at FROM items
 WHERE table_code = $1
@@ -20,6 +20,45 @@ type ActivityEvents struct {
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 }
 
+type Avatars struct {
+	ID         pgtype.UUID        `json:"id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	ObjectKeys []string           `json:"object_keys"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	ReplacedAt pgtype.Timestamptz `json:"replaced_at"`
+}
+
+type DeviceTokens struct {
+	ID         pgtype.UUID        `json:"id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	Token      string             `json:"token"`
+	Platform   string             `json:"platform"`
+	AppVersion pgtype.Text        `json:"app_version"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	LastSeenAt pgtype.Timestamptz `json:"last_seen_at"`
+}
+
+type Friendships struct {
+	RequesterID pgtype.UUID        `json:"requester_id"`
+	AddresseeID pgtype.UUID        `json:"addressee_id"`
+	Status      string             `json:"status"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	AcceptedAt  pgtype.Timestamptz `json:"accepted_at"`
+}
+
+type InviteLinks struct {
+	ID                pgtype.UUID        `json:"id"`
+	SplitID           pgtype.UUID        `json:"split_id"`
+	TableID           pgtype.UUID        `json:"table_id"`
+	CreatedBy         pgtype.UUID        `json:"created_by"`
+	PlaceholderUserID pgtype.UUID        `json:"placeholder_user_id"`
+	ExpiresAt         pgtype.Timestamptz `json:"expires_at"`
+	RevokedAt         pgtype.Timestamptz `json:"revoked_at"`
+	ClaimedBy         pgtype.UUID        `json:"claimed_by"`
+	ClaimedAt         pgtype.Timestamptz `json:"claimed_at"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+}
+
 type Items struct {
 	ID                 pgtype.UUID        `json:"id"`
 	TableCode          string             `json:"table_code"`
@@ -34,6 +73,95 @@ type Items struct {
 	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
 }
 
+type JoinCodes struct {
+	Code      string             `json:"code"`
+	SplitID   pgtype.UUID        `json:"split_id"`
+	TableID   pgtype.UUID        `json:"table_id"`
+	CreatedBy pgtype.UUID        `json:"created_by"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	RevokedAt pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type NotificationOutbox struct {
+	ID            pgtype.UUID        `json:"id"`
+	UserID        pgtype.UUID        `json:"user_id"`
+	SplitID       pgtype.UUID        `json:"split_id"`
+	Kind          string             `json:"kind"`
+	Title         string             `json:"title"`
+	Body          string             `json:"body"`
+	Data          []byte             `json:"data"`
+	Status        string             `json:"status"`
+	Attempts      int32              `json:"attempts"`
+	MaxAttempts   int32              `json:"max_attempts"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	LockedUntil   pgtype.Timestamptz `json:"locked_until"`
+	LastError     pgtype.Text        `json:"last_error"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	DeliveredAt   pgtype.Timestamptz `json:"delivered_at"`
+}
+
+type NotificationPreferences struct {
+	UserID            pgtype.UUID        `json:"user_id"`
+	Channels          []byte             `json:"channels"`
+	QuietHoursEnabled bool               `json:"quiet_hours_enabled"`
+	QuietHoursStart   int32              `json:"quiet_hours_start"`
+	QuietHoursEnd     int32              `json:"quiet_hours_end"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+}
+
+type PayoutMethods struct {
+	ID         pgtype.UUID        `json:"id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	MethodType string             `json:"method_type"`
+	Label      pgtype.Text        `json:"label"`
+	Details    string             `json:"details"`
+	IsDefault  bool               `json:"is_default"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+type PersonalAccessTokens struct {
+	ID         pgtype.UUID        `json:"id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	Name       string             `json:"name"`
+	TokenHash  []byte             `json:"token_hash"`
+	TokenHint  string             `json:"token_hint"`
+	Scopes     []string           `json:"scopes"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	RevokedAt  pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type PhoneVerifications struct {
+	UserID      pgtype.UUID        `json:"user_id"`
+	PhoneNumber string             `json:"phone_number"`
+	CodeHash    []byte             `json:"code_hash"`
+	Attempts    int32              `json:"attempts"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type PlaceholderProfiles struct {
+	UserID    pgtype.UUID        `json:"user_id"`
+	CreatedBy pgtype.UUID        `json:"created_by"`
+	Email     pgtype.Text        `json:"email"`
+	Phone     pgtype.Text        `json:"phone"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type PushTickets struct {
+	ID             string             `json:"id"`
+	NotificationID pgtype.UUID        `json:"notification_id"`
+	PushToken      string             `json:"push_token"`
+	Status         string             `json:"status"`
+	Error          pgtype.Text        `json:"error"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	CheckedAt      pgtype.Timestamptz `json:"checked_at"`
+}
+
 type SplitItemClaims struct {
 	SplitItemID     pgtype.UUID        `json:"split_item_id"`
 	ClaimedByUserID pgtype.UUID        `json:"claimed_by_user_id"`
@@ -52,6 +180,13 @@ type SplitItems struct {
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
 }
 
+type SplitMemberReminders struct {
+	SplitID        pgtype.UUID        `json:"split_id"`
+	UserID         pgtype.UUID        `json:"user_id"`
+	RemindersSent  int32              `json:"reminders_sent"`
+	LastRemindedAt pgtype.Timestamptz `json:"last_reminded_at"`
+}
+
 type SplitMembers struct {
 	SplitID       pgtype.UUID        `json:"split_id"`
 	UserID        pgtype.UUID        `json:"user_id"`
@@ -61,6 +196,22 @@ type SplitMembers struct {
 	Role          string             `json:"role"`
 	JoinedAt      pgtype.Timestamptz `json:"joined_at"`
 	PaymentStatus string             `json:"payment_status"`
+	Permissions   []string           `json:"permissions"`
+}
+
+type SplitPayments struct {
+	ID          pgtype.UUID        `json:"id"`
+	SplitID     pgtype.UUID        `json:"split_id"`
+	UserID      pgtype.UUID        `json:"user_id"`
+	Provider    string             `json:"provider"`
+	Reference   string             `json:"reference"`
+	Amount      pgtype.Numeric     `json:"amount"`
+	Currency    string             `json:"currency"`
+	Status      string             `json:"status"`
+	CheckoutUrl string             `json:"checkout_url"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	ConfirmedAt pgtype.Timestamptz `json:"confirmed_at"`
 }
 
 type SplitReceipts struct {
@@ -75,6 +226,16 @@ type SplitReceipts struct {
 	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
 }
 
+type SplitReminderPolicies struct {
+	SplitID          pgtype.UUID        `json:"split_id"`
+	FirstAfterHours  int32              `json:"first_after_hours"`
+	RepeatEveryHours int32              `json:"repeat_every_hours"`
+	MaxReminders     int32              `json:"max_reminders"`
+	Enabled          bool               `json:"enabled"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+}
+
 type Splits struct {
 	ID                  pgtype.UUID        `json:"id"`
 	CreatedBy           pgtype.UUID        `json:"created_by"`
@@ -91,14 +252,16 @@ type Splits struct {
 	TipIsShared         bool               `json:"tip_is_shared"`
 	SplitType           string             `json:"split_type"`
 	PaymentInstructions pgtype.Text        `json:"payment_instructions"`
+	PayoutMethodID      pgtype.UUID        `json:"payout_method_id"`
 }
 
 type TableMembers struct {
-	TableID   pgtype.UUID        `json:"table_id"`
-	UserID    pgtype.UUID        `json:"user_id"`
-	JoinedAt  pgtype.Timestamptz `json:"joined_at"`
-	Role      string             `json:"role"`
-	IsSettled bool               `json:"is_settled"`
+	TableID     pgtype.UUID        `json:"table_id"`
+	UserID      pgtype.UUID        `json:"user_id"`
+	JoinedAt    pgtype.Timestamptz `json:"joined_at"`
+	Role        string             `json:"role"`
+	IsSettled   bool               `json:"is_settled"`
+	Permissions []string           `json:"permissions"`
 }
 
 type TableSyncOperations struct {
@@ -125,15 +288,36 @@ type Tables struct {
 }
 
 type Users struct {
-	ID                pgtype.UUID        `json:"id"`
-	Name              pgtype.Text        `json:"name"`
-	ProfilePictureUrl pgtype.Text        `json:"profile_picture_url"`
-	CognitoSub        string             `json:"cognito_sub"`
-	Email             string             `json:"email"`
-	CreatedAt         pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
-	PushToken         pgtype.Text        `json:"push_token"`
-	BankName          pgtype.Text        `json:"bank_name"`
-	AccountName       pgtype.Text        `json:"account_name"`
-	AccountNumber     pgtype.Text        `json:"account_number"`
+	ID                     pgtype.UUID        `json:"id"`
+	Name                   pgtype.Text        `json:"name"`
+	ProfilePictureUrl      pgtype.Text        `json:"profile_picture_url"`
+	CognitoSub             string             `json:"cognito_sub"`
+	Email                  string             `json:"email"`
+	CreatedAt              pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt              pgtype.Timestamptz `json:"updated_at"`
+	BankName               pgtype.Text        `json:"bank_name"`
+	AccountName            pgtype.Text        `json:"account_name"`
+	AccountNumber          pgtype.Text        `json:"account_number"`
+	Timezone               string             `json:"timezone"`
+	IsPlaceholder          bool               `json:"is_placeholder"`
+	Username               pgtype.Text        `json:"username"`
+	PhoneNumber            pgtype.Text        `json:"phone_number"`
+	PhoneVerifiedAt        pgtype.Timestamptz `json:"phone_verified_at"`
+	DiscoverableByEmail    string             `json:"discoverable_by_email"`
+	DiscoverableByPhone    string             `json:"discoverable_by_phone"`
+	DiscoverableByUsername string             `json:"discoverable_by_username"`
+}
+
+type WebhookEvents struct {
+	ID          pgtype.UUID        `json:"id"`
+	Source      string             `json:"source"`
+	EventID     string             `json:"event_id"`
+	EventType   string             `json:"event_type"`
+	Payload     []byte             `json:"payload"`
+	Status      string             `json:"status"`
+	Attempts    int32              `json:"attempts"`
+	LastError   pgtype.Text        `json:"last_error"`
+	ReceivedAt  pgtype.Timestamptz `json:"received_at"`
+	ProcessedAt pgtype.Timestamptz `json:"processed_at"`
+	ClaimedAt   pgtype.Timestamptz `json:"claimed_at"`
 }
@@ -11,6 +11,7 @@ import (
 )
 
 type Querier interface {
+	AcceptFriendRequest(ctx context.Context, arg AcceptFriendRequestParams) (int64, error)
 	// Adds a single item to a table.
 	AddItemToTable(ctx context.Context, arg AddItemToTableParams) (Items, error)
 	// Adds multiple items to the database.
@@ -20,32 +21,106 @@ type Querier interface {
 	AddUserToSplit(ctx context.Context, arg AddUserToSplitParams) (SplitMembers, error)
 	// Adds a user to a table with an optional role, defaulting is_settled to false.
 	AddUserToTable(ctx context.Context, arg AddUserToTableParams) (TableMembers, error)
+	// Replaces a deleted user's name on the events they caused, and on events in their
+	// splits and tables that name them as the one who added a placeholder.
+	AnonymizeActivityEvents(ctx context.Context, arg AnonymizeActivityEventsParams) error
+	// Strips everything that identifies a user who deleted their account. The row stays on
+	// as a placeholder so their memberships, balances and items still add up.
+	AnonymizeUser(ctx context.Context, arg AnonymizeUserParams) (Users, error)
+	CancelPendingNotificationsForUser(ctx context.Context, userID pgtype.UUID) error
 	CheckIfCognitoSubExists(ctx context.Context, email string) (bool, error)
 	CheckIfEmailExists(ctx context.Context, email string) (bool, error)
 	CheckIfTableCodeExists(ctx context.Context, tableCode string) (bool, error)
 	// Checks if a specific user is a member of a specific table.
 	CheckIfUserIsMember(ctx context.Context, arg CheckIfUserIsMemberParams) (bool, error)
+	// Locks a batch of due notifications for one worker. Rows left in 'sending' by a
+	// worker that died are picked up again once their lock expires.
+	ClaimDueNotifications(ctx context.Context, arg ClaimDueNotificationsParams) ([]NotificationOutbox, error)
+	ClaimInviteLink(ctx context.Context, arg ClaimInviteLinkParams) (int64, error)
+	// Counts a reminder for a member only if nobody else has since @reminders_sent was read,
+	// so concurrent workers never send the same reminder twice.
+	ClaimSplitReminder(ctx context.Context, arg ClaimSplitReminderParams) (SplitMemberReminders, error)
+	// Marks an event as being handled. Nothing is updated if it was already processed or
+	// another delivery claimed it in the last five minutes; older claims belong to a
+	// delivery that died before finishing.
+	ClaimWebhookEvent(ctx context.Context, id pgtype.UUID) (int64, error)
+	ClearDefaultPayoutMethod(ctx context.Context, userID pgtype.UUID) error
+	ClearPhoneNumber(ctx context.Context, id pgtype.UUID) (Users, error)
 	ConfirmSplitMemberPayment(ctx context.Context, arg ConfirmSplitMemberPaymentParams) (SplitMembers, error)
 	// Counts the number of members in a specific table.
 	CountMembersInTable(ctx context.Context, tableID pgtype.UUID) (int64, error)
 	CountOpenSplits(ctx context.Context) (int64, error)
 	CountOpenTables(ctx context.Context) (int64, error)
+	// Splits and tables both users belong to. Merging a placeholder into someone who shares
+	// one with it would lose one of the two memberships.
+	CountSharedMemberships(ctx context.Context, arg CountSharedMembershipsParams) (int64, error)
 	CountUnclaimedSplitItems(ctx context.Context, splitID pgtype.UUID) (int64, error)
 	CountUnsettledSplitMembers(ctx context.Context, splitID pgtype.UUID) (int64, error)
+	// The id is chosen before upload because it is part of the object keys.
+	CreateAvatar(ctx context.Context, arg CreateAvatarParams) (Avatars, error)
+	// Returns no row if the two already have a request or friendship either way.
+	CreateFriendRequest(ctx context.Context, arg CreateFriendRequestParams) (Friendships, error)
+	CreateInviteLink(ctx context.Context, arg CreateInviteLinkParams) (InviteLinks, error)
+	CreateJoinCode(ctx context.Context, arg CreateJoinCodeParams) (JoinCodes, error)
+	CreatePayoutMethod(ctx context.Context, arg CreatePayoutMethodParams) (PayoutMethods, error)
+	CreatePersonalAccessToken(ctx context.Context, arg CreatePersonalAccessTokenParams) (PersonalAccessTokens, error)
+	CreatePlaceholderProfile(ctx context.Context, arg CreatePlaceholderProfileParams) (PlaceholderProfiles, error)
+	// Placeholders get a sentinel identity that can never match a Clerk user ID.
+	CreatePlaceholderUser(ctx context.Context, name pgtype.Text) (Users, error)
+	CreatePushTicket(ctx context.Context, arg CreatePushTicketParams) error
 	CreateSplit(ctx context.Context, arg CreateSplitParams) (Splits, error)
+	CreateSplitPayment(ctx context.Context, arg CreateSplitPaymentParams) (SplitPayments, error)
 	CreateTable(ctx context.Context, arg CreateTableParams) (Tables, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (Users, error)
 	DeleteAllSplitItems(ctx context.Context, splitID pgtype.UUID) error
+	DeleteAvatar(ctx context.Context, id pgtype.UUID) error
+	DeleteDeviceToken(ctx context.Context, arg DeleteDeviceTokenParams) (int64, error)
+	// Forgets a device token Expo reported as no longer registered, for whichever user holds it.
+	DeleteDeviceTokenByToken(ctx context.Context, token string) error
+	DeleteDeviceTokensForUser(ctx context.Context, userID pgtype.UUID) error
+	// Removes a pending request or a friendship between two users, whichever of them asked.
+	DeleteFriendship(ctx context.Context, arg DeleteFriendshipParams) (int64, error)
+	DeleteFriendshipsForUser(ctx context.Context, requesterID pgtype.UUID) error
 	// Remove an item from a table
 	DeleteItemFromTable(ctx context.Context, id pgtype.UUID) error
+	DeletePayoutMethod(ctx context.Context, arg DeletePayoutMethodParams) error
+	// Splits that pointed at one fall back to no payout method.
+	DeletePayoutMethodsForUser(ctx context.Context, userID pgtype.UUID) error
+	DeletePhoneVerification(ctx context.Context, userID pgtype.UUID) error
+	DeletePlaceholderUser(ctx context.Context, id pgtype.UUID) error
 	DeleteSplitByCode(ctx context.Context, splitCode string) error
 	DeleteSplitItem(ctx context.Context, id pgtype.UUID) error
 	DeleteSplitItemClaim(ctx context.Context, arg DeleteSplitItemClaimParams) error
+	// Removes the receipts a user uploaded and returns their storage keys so the images
+	// can be deleted too. The parsed split items stay.
+	DeleteSplitReceiptsByCreator(ctx context.Context, createdBy pgtype.UUID) ([]string, error)
 	DeleteTableByCode(ctx context.Context, tableCode string) error
 	DeleteTableByID(ctx context.Context, id pgtype.UUID) error
 	DeleteUserByCognitoSub(ctx context.Context, cognitoSub string) error
 	DeleteUserByID(ctx context.Context, id pgtype.UUID) error
+	// Makes the user a plain guest in every split, for when they delete their account.
+	DemoteUserInSplits(ctx context.Context, userID pgtype.UUID) error
+	// Makes the user a plain guest at every table, for when they delete their account.
+	DemoteUserInTables(ctx context.Context, userID pgtype.UUID) error
+	// Delivery starts at next_attempt_at when given (e.g. after the recipient's quiet hours), otherwise now.
+	EnqueueNotification(ctx context.Context, arg EnqueueNotificationParams) (NotificationOutbox, error)
+	// Exact lookups for adding someone. Placeholders are not returned, nor are phone
+	// numbers that haven't been verified; the caller checks the user's discoverability.
+	FindUserByEmail(ctx context.Context, arg FindUserByEmailParams) (Users, error)
+	FindUserByPhone(ctx context.Context, arg FindUserByPhoneParams) (Users, error)
+	FindUserByUsername(ctx context.Context, arg FindUserByUsernameParams) (Users, error)
+	GetActiveJoinCode(ctx context.Context, arg GetActiveJoinCodeParams) (JoinCodes, error)
+	// Looks up a token a request presented. Revoked and expired tokens are not returned.
+	GetActivePersonalAccessTokenByHash(ctx context.Context, tokenHash []byte) (PersonalAccessTokens, error)
 	GetAllTableCodes(ctx context.Context) ([]string, error)
+	// Locks the profile so two accounts can't take the same placeholder over at once.
+	GetClaimablePlaceholder(ctx context.Context, arg GetClaimablePlaceholderParams) (GetClaimablePlaceholderRow, error)
+	GetDefaultPayoutMethod(ctx context.Context, userID pgtype.UUID) (PayoutMethods, error)
+	// The request or friendship between two users, whichever of them asked.
+	GetFriendship(ctx context.Context, arg GetFriendshipParams) (Friendships, error)
+	// Returns an invite with the names needed to preview it before joining.
+	GetInviteLinkDetails(ctx context.Context, id pgtype.UUID) (GetInviteLinkDetailsRow, error)
+	GetItemByID(ctx context.Context, id pgtype.UUID) (Items, error)
 	// -- name: ListTablesByUserID :many
 	// -- Retrieves all membership records for a specific user_id.
 	// SELECT * FROM table_members
@@ -53,12 +128,17 @@ type Querier interface {
 	// ORDER BY joined_at DESC;
 	// Retrieves the role of a specific user in a specific table.
 	GetMemberRoleInTable(ctx context.Context, arg GetMemberRoleInTableParams) (string, error)
+	GetNotificationPreferences(ctx context.Context, id pgtype.UUID) (GetNotificationPreferencesRow, error)
+	GetPayoutMethod(ctx context.Context, id pgtype.UUID) (PayoutMethods, error)
+	GetPhoneVerification(ctx context.Context, userID pgtype.UUID) (PhoneVerifications, error)
 	GetSplitByCode(ctx context.Context, splitCode string) (Splits, error)
 	GetSplitByID(ctx context.Context, id pgtype.UUID) (Splits, error)
 	GetSplitItem(ctx context.Context, id pgtype.UUID) (SplitItems, error)
 	GetSplitItemClaim(ctx context.Context, arg GetSplitItemClaimParams) (SplitItemClaims, error)
 	GetSplitMember(ctx context.Context, arg GetSplitMemberParams) (SplitMembers, error)
+	GetSplitPaymentByReference(ctx context.Context, reference string) (SplitPayments, error)
 	GetSplitReceiptBySplitID(ctx context.Context, splitID pgtype.UUID) (SplitReceipts, error)
+	GetSplitReminderPolicy(ctx context.Context, splitID pgtype.UUID) (SplitReminderPolicies, error)
 	GetTableByCode(ctx context.Context, tableCode string) (Tables, error)
 	GetTableByID(ctx context.Context, id pgtype.UUID) (Tables, error)
 	// Retrieves a specific membership record by table_id and user_id.
@@ -69,13 +149,38 @@ type Querier interface {
 	GetUserByCognitoSub(ctx context.Context, cognitoSub string) (Users, error)
 	GetUserByEmail(ctx context.Context, email string) (Users, error)
 	GetUserByID(ctx context.Context, id pgtype.UUID) (Users, error)
+	// Whether a split or table has ever had a join code, in which case its permanent code
+	// no longer admits new members.
+	HasJoinCodes(ctx context.Context, arg HasJoinCodesParams) (bool, error)
+	IncrementPhoneVerificationAttempts(ctx context.Context, userID pgtype.UUID) error
 	IncrementURLExtractCount(ctx context.Context, tableCode string) (int32, error)
 	InsertActivityEvent(ctx context.Context, arg InsertActivityEventParams) (ActivityEvents, error)
+	// Join codes, split codes and table codes share one namespace so any of them can be
+	// typed into the same join box.
+	IsCodeInUse(ctx context.Context, code string) (bool, error)
+	// Whether two users are friends or share a split or table.
+	IsConnected(ctx context.Context, arg IsConnectedParams) (bool, error)
+	// Devices seen in the last 90 days; older ones have most likely been replaced.
+	ListActiveDeviceTokens(ctx context.Context, userID pgtype.UUID) ([]DeviceTokens, error)
+	ListActivityEventsByActor(ctx context.Context, actorID pgtype.UUID) ([]ActivityEvents, error)
 	// Returns the 50 most recent events from all open tables and splits the user belongs to.
 	ListActivityEventsForUser(ctx context.Context, userID pgtype.UUID) ([]ActivityEvents, error)
+	ListAllPayoutMethods(ctx context.Context) ([]PayoutMethods, error)
 	ListAllUsers(ctx context.Context) ([]Users, error)
+	// Placeholders a host recorded with this email address, for its owner to take over.
+	ListClaimablePlaceholders(ctx context.Context, email string) ([]ListClaimablePlaceholdersRow, error)
 	ListClaimsForItem(ctx context.Context, splitItemID pgtype.UUID) ([]ListClaimsForItemRow, error)
 	ListClaimsForSplit(ctx context.Context, splitID pgtype.UUID) ([]ListClaimsForSplitRow, error)
+	// The user's friends and the people they share splits or tables with, with how many
+	// they share. Most shared first, then friends, then the most recently met. A non-empty
+	// search filters by name; friends_only leaves out people who aren't friends.
+	ListConnectionsForUser(ctx context.Context, arg ListConnectionsForUserParams) ([]ListConnectionsForUserRow, error)
+	// Unpaid guests on open splits whose next scheduled reminder is due at @now.
+	ListDueSplitReminders(ctx context.Context, now pgtype.Timestamptz) ([]ListDueSplitRemindersRow, error)
+	ListFriendIDs(ctx context.Context, userID pgtype.UUID) ([]pgtype.UUID, error)
+	// Pending requests the user sent or received, newest first, with the other person.
+	ListFriendRequestsForUser(ctx context.Context, userID pgtype.UUID) ([]ListFriendRequestsForUserRow, error)
+	ListItemsAddedByUser(ctx context.Context, addedByUserID pgtype.UUID) ([]Items, error)
 	// Retrieves all the items in a table.
 	ListItemsInTable(ctx context.Context, tableCode string) ([]Items, error)
 	// Retrieves all the items in a table with user details (username).
@@ -84,50 +189,125 @@ type Querier interface {
 	ListMembersByTableID(ctx context.Context, tableID pgtype.UUID) ([]TableMembers, error)
 	// Retrieves all members of a specific table_id and include their user details.
 	ListMembersWithUserDetailsByTableID(ctx context.Context, tableID pgtype.UUID) ([]ListMembersWithUserDetailsByTableIDRow, error)
+	// Money still to change hands on the user's open splits: what they owe on splits they
+	// haven't paid, and what other members still owe them on splits they host.
+	ListOutstandingBalancesForUser(ctx context.Context, userID pgtype.UUID) ([]ListOutstandingBalancesForUserRow, error)
+	ListPayoutMethodsForUser(ctx context.Context, userID pgtype.UUID) ([]PayoutMethods, error)
+	// Tickets old enough for Expo to have a receipt ready.
+	ListPendingPushTickets(ctx context.Context, arg ListPendingPushTicketsParams) ([]PushTickets, error)
+	// The user's tokens that are not revoked, newest first. Expired tokens are included so
+	// the user can see why a script stopped working.
+	ListPersonalAccessTokensForUser(ctx context.Context, userID pgtype.UUID) ([]PersonalAccessTokens, error)
+	// Avatars replaced before the given time, oldest first.
+	ListReplacedAvatars(ctx context.Context, arg ListReplacedAvatarsParams) ([]Avatars, error)
 	// Retrieves all members of a table_id where is_settled is true.
 	ListSettledMembersInTable(ctx context.Context, tableID pgtype.UUID) ([]TableMembers, error)
+	ListSplitClaimsForUser(ctx context.Context, claimedByUserID pgtype.UUID) ([]ListSplitClaimsForUserRow, error)
 	ListSplitItems(ctx context.Context, splitID pgtype.UUID) ([]SplitItems, error)
 	ListSplitMembersBySplitID(ctx context.Context, splitID pgtype.UUID) ([]SplitMembers, error)
 	// Get all members of a split with their user info
 	ListSplitMembersWithUserDetails(ctx context.Context, splitID pgtype.UUID) ([]ListSplitMembersWithUserDetailsRow, error)
+	ListSplitNotifications(ctx context.Context, splitID pgtype.UUID) ([]ListSplitNotificationsRow, error)
+	ListSplitPaymentsForMember(ctx context.Context, arg ListSplitPaymentsForMemberParams) ([]SplitPayments, error)
+	ListSplitPaymentsForUser(ctx context.Context, userID pgtype.UUID) ([]ListSplitPaymentsForUserRow, error)
 	ListSplitsByUserID(ctx context.Context, createdBy pgtype.UUID) ([]Splits, error)
 	// Get all splits a user is a member of
 	ListSplitsForUser(ctx context.Context, userID pgtype.UUID) ([]ListSplitsForUserRow, error)
-	// Returns guest members of a table with their push tokens for payment reminders
+	ListSplitsHostedByUser(ctx context.Context, userID pgtype.UUID) ([]pgtype.UUID, error)
+	// Returns guest members of a table, and whether they can be reached by push or email, for payment reminders.
+	// Push only counts devices seen in the last 90 days, like ListActiveDeviceTokens.
 	ListTableGuestsForReminder(ctx context.Context, tableCode string) ([]ListTableGuestsForReminderRow, error)
 	ListTablesByStatus(ctx context.Context, status string) ([]Tables, error)
 	ListTablesByUserID(ctx context.Context, createdBy pgtype.UUID) ([]Tables, error)
+	ListTablesHostedByUser(ctx context.Context, userID pgtype.UUID) ([]pgtype.UUID, error)
 	// For a specific user, list all tables they are a member of,
 	// along with their role and is_settled status in each, and table details.
 	ListTablesWithMembershipStatusForUser(ctx context.Context, userID pgtype.UUID) ([]ListTablesWithMembershipStatusForUserRow, error)
 	// Retrieves all members of a table_id where is_settled is false.
 	ListUnsettledMembersInTable(ctx context.Context, tableID pgtype.UUID) ([]TableMembers, error)
-	// Returns unsettled guest members with their push tokens for sending reminders
+	// Returns unsettled members other than the host, and whether they can be reached by push or email, for sending reminders.
+	// Push only counts devices seen in the last 90 days, like ListActiveDeviceTokens.
 	ListUnsettledSplitMembersForReminder(ctx context.Context, splitID pgtype.UUID) ([]ListUnsettledSplitMembersForReminderRow, error)
+	ListUsersWithBankDetails(ctx context.Context) ([]ListUsersWithBankDetailsRow, error)
+	LockInviteLink(ctx context.Context, id pgtype.UUID) (InviteLinks, error)
 	// Sets is_settled to true for all members of a specific table.
 	// Returns all updated member rows.
 	MarkAllMembersInTableAsSettled(ctx context.Context, tableID pgtype.UUID) ([]TableMembers, error)
+	MarkNotificationDelivered(ctx context.Context, id pgtype.UUID) error
+	MarkNotificationFailed(ctx context.Context, arg MarkNotificationFailedParams) error
+	MarkPushTicketChecked(ctx context.Context, arg MarkPushTicketCheckedParams) error
+	MarkWebhookEventFailed(ctx context.Context, arg MarkWebhookEventFailedParams) error
+	MarkWebhookEventProcessed(ctx context.Context, id pgtype.UUID) error
+	// Credits the placeholder's activity to the user; deleting the placeholder would
+	// otherwise take its events with it.
+	MovePlaceholderActivityEvents(ctx context.Context, arg MovePlaceholderActivityEventsParams) error
+	MovePlaceholderItemClaims(ctx context.Context, arg MovePlaceholderItemClaimsParams) error
+	// Reassigns table orders the placeholder is recorded as adding.
+	MovePlaceholderItems(ctx context.Context, arg MovePlaceholderItemsParams) error
+	MovePlaceholderNotifications(ctx context.Context, arg MovePlaceholderNotificationsParams) error
+	MovePlaceholderSplitItems(ctx context.Context, arg MovePlaceholderSplitItemsParams) error
+	// Hands a placeholder's split memberships, balances included, to a real user. Splits
+	// the user is already in are left alone.
+	MovePlaceholderSplitMemberships(ctx context.Context, arg MovePlaceholderSplitMembershipsParams) error
+	MovePlaceholderTableMemberships(ctx context.Context, arg MovePlaceholderTableMembershipsParams) error
+	// Picks who takes over a split when its host leaves: the longest-standing co-host, or
+	// failing that the longest-standing member with an account.
+	NextSplitHost(ctx context.Context, arg NextSplitHostParams) (pgtype.UUID, error)
+	// Picks who takes over a table when its host deletes their account: the longest-standing
+	// co-host, or failing that the longest-standing member with an account.
+	NextTableHost(ctx context.Context, arg NextTableHostParams) (pgtype.UUID, error)
 	// When someone joins/leaves, recalculate everyone's amount_owed
 	RecalculateSplitForAllMembers(ctx context.Context, splitID pgtype.UUID) error
+	// Inserts a delivery, or bumps the attempt count when the provider redelivers the same event.
+	RecordWebhookEvent(ctx context.Context, arg RecordWebhookEventParams) (WebhookEvents, error)
 	RegisterTableSyncOperation(ctx context.Context, arg RegisterTableSyncOperationParams) (int64, error)
+	// Takes a verified number off whoever else had it, for when a number has been
+	// passed on to someone new.
+	ReleasePhoneNumber(ctx context.Context, arg ReleasePhoneNumberParams) error
 	RemoveUserFromSplit(ctx context.Context, arg RemoveUserFromSplitParams) error
 	// Removes a user from a specific table.
 	RemoveUserFromTable(ctx context.Context, arg RemoveUserFromTableParams) error
+	ReplaceAvatarsForUser(ctx context.Context, userID pgtype.UUID) error
+	RescheduleNotification(ctx context.Context, arg RescheduleNotificationParams) error
+	// A join code that is neither revoked nor expired.
+	ResolveJoinCode(ctx context.Context, code string) (JoinCodes, error)
+	RevokeInviteLink(ctx context.Context, arg RevokeInviteLinkParams) (int64, error)
+	RevokeInviteLinksByCreator(ctx context.Context, createdBy pgtype.UUID) error
+	RevokeJoinCodes(ctx context.Context, arg RevokeJoinCodesParams) (int64, error)
+	RevokePersonalAccessToken(ctx context.Context, arg RevokePersonalAccessTokenParams) (int64, error)
+	RevokePersonalAccessTokensForUser(ctx context.Context, userID pgtype.UUID) error
 	SearchTablesByNameOrRestaurant(ctx context.Context, dollar_1 pgtype.Text) ([]Tables, error)
-	SearchUsersByName(ctx context.Context, arg SearchUsersByNameParams) ([]SearchUsersByNameRow, error)
+	// Run after ClearDefaultPayoutMethod in the same transaction: a user can only have one
+	// default, and the unique index is checked row by row.
+	SetDefaultPayoutMethod(ctx context.Context, arg SetDefaultPayoutMethodParams) error
 	// Updates the is_settled status for a user in a specific table.
 	SetMemberSettledStatus(ctx context.Context, arg SetMemberSettledStatusParams) (TableMembers, error)
+	SetVerifiedPhoneNumber(ctx context.Context, arg SetVerifiedPhoneNumberParams) (Users, error)
+	TouchPersonalAccessToken(ctx context.Context, id pgtype.UUID) error
+	// Makes another member the host, and so the one who is owed. The old host's payout
+	// details no longer apply.
+	TransferSplitHost(ctx context.Context, arg TransferSplitHostParams) (Splits, error)
+	// Makes another member the host of a table.
+	TransferTableHost(ctx context.Context, arg TransferTableHostParams) (Tables, error)
 	UpdateBankDetails(ctx context.Context, arg UpdateBankDetailsParams) error
+	UpdateDiscoverability(ctx context.Context, arg UpdateDiscoverabilityParams) (Users, error)
 	// Updates the quantity of a single item
 	UpdateItemQuantity(ctx context.Context, arg UpdateItemQuantityParams) (Items, error)
-	// Updates the role of a user within a specific table.
+	// Updates the role of a user within a specific table, and for co-hosts what they may do.
 	UpdateMemberRoleInTable(ctx context.Context, arg UpdateMemberRoleInTableParams) (TableMembers, error)
+	UpdatePayoutMethodDetails(ctx context.Context, arg UpdatePayoutMethodDetailsParams) error
 	UpdateSplitAmount(ctx context.Context, arg UpdateSplitAmountParams) (Splits, error)
 	UpdateSplitItemRemainingQty(ctx context.Context, arg UpdateSplitItemRemainingQtyParams) (SplitItems, error)
 	UpdateSplitMemberAmount(ctx context.Context, arg UpdateSplitMemberAmountParams) error
 	UpdateSplitMemberPaymentStatus(ctx context.Context, arg UpdateSplitMemberPaymentStatusParams) (SplitMembers, error)
+	// Sets a member's role and, for co-hosts, what they may do.
+	UpdateSplitMemberRole(ctx context.Context, arg UpdateSplitMemberRoleParams) (SplitMembers, error)
 	UpdateSplitMemberSettledStatus(ctx context.Context, arg UpdateSplitMemberSettledStatusParams) (SplitMembers, error)
 	UpdateSplitPaymentInstructions(ctx context.Context, arg UpdateSplitPaymentInstructionsParams) (Splits, error)
+	// A succeeded payment is final: a repeated or late event updates no row, which tells
+	// the caller someone else has already settled it.
+	UpdateSplitPaymentStatus(ctx context.Context, arg UpdateSplitPaymentStatusParams) (SplitPayments, error)
+	UpdateSplitPayoutMethod(ctx context.Context, arg UpdateSplitPayoutMethodParams) (Splits, error)
 	UpdateSplitReceiptDetails(ctx context.Context, arg UpdateSplitReceiptDetailsParams) (Splits, error)
 	UpdateSplitStatus(ctx context.Context, arg UpdateSplitStatusParams) (Splits, error)
 	UpdateSplitTotalAmount(ctx context.Context, arg UpdateSplitTotalAmountParams) (Splits, error)
@@ -141,8 +321,19 @@ type Querier interface {
 	// Updates the name of a user given their ID and returns the updated user row.
 	UpdateUserName(ctx context.Context, arg UpdateUserNameParams) (Users, error)
 	UpdateUserProfilePictureURL(ctx context.Context, arg UpdateUserProfilePictureURLParams) (Users, error)
-	UpdateUserPushToken(ctx context.Context, arg UpdateUserPushTokenParams) error
+	UpdateUserTimezone(ctx context.Context, arg UpdateUserTimezoneParams) error
+	// Sets or, with NULL, clears the username. Fails on the unique index if it is taken.
+	UpdateUsername(ctx context.Context, arg UpdateUsernameParams) (Users, error)
+	// A token identifies one device, so re-registering it (e.g. after a different user
+	// signs in on the same phone) moves it to the new owner.
+	UpsertDeviceToken(ctx context.Context, arg UpsertDeviceTokenParams) (DeviceTokens, error)
+	UpsertNotificationPreferences(ctx context.Context, arg UpsertNotificationPreferencesParams) (NotificationPreferences, error)
+	// Replaces any code already pending for the user.
+	UpsertPhoneVerification(ctx context.Context, arg UpsertPhoneVerificationParams) error
 	UpsertSplitReceipt(ctx context.Context, arg UpsertSplitReceiptParams) (SplitReceipts, error)
+	UpsertSplitReminderPolicy(ctx context.Context, arg UpsertSplitReminderPolicyParams) (SplitReminderPolicies, error)
+	// Reports whether the debtor still owes money on a split created by the creditor.
+	UserOwesUser(ctx context.Context, arg UserOwesUserParams) (bool, error)
 }
 
 var _ Querier = (*Queries)(nil)
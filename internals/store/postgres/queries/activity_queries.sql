@@ -29,3 +29,25 @@ WHERE e.entity_code IN (
 )
 ORDER BY e.created_at DESC
 LIMIT 50;
+
+-- name: AnonymizeActivityEvents :exec
+-- Replaces a deleted user's name on the events they caused, and on events in their
+-- splits and tables that name them as the one who added a placeholder.
+UPDATE activity_events
+SET
+    actor_name = CASE WHEN actor_id = @user_id THEN @name::text ELSE actor_name END,
+    metadata = CASE
+        WHEN metadata->>'added_by' = @old_name::text THEN jsonb_set(metadata, '{added_by}', to_jsonb(@name::text))
+        ELSE metadata
+    END
+WHERE actor_id = @user_id
+   OR (@old_name::text <> '' AND metadata->>'added_by' = @old_name::text AND entity_code IN (
+        SELECT s.split_code FROM splits s JOIN split_members sm ON sm.split_id = s.id WHERE sm.user_id = @user_id
+        UNION
+        SELECT t.table_code FROM tables t JOIN table_members tm ON tm.table_id = t.id WHERE tm.user_id = @user_id
+   ));
+
+-- name: ListActivityEventsByActor :many
+SELECT * FROM activity_events
+WHERE actor_id = $1
+ORDER BY created_at;
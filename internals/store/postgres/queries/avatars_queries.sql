@@ -0,0 +1,20 @@
+-- name: CreateAvatar :one
+-- The id is chosen before upload because it is part of the object keys.
+INSERT INTO avatars (id, user_id, object_keys)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: DeleteAvatar :exec
+DELETE FROM avatars WHERE id = $1;
+
+-- name: ListReplacedAvatars :many
+-- Avatars replaced before the given time, oldest first.
+SELECT * FROM avatars
+WHERE replaced_at IS NOT NULL AND replaced_at < $1
+ORDER BY replaced_at
+LIMIT $2;
+
+-- name: ReplaceAvatarsForUser :exec
+UPDATE avatars
+SET replaced_at = NOW()
+WHERE user_id = $1 AND replaced_at IS NULL;
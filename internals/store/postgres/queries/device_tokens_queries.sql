@@ -0,0 +1,30 @@
+-- name: UpsertDeviceToken :one
+-- A token identifies one device, so re-registering it (e.g. after a different user
+-- signs in on the same phone) moves it to the new owner.
+INSERT INTO device_tokens (user_id, token, platform, app_version)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (token) DO UPDATE
+SET user_id = EXCLUDED.user_id,
+    platform = EXCLUDED.platform,
+    app_version = EXCLUDED.app_version,
+    last_seen_at = NOW()
+RETURNING *;
+
+-- name: DeleteDeviceToken :execrows
+DELETE FROM device_tokens
+WHERE user_id = $1 AND token = $2;
+
+-- name: DeleteDeviceTokenByToken :exec
+-- Forgets a device token Expo reported as no longer registered, for whichever user holds it.
+DELETE FROM device_tokens
+WHERE token = $1;
+
+-- name: ListActiveDeviceTokens :many
+-- Devices seen in the last 90 days; older ones have most likely been replaced.
+SELECT * FROM device_tokens
+WHERE user_id = $1 AND last_seen_at > NOW() - INTERVAL '90 days'
+ORDER BY last_seen_at DESC;
+
+-- name: DeleteDeviceTokensForUser :exec
+DELETE FROM device_tokens
+WHERE user_id = $1;
@@ -0,0 +1,111 @@
+-- name: CreateFriendRequest :one
+-- Returns no row if the two already have a request or friendship either way.
+INSERT INTO friendships (requester_id, addressee_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+RETURNING *;
+
+-- name: GetFriendship :one
+-- The request or friendship between two users, whichever of them asked.
+SELECT * FROM friendships
+WHERE (requester_id = @user_id AND addressee_id = @other_id)
+   OR (requester_id = @other_id AND addressee_id = @user_id);
+
+-- name: AcceptFriendRequest :execrows
+UPDATE friendships
+SET status = 'accepted', accepted_at = NOW()
+WHERE requester_id = $1 AND addressee_id = $2 AND status = 'pending';
+
+-- name: DeleteFriendship :execrows
+-- Removes a pending request or a friendship between two users, whichever of them asked.
+DELETE FROM friendships
+WHERE status = @status
+  AND ((requester_id = @user_id AND addressee_id = @other_id)
+    OR (requester_id = @other_id AND addressee_id = @user_id));
+
+-- name: DeleteFriendshipsForUser :exec
+DELETE FROM friendships
+WHERE requester_id = $1 OR addressee_id = $1;
+
+-- name: ListFriendRequestsForUser :many
+-- Pending requests the user sent or received, newest first, with the other person.
+SELECT
+    f.requester_id,
+    f.addressee_id,
+    f.created_at,
+    u.id AS other_id,
+    u.name AS other_name,
+    u.profile_picture_url AS other_profile_picture_url
+FROM friendships f
+JOIN users u ON u.id = CASE WHEN f.requester_id = @user_id THEN f.addressee_id ELSE f.requester_id END
+WHERE f.status = 'pending'
+  AND (f.requester_id = @user_id OR f.addressee_id = @user_id)
+ORDER BY f.created_at DESC;
+
+-- name: ListConnectionsForUser :many
+-- The user's friends and the people they share splits or tables with, with how many
+-- they share. Most shared first, then friends, then the most recently met. A non-empty
+-- search filters by name; friends_only leaves out people who aren't friends.
+WITH shared AS (
+    SELECT other.user_id, GREATEST(me.joined_at, other.joined_at) AS shared_at
+    FROM split_members me
+    JOIN split_members other ON other.split_id = me.split_id AND other.user_id <> me.user_id
+    WHERE me.user_id = @user_id
+    UNION ALL
+    SELECT other.user_id, GREATEST(me.joined_at, other.joined_at) AS shared_at
+    FROM table_members me
+    JOIN table_members other ON other.table_id = me.table_id AND other.user_id <> me.user_id
+    WHERE me.user_id = @user_id
+), contacts AS (
+    SELECT user_id, COUNT(*) AS shared_count, MAX(shared_at) AS last_shared_at
+    FROM shared
+    GROUP BY user_id
+), friends AS (
+    SELECT CASE WHEN requester_id = @user_id THEN addressee_id ELSE requester_id END AS user_id
+    FROM friendships
+    WHERE status = 'accepted' AND (requester_id = @user_id OR addressee_id = @user_id)
+)
+SELECT
+    u.id,
+    u.name,
+    u.username,
+    u.email,
+    u.profile_picture_url,
+    COALESCE(c.shared_count, 0)::int AS shared_count,
+    c.last_shared_at::timestamptz AS last_shared_at,
+    (f.user_id IS NOT NULL)::bool AS is_friend
+FROM users u
+LEFT JOIN contacts c ON c.user_id = u.id
+LEFT JOIN friends f ON f.user_id = u.id
+WHERE (c.user_id IS NOT NULL OR f.user_id IS NOT NULL)
+  AND NOT u.is_placeholder
+  AND (@search::text = '' OR u.name ILIKE '%' || @search::text || '%')
+  AND (NOT @friends_only::bool OR f.user_id IS NOT NULL)
+ORDER BY shared_count DESC, is_friend DESC, last_shared_at DESC NULLS LAST, u.name
+LIMIT @max_results;
+
+-- name: ListFriendIDs :many
+SELECT (CASE WHEN requester_id = @user_id THEN addressee_id ELSE requester_id END)::uuid AS friend_id
+FROM friendships
+WHERE status = 'accepted' AND (requester_id = @user_id OR addressee_id = @user_id);
+
+-- name: IsConnected :one
+-- Whether two users are friends or share a split or table.
+SELECT (
+    EXISTS (
+        SELECT 1 FROM friendships
+        WHERE status = 'accepted'
+          AND ((requester_id = @user_id AND addressee_id = @other_id)
+            OR (requester_id = @other_id AND addressee_id = @user_id))
+    )
+    OR EXISTS (
+        SELECT 1 FROM split_members a
+        JOIN split_members b ON b.split_id = a.split_id
+        WHERE a.user_id = @user_id AND b.user_id = @other_id
+    )
+    OR EXISTS (
+        SELECT 1 FROM table_members a
+        JOIN table_members b ON b.table_id = a.table_id
+        WHERE a.user_id = @user_id AND b.user_id = @other_id
+    )
+)::bool AS connected;
@@ -0,0 +1,47 @@
+-- name: CreateInviteLink :one
+INSERT INTO invite_links (split_id, table_id, created_by, placeholder_user_id, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetInviteLinkDetails :one
+-- Returns an invite with the names needed to preview it before joining.
+SELECT
+    il.id,
+    il.split_id,
+    il.table_id,
+    il.placeholder_user_id,
+    il.expires_at,
+    il.revoked_at,
+    il.claimed_at,
+    s.split_code,
+    s.name AS split_name,
+    t.table_code,
+    t.name AS table_name,
+    host.name AS host_name,
+    ph.name AS placeholder_name
+FROM invite_links il
+JOIN users host ON host.id = il.created_by
+LEFT JOIN splits s ON s.id = il.split_id
+LEFT JOIN tables t ON t.id = il.table_id
+LEFT JOIN users ph ON ph.id = il.placeholder_user_id
+WHERE il.id = $1;
+
+-- name: LockInviteLink :one
+SELECT * FROM invite_links
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ClaimInviteLink :execrows
+UPDATE invite_links
+SET claimed_by = $2, claimed_at = NOW()
+WHERE id = $1 AND claimed_at IS NULL;
+
+-- name: RevokeInviteLink :execrows
+UPDATE invite_links
+SET revoked_at = NOW()
+WHERE id = $1 AND created_by = $2 AND revoked_at IS NULL;
+
+-- name: RevokeInviteLinksByCreator :exec
+UPDATE invite_links
+SET revoked_at = NOW()
+WHERE created_by = $1 AND revoked_at IS NULL;
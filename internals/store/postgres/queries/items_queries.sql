@@ -26,6 +26,11 @@ WHERE i.table_code = $1
 ORDER BY i.created_at ASC;
 
 
+-- name: GetItemByID :one
+SELECT * FROM items
+WHERE id = $1;
+
+
 -- name: DeleteItemFromTable :exec
 -- Remove an item from a table
 DELETE FROM items
@@ -83,3 +88,8 @@ INSERT INTO items (
     $7,
     $8
 );
+
+-- name: ListItemsAddedByUser :many
+SELECT * FROM items
+WHERE added_by_user_id = $1
+ORDER BY created_at;
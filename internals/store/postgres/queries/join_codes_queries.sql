@@ -0,0 +1,42 @@
+-- name: IsCodeInUse :one
+-- Join codes, split codes and table codes share one namespace so any of them can be
+-- typed into the same join box.
+SELECT (
+    EXISTS (SELECT 1 FROM join_codes jc WHERE jc.code = @code)
+    OR EXISTS (SELECT 1 FROM splits s WHERE s.split_code = @code)
+    OR EXISTS (SELECT 1 FROM tables t WHERE t.table_code = @code)
+)::boolean AS in_use;
+
+-- name: CreateJoinCode :one
+INSERT INTO join_codes (code, split_id, table_id, created_by, expires_at)
+VALUES (@code, @split_id, @table_id, @created_by, @expires_at)
+RETURNING *;
+
+-- name: ResolveJoinCode :one
+-- A join code that is neither revoked nor expired.
+SELECT * FROM join_codes
+WHERE code = $1
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > NOW());
+
+-- name: GetActiveJoinCode :one
+SELECT * FROM join_codes
+WHERE (split_id = @split_id OR table_id = @table_id)
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: HasJoinCodes :one
+-- Whether a split or table has ever had a join code, in which case its permanent code
+-- no longer admits new members.
+SELECT EXISTS (
+    SELECT 1 FROM join_codes
+    WHERE split_id = @split_id OR table_id = @table_id
+);
+
+-- name: RevokeJoinCodes :execrows
+UPDATE join_codes
+SET revoked_at = NOW()
+WHERE (split_id = @split_id OR table_id = @table_id)
+  AND revoked_at IS NULL;
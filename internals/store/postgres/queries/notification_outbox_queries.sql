@@ -0,0 +1,60 @@
+-- name: EnqueueNotification :one
+-- Delivery starts at next_attempt_at when given (e.g. after the recipient's quiet hours), otherwise now.
+INSERT INTO notification_outbox (user_id, split_id, kind, title, body, data, next_attempt_at)
+VALUES ($1, $2, $3, $4, $5, $6, COALESCE(sqlc.narg('next_attempt_at')::timestamptz, NOW()))
+RETURNING *;
+
+-- name: ClaimDueNotifications :many
+-- Locks a batch of due notifications for one worker. Rows left in 'sending' by a
+-- worker that died are picked up again once their lock expires.
+UPDATE notification_outbox
+SET status = 'sending',
+    attempts = attempts + 1,
+    locked_until = NOW() + make_interval(secs => @lock_seconds::int),
+    updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM notification_outbox
+    WHERE (status = 'pending' AND next_attempt_at <= NOW())
+       OR (status = 'sending' AND locked_until < NOW())
+    ORDER BY next_attempt_at
+    LIMIT @batch_size
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkNotificationDelivered :exec
+UPDATE notification_outbox
+SET status = 'delivered', delivered_at = NOW(), locked_until = NULL, last_error = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- name: RescheduleNotification :exec
+UPDATE notification_outbox
+SET status = 'pending', next_attempt_at = $2, locked_until = NULL, last_error = $3, updated_at = NOW()
+WHERE id = $1;
+
+-- name: MarkNotificationFailed :exec
+UPDATE notification_outbox
+SET status = 'failed', locked_until = NULL, last_error = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: ListSplitNotifications :many
+SELECT
+    n.id,
+    n.user_id,
+    u.name AS user_name,
+    n.kind,
+    n.title,
+    n.status,
+    n.attempts,
+    n.last_error,
+    n.created_at,
+    n.delivered_at
+FROM notification_outbox n
+JOIN users u ON u.id = n.user_id
+WHERE n.split_id = $1
+ORDER BY n.created_at DESC
+LIMIT 100;
+
+-- name: CancelPendingNotificationsForUser :exec
+DELETE FROM notification_outbox
+WHERE user_id = $1 AND status = 'pending';
@@ -0,0 +1,21 @@
+-- name: GetNotificationPreferences :one
+SELECT
+    u.timezone,
+    p.channels,
+    p.quiet_hours_enabled,
+    p.quiet_hours_start,
+    p.quiet_hours_end
+FROM users u
+LEFT JOIN notification_preferences p ON p.user_id = u.id
+WHERE u.id = $1;
+
+-- name: UpsertNotificationPreferences :one
+INSERT INTO notification_preferences (user_id, channels, quiet_hours_enabled, quiet_hours_start, quiet_hours_end)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id) DO UPDATE
+SET channels = EXCLUDED.channels,
+    quiet_hours_enabled = EXCLUDED.quiet_hours_enabled,
+    quiet_hours_start = EXCLUDED.quiet_hours_start,
+    quiet_hours_end = EXCLUDED.quiet_hours_end,
+    updated_at = NOW()
+RETURNING *;
@@ -0,0 +1,48 @@
+-- name: CreatePayoutMethod :one
+INSERT INTO payout_methods (user_id, method_type, label, details)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetPayoutMethod :one
+SELECT * FROM payout_methods
+WHERE id = $1;
+
+-- name: GetDefaultPayoutMethod :one
+SELECT * FROM payout_methods
+WHERE user_id = $1 AND is_default
+LIMIT 1;
+
+-- name: ListPayoutMethodsForUser :many
+SELECT * FROM payout_methods
+WHERE user_id = $1
+ORDER BY is_default DESC, created_at ASC;
+
+-- name: SetDefaultPayoutMethod :exec
+-- Run after ClearDefaultPayoutMethod in the same transaction: a user can only have one
+-- default, and the unique index is checked row by row.
+UPDATE payout_methods
+SET is_default = TRUE, updated_at = NOW()
+WHERE id = @id AND user_id = @user_id;
+
+-- name: ClearDefaultPayoutMethod :exec
+UPDATE payout_methods
+SET is_default = FALSE, updated_at = NOW()
+WHERE user_id = $1 AND is_default;
+
+-- name: DeletePayoutMethod :exec
+DELETE FROM payout_methods
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListAllPayoutMethods :many
+SELECT * FROM payout_methods
+ORDER BY id;
+
+-- name: UpdatePayoutMethodDetails :exec
+UPDATE payout_methods
+SET details = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: DeletePayoutMethodsForUser :exec
+-- Splits that pointed at one fall back to no payout method.
+DELETE FROM payout_methods
+WHERE user_id = $1;
@@ -0,0 +1,23 @@
+-- name: UpsertPhoneVerification :exec
+-- Replaces any code already pending for the user.
+INSERT INTO phone_verifications (user_id, phone_number, code_hash, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id) DO UPDATE
+SET phone_number = EXCLUDED.phone_number,
+    code_hash = EXCLUDED.code_hash,
+    attempts = 0,
+    expires_at = EXCLUDED.expires_at,
+    created_at = NOW();
+
+-- name: GetPhoneVerification :one
+SELECT * FROM phone_verifications
+WHERE user_id = $1;
+
+-- name: IncrementPhoneVerificationAttempts :exec
+UPDATE phone_verifications
+SET attempts = attempts + 1
+WHERE user_id = $1;
+
+-- name: DeletePhoneVerification :exec
+DELETE FROM phone_verifications
+WHERE user_id = $1;
@@ -0,0 +1,86 @@
+-- name: CreatePlaceholderUser :one
+-- Placeholders get a sentinel identity that can never match a Clerk user ID.
+INSERT INTO users (name, cognito_sub, email, is_placeholder)
+VALUES (@name, 'placeholder:' || gen_random_uuid(), '', TRUE)
+RETURNING *;
+
+-- name: CreatePlaceholderProfile :one
+INSERT INTO placeholder_profiles (user_id, created_by, email, phone)
+VALUES (@user_id, @created_by, @email, @phone)
+RETURNING *;
+
+-- name: ListClaimablePlaceholders :many
+-- Placeholders a host recorded with this email address, for its owner to take over.
+SELECT u.id, u.name, pp.created_at, h.name AS host_name
+FROM placeholder_profiles pp
+JOIN users u ON u.id = pp.user_id
+JOIN users h ON h.id = pp.created_by
+WHERE @email::text <> '' AND LOWER(pp.email) = LOWER(@email::text)
+ORDER BY pp.created_at;
+
+-- name: GetClaimablePlaceholder :one
+-- Locks the profile so two accounts can't take the same placeholder over at once.
+SELECT u.id, u.name
+FROM placeholder_profiles pp
+JOIN users u ON u.id = pp.user_id
+WHERE pp.user_id = @user_id AND @email::text <> '' AND LOWER(pp.email) = LOWER(@email::text)
+FOR UPDATE OF pp;
+
+-- name: CountSharedMemberships :one
+-- Splits and tables both users belong to. Merging a placeholder into someone who shares
+-- one with it would lose one of the two memberships.
+SELECT (
+    (SELECT COUNT(*) FROM split_members a
+     JOIN split_members b ON b.split_id = a.split_id
+     WHERE a.user_id = @placeholder_id AND b.user_id = @user_id)
+  + (SELECT COUNT(*) FROM table_members a
+     JOIN table_members b ON b.table_id = a.table_id
+     WHERE a.user_id = @placeholder_id AND b.user_id = @user_id)
+)::bigint AS shared;
+
+-- name: MovePlaceholderSplitMemberships :exec
+-- Hands a placeholder's split memberships, balances included, to a real user. Splits
+-- the user is already in are left alone.
+UPDATE split_members
+SET user_id = @user_id
+WHERE user_id = @placeholder_id
+  AND split_id NOT IN (SELECT sm.split_id FROM split_members sm WHERE sm.user_id = @user_id);
+
+-- name: MovePlaceholderItemClaims :exec
+UPDATE split_item_claims
+SET claimed_by_user_id = @user_id
+WHERE claimed_by_user_id = @placeholder_id
+  AND split_item_id NOT IN (SELECT c.split_item_id FROM split_item_claims c WHERE c.claimed_by_user_id = @user_id);
+
+-- name: MovePlaceholderTableMemberships :exec
+UPDATE table_members
+SET user_id = @user_id
+WHERE user_id = @placeholder_id
+  AND table_id NOT IN (SELECT tm.table_id FROM table_members tm WHERE tm.user_id = @user_id);
+
+-- name: MovePlaceholderItems :exec
+-- Reassigns table orders the placeholder is recorded as adding.
+UPDATE items
+SET added_by_user_id = @user_id
+WHERE added_by_user_id = @placeholder_id;
+
+-- name: MovePlaceholderSplitItems :exec
+UPDATE split_items
+SET added_by_user_id = @user_id
+WHERE added_by_user_id = @placeholder_id;
+
+-- name: MovePlaceholderActivityEvents :exec
+-- Credits the placeholder's activity to the user; deleting the placeholder would
+-- otherwise take its events with it.
+UPDATE activity_events
+SET actor_id = @user_id
+WHERE actor_id = @placeholder_id;
+
+-- name: MovePlaceholderNotifications :exec
+UPDATE notification_outbox
+SET user_id = @user_id
+WHERE user_id = @placeholder_id;
+
+-- name: DeletePlaceholderUser :exec
+DELETE FROM users
+WHERE id = $1 AND is_placeholder;
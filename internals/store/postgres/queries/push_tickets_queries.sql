@@ -0,0 +1,16 @@
+-- name: CreatePushTicket :exec
+INSERT INTO push_tickets (id, notification_id, push_token)
+VALUES ($1, $2, $3)
+ON CONFLICT (id) DO NOTHING;
+
+-- name: ListPendingPushTickets :many
+-- Tickets old enough for Expo to have a receipt ready.
+SELECT * FROM push_tickets
+WHERE status = 'pending' AND created_at <= @created_before::timestamptz
+ORDER BY created_at
+LIMIT @batch_size;
+
+-- name: MarkPushTicketChecked :exec
+UPDATE push_tickets
+SET status = $2, error = $3, checked_at = NOW()
+WHERE id = $1;
@@ -75,3 +75,11 @@ SET
     updated_at    = NOW()
 WHERE id = $1
 RETURNING *;
+
+-- name: ListSplitClaimsForUser :many
+SELECT s.split_code, si.name AS item_name, si.price, c.quantity_claimed, c.claimed_at
+FROM split_item_claims c
+JOIN split_items si ON si.id = c.split_item_id
+JOIN splits s ON s.id = si.split_id
+WHERE c.claimed_by_user_id = $1
+ORDER BY c.claimed_at;
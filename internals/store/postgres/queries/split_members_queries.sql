@@ -23,9 +23,11 @@ SELECT
     sm.role,
     sm.joined_at,
     sm.payment_status,
+    sm.permissions,
     u.email AS user_email,
     u.name AS user_name,
-    u.profile_picture_url AS user_profile_picture_url
+    u.profile_picture_url AS user_profile_picture_url,
+    u.is_placeholder AS user_is_placeholder
 FROM split_members sm
 JOIN users u ON sm.user_id = u.id
 WHERE sm.split_id = $1
@@ -72,15 +74,16 @@ SET amount_owed = $3
 WHERE split_id = $1 AND user_id = $2;
 
 -- name: ListUnsettledSplitMembersForReminder :many
--- Returns unsettled guest members with their push tokens for sending reminders
+-- Returns unsettled members other than the host, and whether they can be reached by push or email, for sending reminders.
+-- Push only counts devices seen in the last 90 days, like ListActiveDeviceTokens.
 SELECT
     sm.user_id,
     u.name AS user_name,
     sm.amount_owed,
-    u.push_token
+    (u.email <> '' OR EXISTS (SELECT 1 FROM device_tokens d WHERE d.user_id = u.id AND d.last_seen_at > NOW() - INTERVAL '90 days')) AS reachable
 FROM split_members sm
 JOIN users u ON sm.user_id = u.id
-WHERE sm.split_id = $1 AND sm.is_settled = FALSE AND sm.role = 'guest';
+WHERE sm.split_id = $1 AND sm.is_settled = FALSE AND sm.role <> 'host';
 
 -- name: UpdateSplitMemberPaymentStatus :one
 UPDATE split_members
@@ -104,3 +107,68 @@ SET amount_owed = (
 )
 FROM splits s
 WHERE sm.split_id = s.id AND sm.split_id = $1;
+
+-- name: UserOwesUser :one
+-- Reports whether the debtor still owes money on a split created by the creditor.
+SELECT EXISTS (
+    SELECT 1
+    FROM split_members sm
+    JOIN splits s ON s.id = sm.split_id
+    WHERE sm.user_id = @debtor_id
+      AND s.created_by = @creditor_id
+      AND sm.role <> 'host'
+      AND sm.payment_status <> 'confirmed'
+      AND sm.amount_owed > 0
+);
+
+-- name: UpdateSplitMemberRole :one
+-- Sets a member's role and, for co-hosts, what they may do.
+UPDATE split_members
+SET role = @role, permissions = @permissions
+WHERE split_id = @split_id AND user_id = @user_id
+RETURNING *;
+
+-- name: NextSplitHost :one
+-- Picks who takes over a split when its host leaves: the longest-standing co-host, or
+-- failing that the longest-standing member with an account.
+SELECT sm.user_id
+FROM split_members sm
+JOIN users u ON u.id = sm.user_id
+WHERE sm.split_id = @split_id AND sm.user_id <> @user_id AND NOT u.is_placeholder
+ORDER BY sm.role = 'cohost' DESC, sm.joined_at ASC
+LIMIT 1;
+
+-- name: ListSplitsHostedByUser :many
+SELECT split_id
+FROM split_members
+WHERE user_id = $1 AND role = 'host';
+
+-- name: DemoteUserInSplits :exec
+-- Makes the user a plain guest in every split, for when they delete their account.
+UPDATE split_members
+SET role = 'guest', permissions = '{}'
+WHERE user_id = $1 AND role <> 'guest';
+
+-- name: ListOutstandingBalancesForUser :many
+-- Money still to change hands on the user's open splits: what they owe on splits they
+-- haven't paid, and what other members still owe them on splits they host.
+SELECT s.split_code, s.name AS split_name, 'owes'::text AS direction, sm.amount_owed AS amount
+FROM split_members sm
+JOIN splits s ON s.id = sm.split_id
+WHERE sm.user_id = @user_id
+  AND sm.role <> 'host'
+  AND sm.payment_status <> 'confirmed'
+  AND sm.amount_owed > 0
+  AND s.status <> 'settled'
+UNION ALL
+SELECT s.split_code, s.name AS split_name, 'owed'::text AS direction, SUM(sm.amount_owed)::numeric AS amount
+FROM splits s
+JOIN split_members sm ON sm.split_id = s.id
+WHERE s.created_by = @user_id
+  AND sm.user_id <> @user_id
+  AND sm.role <> 'host'
+  AND sm.payment_status <> 'confirmed'
+  AND sm.amount_owed > 0
+  AND s.status <> 'settled'
+GROUP BY s.id, s.split_code, s.name
+ORDER BY split_code;
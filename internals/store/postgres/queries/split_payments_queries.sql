@@ -0,0 +1,38 @@
+-- name: CreateSplitPayment :one
+INSERT INTO split_payments (
+    split_id,
+    user_id,
+    provider,
+    reference,
+    amount,
+    currency,
+    checkout_url
+) VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetSplitPaymentByReference :one
+SELECT * FROM split_payments
+WHERE reference = $1;
+
+-- name: ListSplitPaymentsForMember :many
+SELECT * FROM split_payments
+WHERE split_id = $1 AND user_id = $2
+ORDER BY created_at DESC;
+
+-- name: UpdateSplitPaymentStatus :one
+-- A succeeded payment is final: a repeated or late event updates no row, which tells
+-- the caller someone else has already settled it.
+UPDATE split_payments
+SET
+    status = @status::text,
+    confirmed_at = CASE WHEN @status::text = 'succeeded' THEN NOW() ELSE confirmed_at END,
+    updated_at = NOW()
+WHERE reference = @reference AND status <> 'succeeded'
+RETURNING *;
+
+-- name: ListSplitPaymentsForUser :many
+SELECT s.split_code, p.provider, p.reference, p.amount, p.currency, p.status, p.created_at, p.confirmed_at
+FROM split_payments p
+JOIN splits s ON s.id = p.split_id
+WHERE p.user_id = $1
+ORDER BY p.created_at;
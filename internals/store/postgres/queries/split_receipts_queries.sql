@@ -19,3 +19,10 @@ RETURNING id, split_id, object_key, image_url, media_type, original_filename, cr
 SELECT id, split_id, object_key, image_url, media_type, original_filename, created_by, created_at, updated_at
 FROM split_receipts
 WHERE split_id = $1;
+
+-- name: DeleteSplitReceiptsByCreator :many
+-- Removes the receipts a user uploaded and returns their storage keys so the images
+-- can be deleted too. The parsed split items stay.
+DELETE FROM split_receipts
+WHERE created_by = $1
+RETURNING object_key;
@@ -0,0 +1,58 @@
+-- name: GetSplitReminderPolicy :one
+SELECT * FROM split_reminder_policies
+WHERE split_id = $1;
+
+-- name: UpsertSplitReminderPolicy :one
+INSERT INTO split_reminder_policies (split_id, first_after_hours, repeat_every_hours, max_reminders, enabled)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (split_id) DO UPDATE
+SET first_after_hours = EXCLUDED.first_after_hours,
+    repeat_every_hours = EXCLUDED.repeat_every_hours,
+    max_reminders = EXCLUDED.max_reminders,
+    enabled = EXCLUDED.enabled,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: ListDueSplitReminders :many
+-- Unpaid guests on open splits whose next scheduled reminder is due at @now.
+SELECT
+    sm.split_id,
+    sm.user_id,
+    sm.amount_owed,
+    s.split_code,
+    s.name AS split_name,
+    h.name AS host_name,
+    u.name AS user_name,
+    p.max_reminders,
+    COALESCE(r.reminders_sent, 0)::int AS reminders_sent
+FROM split_reminder_policies p
+JOIN splits s ON s.id = p.split_id
+JOIN users h ON h.id = s.created_by
+JOIN split_members sm ON sm.split_id = s.id
+JOIN users u ON u.id = sm.user_id
+LEFT JOIN split_member_reminders r ON r.split_id = sm.split_id AND r.user_id = sm.user_id
+WHERE p.enabled
+  AND s.status <> 'settled'
+  AND sm.role <> 'host'
+  AND NOT u.is_placeholder
+  AND sm.payment_status <> 'confirmed'
+  AND sm.amount_owed > 0
+  AND COALESCE(r.reminders_sent, 0) < p.max_reminders
+  AND CASE
+        WHEN r.last_reminded_at IS NULL
+          THEN GREATEST(sm.joined_at, p.created_at) + make_interval(hours => p.first_after_hours) <= @now::timestamptz
+        ELSE r.last_reminded_at + make_interval(hours => p.repeat_every_hours) <= @now::timestamptz
+      END
+ORDER BY sm.split_id
+LIMIT 500;
+
+-- name: ClaimSplitReminder :one
+-- Counts a reminder for a member only if nobody else has since @reminders_sent was read,
+-- so concurrent workers never send the same reminder twice.
+INSERT INTO split_member_reminders (split_id, user_id, reminders_sent, last_reminded_at)
+VALUES (@split_id, @user_id, 1, NOW())
+ON CONFLICT (split_id, user_id) DO UPDATE
+SET reminders_sent = split_member_reminders.reminders_sent + 1,
+    last_reminded_at = NOW()
+WHERE split_member_reminders.reminders_sent = @reminders_sent::int
+RETURNING *;
@@ -35,3 +35,17 @@ UPDATE splits
 SET payment_instructions = $2, updated_at = NOW()
 WHERE id = $1
 RETURNING *;
+
+-- name: UpdateSplitPayoutMethod :one
+UPDATE splits
+SET payout_method_id = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: TransferSplitHost :one
+-- Makes another member the host, and so the one who is owed. The old host's payout
+-- details no longer apply.
+UPDATE splits
+SET created_by = $2, payout_method_id = NULL, payment_instructions = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
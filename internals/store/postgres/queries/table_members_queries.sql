@@ -63,9 +63,9 @@ WHERE user_id = $1
 ORDER BY table_id, joined_at DESC;
 
 -- name: UpdateMemberRoleInTable :one
--- Updates the role of a user within a specific table.
+-- Updates the role of a user within a specific table, and for co-hosts what they may do.
 UPDATE table_members
-SET role = $3
+SET role = $3, permissions = $4
 WHERE table_id = $1 AND user_id = $2
 RETURNING *;
 
@@ -97,12 +97,11 @@ SELECT
     tm.joined_at,
     tm.role,
     tm.is_settled,
+    tm.permissions,
     u.email AS user_email,
     u.name AS user_name,
     u.profile_picture_url AS user_profile_picture_url,
-    u.bank_name AS user_bank_name,
-    u.account_name AS user_account_name,
-    u.account_number AS user_account_number
+    u.is_placeholder AS user_is_placeholder
 FROM table_members tm
 JOIN users u ON tm.user_id = u.id
 WHERE tm.table_id = $1
@@ -138,4 +137,24 @@ GROUP BY
     tm.role,
     tm.is_settled,
     tm.joined_at
-ORDER BY t.created_at DESC, tm.joined_at DESC;
\ No newline at end of file
+ORDER BY t.created_at DESC, tm.joined_at DESC;
+-- name: ListTablesHostedByUser :many
+SELECT table_id
+FROM table_members
+WHERE user_id = $1 AND role = 'host';
+
+-- name: NextTableHost :one
+-- Picks who takes over a table when its host deletes their account: the longest-standing
+-- co-host, or failing that the longest-standing member with an account.
+SELECT tm.user_id
+FROM table_members tm
+JOIN users u ON u.id = tm.user_id
+WHERE tm.table_id = @table_id AND tm.user_id <> @user_id AND NOT u.is_placeholder
+ORDER BY tm.role = 'cohost' DESC, tm.joined_at ASC
+LIMIT 1;
+
+-- name: DemoteUserInTables :exec
+-- Makes the user a plain guest at every table, for when they delete their account.
+UPDATE table_members
+SET role = 'guest', permissions = '{}'
+WHERE user_id = $1 AND role <> 'guest';
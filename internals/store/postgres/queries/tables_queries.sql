@@ -99,12 +99,19 @@ WHERE table_code = $1
 RETURNING url_extract_count;
 
 -- name: ListTableGuestsForReminder :many
--- Returns guest members of a table with their push tokens for payment reminders
+-- Returns guest members of a table, and whether they can be reached by push or email, for payment reminders.
+-- Push only counts devices seen in the last 90 days, like ListActiveDeviceTokens.
 SELECT
     tm.user_id,
     u.name AS user_name,
-    u.push_token
+    (u.email <> '' OR EXISTS (SELECT 1 FROM device_tokens d WHERE d.user_id = u.id AND d.last_seen_at > NOW() - INTERVAL '90 days')) AS reachable
 FROM table_members tm
 JOIN users u ON tm.user_id = u.id
 JOIN tables t ON tm.table_id = t.id
-WHERE t.table_code = $1 AND tm.role = 'guest';
\ No newline at end of file
+WHERE t.table_code = $1 AND tm.role <> 'host';
+-- name: TransferTableHost :one
+-- Makes another member the host of a table.
+UPDATE tables
+SET created_by = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
@@ -66,20 +66,96 @@ WHERE id = $1;
 DELETE FROM users
 WHERE cognito_sub = $1;
 
--- name: SearchUsersByName :many
-SELECT id, name, email, profile_picture_url FROM users
-WHERE name ILIKE '%' || $1 || '%'
-  AND id != $2
-ORDER BY name ASC
-LIMIT 10;
-
--- name: UpdateUserPushToken :exec
-UPDATE users
-SET push_token = $1, updated_at = NOW()
-WHERE id = $2;
-
 -- name: UpdateBankDetails :exec
 UPDATE users
 SET bank_name = $1, account_name = $2, account_number = $3, updated_at = NOW()
 WHERE id = $4;
 
+
+-- name: ListUsersWithBankDetails :many
+SELECT id, bank_name, account_name, account_number FROM users
+WHERE bank_name IS NOT NULL OR account_name IS NOT NULL OR account_number IS NOT NULL
+ORDER BY id;
+
+-- name: UpdateUserTimezone :exec
+UPDATE users
+SET timezone = $1, updated_at = NOW()
+WHERE id = $2;
+
+-- name: AnonymizeUser :one
+-- Strips everything that identifies a user who deleted their account. The row stays on
+-- as a placeholder so their memberships, balances and items still add up.
+UPDATE users
+SET
+    name = @name,
+    profile_picture_url = NULL,
+    cognito_sub = 'deleted:' || id,
+    email = '',
+    bank_name = NULL,
+    account_name = NULL,
+    account_number = NULL,
+    username = NULL,
+    phone_number = NULL,
+    phone_verified_at = NULL,
+    is_placeholder = TRUE,
+    updated_at = NOW()
+WHERE
+    id = @id
+RETURNING *;
+
+-- name: FindUserByEmail :one
+-- Exact lookups for adding someone. Placeholders are not returned, nor are phone
+-- numbers that haven't been verified; the caller checks the user's discoverability.
+SELECT * FROM users
+WHERE LOWER(email) = LOWER(@email::text)
+  AND id <> @user_id
+  AND NOT is_placeholder;
+
+-- name: FindUserByPhone :one
+SELECT * FROM users
+WHERE phone_number = @phone_number::text
+  AND phone_verified_at IS NOT NULL
+  AND id <> @user_id
+  AND NOT is_placeholder;
+
+-- name: FindUserByUsername :one
+SELECT * FROM users
+WHERE LOWER(username) = LOWER(@username::text)
+  AND id <> @user_id
+  AND NOT is_placeholder;
+
+-- name: UpdateUsername :one
+-- Sets or, with NULL, clears the username. Fails on the unique index if it is taken.
+UPDATE users
+SET username = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateDiscoverability :one
+UPDATE users
+SET
+    discoverable_by_email = $2,
+    discoverable_by_phone = $3,
+    discoverable_by_username = $4,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetVerifiedPhoneNumber :one
+UPDATE users
+SET phone_number = $2, phone_verified_at = NOW(), updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ReleasePhoneNumber :exec
+-- Takes a verified number off whoever else had it, for when a number has been
+-- passed on to someone new.
+UPDATE users
+SET phone_number = NULL, phone_verified_at = NULL, updated_at = NOW()
+WHERE phone_number = $1 AND id <> $2;
+
+-- name: ClearPhoneNumber :one
+UPDATE users
+SET phone_number = NULL, phone_verified_at = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
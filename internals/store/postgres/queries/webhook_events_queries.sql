@@ -0,0 +1,27 @@
+-- name: RecordWebhookEvent :one
+-- Inserts a delivery, or bumps the attempt count when the provider redelivers the same event.
+INSERT INTO webhook_events (source, event_id, event_type, payload)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (source, event_id) DO UPDATE
+SET attempts = webhook_events.attempts + 1
+RETURNING *;
+
+-- name: ClaimWebhookEvent :execrows
+-- Marks an event as being handled. Nothing is updated if it was already processed or
+-- another delivery claimed it in the last five minutes; older claims belong to a
+-- delivery that died before finishing.
+UPDATE webhook_events
+SET status = 'processing', claimed_at = NOW()
+WHERE id = $1
+  AND status <> 'processed'
+  AND (status <> 'processing' OR claimed_at < NOW() - INTERVAL '5 minutes');
+
+-- name: MarkWebhookEventProcessed :exec
+UPDATE webhook_events
+SET status = 'processed', processed_at = NOW(), last_error = NULL
+WHERE id = $1;
+
+-- name: MarkWebhookEventFailed :exec
+UPDATE webhook_events
+SET status = 'failed', last_error = $2
+WHERE id = $1;
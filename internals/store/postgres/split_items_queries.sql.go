@@ -258,6 +258,49 @@ func (q *Queries) ListClaimsForSplit(ctx context.Context, splitID pgtype.UUID) (
 	return items, nil
 }
 
+const listSplitClaimsForUser = `-- name: ListSplitClaimsForUser :many
+SELECT s.split_code, si.name AS item_name, si.price, c.quantity_claimed, c.claimed_at
+FROM split_item_claims c
+JOIN split_items si ON si.id = c.split_item_id
+JOIN splits s ON s.id = si.split_id
+WHERE c.claimed_by_user_id = $1
+ORDER BY c.claimed_at
+`
+
+type ListSplitClaimsForUserRow struct {
+	SplitCode       string             `json:"split_code"`
+	ItemName        string             `json:"item_name"`
+	Price           pgtype.Numeric     `json:"price"`
+	QuantityClaimed int32              `json:"quantity_claimed"`
+	ClaimedAt       pgtype.Timestamptz `json:"claimed_at"`
+}
+
+func (q *Queries) ListSplitClaimsForUser(ctx context.Context, claimedByUserID pgtype.UUID) ([]ListSplitClaimsForUserRow, error) {
+	rows, err := q.db.Query(ctx, listSplitClaimsForUser, claimedByUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSplitClaimsForUserRow{}
+	for rows.Next() {
+		var i ListSplitClaimsForUserRow
+		if err := rows.Scan(
+			&i.SplitCode,
+			&i.ItemName,
+			&i.Price,
+			&i.QuantityClaimed,
+			&i.ClaimedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listSplitItems = `-- name: ListSplitItems :many
 SELECT id, split_id, name, price, quantity, remaining_qty, added_by_user_id, created_at FROM split_items WHERE split_id = $1 ORDER BY created_at ASC
 `
@@ -326,7 +369,7 @@ SET
     split_type    = 'receipt',
     updated_at    = NOW()
 WHERE id = $1
-RETURNING id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions
+RETURNING id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions, payout_method_id
 `
 
 type UpdateSplitReceiptDetailsParams struct {
@@ -362,12 +405,13 @@ func (q *Queries) UpdateSplitReceiptDetails(ctx context.Context, arg UpdateSplit
 		&i.TipIsShared,
 		&i.SplitType,
 		&i.PaymentInstructions,
+		&i.PayoutMethodID,
 	)
 	return i, err
 }
 
 const updateSplitTotalAmount = `-- name: UpdateSplitTotalAmount :one
-UPDATE splits SET total_amount = $2, updated_at = NOW() WHERE id = $1 RETURNING id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions
+UPDATE splits SET total_amount = $2, updated_at = NOW() WHERE id = $1 RETURNING id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions, payout_method_id
 `
 
 type UpdateSplitTotalAmountParams struct {
@@ -394,6 +438,7 @@ func (q *Queries) UpdateSplitTotalAmount(ctx context.Context, arg UpdateSplitTot
 		&i.TipIsShared,
 		&i.SplitType,
 		&i.PaymentInstructions,
+		&i.PayoutMethodID,
 	)
 	return i, err
 }
@@ -14,7 +14,7 @@ import (
 const addUserToSplit = `-- name: AddUserToSplit :one
 INSERT INTO split_members (split_id, user_id, amount_owed, role)
 VALUES ($1, $2, $3, $4)
-RETURNING split_id, user_id, amount_owed, is_settled, settled_at, role, joined_at, payment_status
+RETURNING split_id, user_id, amount_owed, is_settled, settled_at, role, joined_at, payment_status, permissions
 `
 
 type AddUserToSplitParams struct {
@@ -41,6 +41,7 @@ func (q *Queries) AddUserToSplit(ctx context.Context, arg AddUserToSplitParams)
 		&i.Role,
 		&i.JoinedAt,
 		&i.PaymentStatus,
+		&i.Permissions,
 	)
 	return i, err
 }
@@ -49,7 +50,7 @@ const confirmSplitMemberPayment = `-- name: ConfirmSplitMemberPayment :one
 UPDATE split_members
 SET payment_status = 'confirmed', is_settled = TRUE, settled_at = NOW()
 WHERE split_id = $1 AND user_id = $2
-RETURNING split_id, user_id, amount_owed, is_settled, settled_at, role, joined_at, payment_status
+RETURNING split_id, user_id, amount_owed, is_settled, settled_at, role, joined_at, payment_status, permissions
 `
 
 type ConfirmSplitMemberPaymentParams struct {
@@ -69,6 +70,7 @@ func (q *Queries) ConfirmSplitMemberPayment(ctx context.Context, arg ConfirmSpli
 		&i.Role,
 		&i.JoinedAt,
 		&i.PaymentStatus,
+		&i.Permissions,
 	)
 	return i, err
 }
@@ -85,8 +87,20 @@ func (q *Queries) CountUnsettledSplitMembers(ctx context.Context, splitID pgtype
 	return count, err
 }
 
+const demoteUserInSplits = `-- name: DemoteUserInSplits :exec
+UPDATE split_members
+SET role = 'guest', permissions = '{}'
+WHERE user_id = $1 AND role <> 'guest'
+`
+
+// Makes the user a plain guest in every split, for when they delete their account.
+func (q *Queries) DemoteUserInSplits(ctx context.Context, userID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, demoteUserInSplits, userID)
+	return err
+}
+
 const getSplitMember = `-- name: GetSplitMember :one
-SELECT split_id, user_id, amount_owed, is_settled, settled_at, role, joined_at, payment_status FROM split_members
+SELECT split_id, user_id, amount_owed, is_settled, settled_at, role, joined_at, payment_status, permissions FROM split_members
 WHERE split_id = $1 AND user_id = $2
 `
 
@@ -107,12 +121,70 @@ func (q *Queries) GetSplitMember(ctx context.Context, arg GetSplitMemberParams)
 		&i.Role,
 		&i.JoinedAt,
 		&i.PaymentStatus,
+		&i.Permissions,
 	)
 	return i, err
 }
 
+const listOutstandingBalancesForUser = `-- name: ListOutstandingBalancesForUser :many
+SELECT s.split_code, s.name AS split_name, 'owes'::text AS direction, sm.amount_owed AS amount
+FROM split_members sm
+JOIN splits s ON s.id = sm.split_id
+WHERE sm.user_id = $1
+  AND sm.role <> 'host'
+  AND sm.payment_status <> 'confirmed'
+  AND sm.amount_owed > 0
+  AND s.status <> 'settled'
+UNION ALL
+SELECT s.split_code, s.name AS split_name, 'owed'::text AS direction, SUM(sm.amount_owed)::numeric AS amount
+FROM splits s
+JOIN split_members sm ON sm.split_id = s.id
+WHERE s.created_by = $1
+  AND sm.user_id <> $1
+  AND sm.role <> 'host'
+  AND sm.payment_status <> 'confirmed'
+  AND sm.amount_owed > 0
+  AND s.status <> 'settled'
+GROUP BY s.id, s.split_code, s.name
+ORDER BY split_code
+`
+
+type ListOutstandingBalancesForUserRow struct {
+	SplitCode string         `json:"split_code"`
+	SplitName string         `json:"split_name"`
+	Direction string         `json:"direction"`
+	Amount    pgtype.Numeric `json:"amount"`
+}
+
+// Money still to change hands on the user's open splits: what they owe on splits they
+// haven't paid, and what other members still owe them on splits they host.
+func (q *Queries) ListOutstandingBalancesForUser(ctx context.Context, userID pgtype.UUID) ([]ListOutstandingBalancesForUserRow, error) {
+	rows, err := q.db.Query(ctx, listOutstandingBalancesForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOutstandingBalancesForUserRow{}
+	for rows.Next() {
+		var i ListOutstandingBalancesForUserRow
+		if err := rows.Scan(
+			&i.SplitCode,
+			&i.SplitName,
+			&i.Direction,
+			&i.Amount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listSplitMembersBySplitID = `-- name: ListSplitMembersBySplitID :many
-SELECT split_id, user_id, amount_owed, is_settled, settled_at, role, joined_at, payment_status FROM split_members
+SELECT split_id, user_id, amount_owed, is_settled, settled_at, role, joined_at, payment_status, permissions FROM split_members
 WHERE split_id = $1
 ORDER BY joined_at ASC
 `
@@ -135,6 +207,7 @@ func (q *Queries) ListSplitMembersBySplitID(ctx context.Context, splitID pgtype.
 			&i.Role,
 			&i.JoinedAt,
 			&i.PaymentStatus,
+			&i.Permissions,
 		); err != nil {
 			return nil, err
 		}
@@ -156,9 +229,11 @@ SELECT
     sm.role,
     sm.joined_at,
     sm.payment_status,
+    sm.permissions,
     u.email AS user_email,
     u.name AS user_name,
-    u.profile_picture_url AS user_profile_picture_url
+    u.profile_picture_url AS user_profile_picture_url,
+    u.is_placeholder AS user_is_placeholder
 FROM split_members sm
 JOIN users u ON sm.user_id = u.id
 WHERE sm.split_id = $1
@@ -174,9 +249,11 @@ type ListSplitMembersWithUserDetailsRow struct {
 	Role                  string             `json:"role"`
 	JoinedAt              pgtype.Timestamptz `json:"joined_at"`
 	PaymentStatus         string             `json:"payment_status"`
+	Permissions           []string           `json:"permissions"`
 	UserEmail             string             `json:"user_email"`
 	UserName              pgtype.Text        `json:"user_name"`
 	UserProfilePictureUrl pgtype.Text        `json:"user_profile_picture_url"`
+	UserIsPlaceholder     bool               `json:"user_is_placeholder"`
 }
 
 // Get all members of a split with their user info
@@ -198,9 +275,11 @@ func (q *Queries) ListSplitMembersWithUserDetails(ctx context.Context, splitID p
 			&i.Role,
 			&i.JoinedAt,
 			&i.PaymentStatus,
+			&i.Permissions,
 			&i.UserEmail,
 			&i.UserName,
 			&i.UserProfilePictureUrl,
+			&i.UserIsPlaceholder,
 		); err != nil {
 			return nil, err
 		}
@@ -278,25 +357,52 @@ func (q *Queries) ListSplitsForUser(ctx context.Context, userID pgtype.UUID) ([]
 	return items, nil
 }
 
+const listSplitsHostedByUser = `-- name: ListSplitsHostedByUser :many
+SELECT split_id
+FROM split_members
+WHERE user_id = $1 AND role = 'host'
+`
+
+func (q *Queries) ListSplitsHostedByUser(ctx context.Context, userID pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listSplitsHostedByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var split_id pgtype.UUID
+		if err := rows.Scan(&split_id); err != nil {
+			return nil, err
+		}
+		items = append(items, split_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUnsettledSplitMembersForReminder = `-- name: ListUnsettledSplitMembersForReminder :many
 SELECT
     sm.user_id,
     u.name AS user_name,
     sm.amount_owed,
-    u.push_token
+    (u.email <> '' OR EXISTS (SELECT 1 FROM device_tokens d WHERE d.user_id = u.id AND d.last_seen_at > NOW() - INTERVAL '90 days')) AS reachable
 FROM split_members sm
 JOIN users u ON sm.user_id = u.id
-WHERE sm.split_id = $1 AND sm.is_settled = FALSE AND sm.role = 'guest'
+WHERE sm.split_id = $1 AND sm.is_settled = FALSE AND sm.role <> 'host'
 `
 
 type ListUnsettledSplitMembersForReminderRow struct {
 	UserID     pgtype.UUID    `json:"user_id"`
 	UserName   pgtype.Text    `json:"user_name"`
 	AmountOwed pgtype.Numeric `json:"amount_owed"`
-	PushToken  pgtype.Text    `json:"push_token"`
+	Reachable  bool           `json:"reachable"`
 }
 
-// Returns unsettled guest members with their push tokens for sending reminders
+// Returns unsettled members other than the host, and whether they can be reached by push or email, for sending reminders.
+// Push only counts devices seen in the last 90 days, like ListActiveDeviceTokens.
 func (q *Queries) ListUnsettledSplitMembersForReminder(ctx context.Context, splitID pgtype.UUID) ([]ListUnsettledSplitMembersForReminderRow, error) {
 	rows, err := q.db.Query(ctx, listUnsettledSplitMembersForReminder, splitID)
 	if err != nil {
@@ -310,7 +416,7 @@ func (q *Queries) ListUnsettledSplitMembersForReminder(ctx context.Context, spli
 			&i.UserID,
 			&i.UserName,
 			&i.AmountOwed,
-			&i.PushToken,
+			&i.Reachable,
 		); err != nil {
 			return nil, err
 		}
@@ -322,6 +428,29 @@ func (q *Queries) ListUnsettledSplitMembersForReminder(ctx context.Context, spli
 	return items, nil
 }
 
+const nextSplitHost = `-- name: NextSplitHost :one
+SELECT sm.user_id
+FROM split_members sm
+JOIN users u ON u.id = sm.user_id
+WHERE sm.split_id = $1 AND sm.user_id <> $2 AND NOT u.is_placeholder
+ORDER BY sm.role = 'cohost' DESC, sm.joined_at ASC
+LIMIT 1
+`
+
+type NextSplitHostParams struct {
+	SplitID pgtype.UUID `json:"split_id"`
+	UserID  pgtype.UUID `json:"user_id"`
+}
+
+// Picks who takes over a split when its host leaves: the longest-standing co-host, or
+// failing that the longest-standing member with an account.
+func (q *Queries) NextSplitHost(ctx context.Context, arg NextSplitHostParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, nextSplitHost, arg.SplitID, arg.UserID)
+	var user_id pgtype.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
 const recalculateSplitForAllMembers = `-- name: RecalculateSplitForAllMembers :exec
 UPDATE split_members sm
 SET amount_owed = (
@@ -375,7 +504,7 @@ const updateSplitMemberPaymentStatus = `-- name: UpdateSplitMemberPaymentStatus
 UPDATE split_members
 SET payment_status = $3
 WHERE split_id = $1 AND user_id = $2
-RETURNING split_id, user_id, amount_owed, is_settled, settled_at, role, joined_at, payment_status
+RETURNING split_id, user_id, amount_owed, is_settled, settled_at, role, joined_at, payment_status, permissions
 `
 
 type UpdateSplitMemberPaymentStatusParams struct {
@@ -396,6 +525,44 @@ func (q *Queries) UpdateSplitMemberPaymentStatus(ctx context.Context, arg Update
 		&i.Role,
 		&i.JoinedAt,
 		&i.PaymentStatus,
+		&i.Permissions,
+	)
+	return i, err
+}
+
+const updateSplitMemberRole = `-- name: UpdateSplitMemberRole :one
+UPDATE split_members
+SET role = $1, permissions = $2
+WHERE split_id = $3 AND user_id = $4
+RETURNING split_id, user_id, amount_owed, is_settled, settled_at, role, joined_at, payment_status, permissions
+`
+
+type UpdateSplitMemberRoleParams struct {
+	Role        string      `json:"role"`
+	Permissions []string    `json:"permissions"`
+	SplitID     pgtype.UUID `json:"split_id"`
+	UserID      pgtype.UUID `json:"user_id"`
+}
+
+// Sets a member's role and, for co-hosts, what they may do.
+func (q *Queries) UpdateSplitMemberRole(ctx context.Context, arg UpdateSplitMemberRoleParams) (SplitMembers, error) {
+	row := q.db.QueryRow(ctx, updateSplitMemberRole,
+		arg.Role,
+		arg.Permissions,
+		arg.SplitID,
+		arg.UserID,
+	)
+	var i SplitMembers
+	err := row.Scan(
+		&i.SplitID,
+		&i.UserID,
+		&i.AmountOwed,
+		&i.IsSettled,
+		&i.SettledAt,
+		&i.Role,
+		&i.JoinedAt,
+		&i.PaymentStatus,
+		&i.Permissions,
 	)
 	return i, err
 }
@@ -406,7 +573,7 @@ SET
     is_settled = $3,
     settled_at = CASE WHEN $3 = TRUE THEN NOW() ELSE NULL END
 WHERE split_id = $1 AND user_id = $2
-RETURNING split_id, user_id, amount_owed, is_settled, settled_at, role, joined_at, payment_status
+RETURNING split_id, user_id, amount_owed, is_settled, settled_at, role, joined_at, payment_status, permissions
 `
 
 type UpdateSplitMemberSettledStatusParams struct {
@@ -427,6 +594,33 @@ func (q *Queries) UpdateSplitMemberSettledStatus(ctx context.Context, arg Update
 		&i.Role,
 		&i.JoinedAt,
 		&i.PaymentStatus,
+		&i.Permissions,
 	)
 	return i, err
 }
+
+const userOwesUser = `-- name: UserOwesUser :one
+SELECT EXISTS (
+    SELECT 1
+    FROM split_members sm
+    JOIN splits s ON s.id = sm.split_id
+    WHERE sm.user_id = $1
+      AND s.created_by = $2
+      AND sm.role <> 'host'
+      AND sm.payment_status <> 'confirmed'
+      AND sm.amount_owed > 0
+)
+`
+
+type UserOwesUserParams struct {
+	DebtorID   pgtype.UUID `json:"debtor_id"`
+	CreditorID pgtype.UUID `json:"creditor_id"`
+}
+
+// Reports whether the debtor still owes money on a split created by the creditor.
+func (q *Queries) UserOwesUser(ctx context.Context, arg UserOwesUserParams) (bool, error) {
+	row := q.db.QueryRow(ctx, userOwesUser, arg.DebtorID, arg.CreditorID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
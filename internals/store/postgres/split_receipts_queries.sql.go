@@ -11,6 +11,34 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const deleteSplitReceiptsByCreator = `-- name: DeleteSplitReceiptsByCreator :many
+DELETE FROM split_receipts
+WHERE created_by = $1
+RETURNING object_key
+`
+
+// Removes the receipts a user uploaded and returns their storage keys so the images
+// can be deleted too. The parsed split items stay.
+func (q *Queries) DeleteSplitReceiptsByCreator(ctx context.Context, createdBy pgtype.UUID) ([]string, error) {
+	rows, err := q.db.Query(ctx, deleteSplitReceiptsByCreator, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var object_key string
+		if err := rows.Scan(&object_key); err != nil {
+			return nil, err
+		}
+		items = append(items, object_key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSplitReceiptBySplitID = `-- name: GetSplitReceiptBySplitID :one
 SELECT id, split_id, object_key, image_url, media_type, original_filename, created_by, created_at, updated_at
 FROM split_receipts
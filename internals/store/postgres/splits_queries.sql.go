@@ -25,7 +25,7 @@ func (q *Queries) CountOpenSplits(ctx context.Context) (int64, error) {
 const createSplit = `-- name: CreateSplit :one
 INSERT INTO splits (created_by, split_code, name, description, total_amount, status)
 VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions
+RETURNING id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions, payout_method_id
 `
 
 type CreateSplitParams struct {
@@ -63,6 +63,7 @@ func (q *Queries) CreateSplit(ctx context.Context, arg CreateSplitParams) (Split
 		&i.TipIsShared,
 		&i.SplitType,
 		&i.PaymentInstructions,
+		&i.PayoutMethodID,
 	)
 	return i, err
 }
@@ -77,7 +78,7 @@ func (q *Queries) DeleteSplitByCode(ctx context.Context, splitCode string) error
 }
 
 const getSplitByCode = `-- name: GetSplitByCode :one
-SELECT id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions FROM splits WHERE split_code = $1
+SELECT id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions, payout_method_id FROM splits WHERE split_code = $1
 `
 
 func (q *Queries) GetSplitByCode(ctx context.Context, splitCode string) (Splits, error) {
@@ -99,12 +100,13 @@ func (q *Queries) GetSplitByCode(ctx context.Context, splitCode string) (Splits,
 		&i.TipIsShared,
 		&i.SplitType,
 		&i.PaymentInstructions,
+		&i.PayoutMethodID,
 	)
 	return i, err
 }
 
 const getSplitByID = `-- name: GetSplitByID :one
-SELECT id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions FROM splits WHERE id = $1
+SELECT id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions, payout_method_id FROM splits WHERE id = $1
 `
 
 func (q *Queries) GetSplitByID(ctx context.Context, id pgtype.UUID) (Splits, error) {
@@ -126,12 +128,13 @@ func (q *Queries) GetSplitByID(ctx context.Context, id pgtype.UUID) (Splits, err
 		&i.TipIsShared,
 		&i.SplitType,
 		&i.PaymentInstructions,
+		&i.PayoutMethodID,
 	)
 	return i, err
 }
 
 const listSplitsByUserID = `-- name: ListSplitsByUserID :many
-SELECT id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions FROM splits WHERE created_by = $1 ORDER BY created_at DESC
+SELECT id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions, payout_method_id FROM splits WHERE created_by = $1 ORDER BY created_at DESC
 `
 
 func (q *Queries) ListSplitsByUserID(ctx context.Context, createdBy pgtype.UUID) ([]Splits, error) {
@@ -159,6 +162,7 @@ func (q *Queries) ListSplitsByUserID(ctx context.Context, createdBy pgtype.UUID)
 			&i.TipIsShared,
 			&i.SplitType,
 			&i.PaymentInstructions,
+			&i.PayoutMethodID,
 		); err != nil {
 			return nil, err
 		}
@@ -170,8 +174,46 @@ func (q *Queries) ListSplitsByUserID(ctx context.Context, createdBy pgtype.UUID)
 	return items, nil
 }
 
+const transferSplitHost = `-- name: TransferSplitHost :one
+UPDATE splits
+SET created_by = $2, payout_method_id = NULL, payment_instructions = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions, payout_method_id
+`
+
+type TransferSplitHostParams struct {
+	ID        pgtype.UUID `json:"id"`
+	CreatedBy pgtype.UUID `json:"created_by"`
+}
+
+// Makes another member the host, and so the one who is owed. The old host's payout
+// details no longer apply.
+func (q *Queries) TransferSplitHost(ctx context.Context, arg TransferSplitHostParams) (Splits, error) {
+	row := q.db.QueryRow(ctx, transferSplitHost, arg.ID, arg.CreatedBy)
+	var i Splits
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedBy,
+		&i.SplitCode,
+		&i.Name,
+		&i.Description,
+		&i.TotalAmount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SettledAt,
+		&i.TaxAmount,
+		&i.TipAmount,
+		&i.TipIsShared,
+		&i.SplitType,
+		&i.PaymentInstructions,
+		&i.PayoutMethodID,
+	)
+	return i, err
+}
+
 const updateSplitAmount = `-- name: UpdateSplitAmount :one
-UPDATE splits SET total_amount = $2, updated_at = NOW() WHERE id = $1 RETURNING id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions
+UPDATE splits SET total_amount = $2, updated_at = NOW() WHERE id = $1 RETURNING id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions, payout_method_id
 `
 
 type UpdateSplitAmountParams struct {
@@ -198,6 +240,7 @@ func (q *Queries) UpdateSplitAmount(ctx context.Context, arg UpdateSplitAmountPa
 		&i.TipIsShared,
 		&i.SplitType,
 		&i.PaymentInstructions,
+		&i.PayoutMethodID,
 	)
 	return i, err
 }
@@ -206,7 +249,7 @@ const updateSplitPaymentInstructions = `-- name: UpdateSplitPaymentInstructions
 UPDATE splits
 SET payment_instructions = $2, updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions
+RETURNING id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions, payout_method_id
 `
 
 type UpdateSplitPaymentInstructionsParams struct {
@@ -233,6 +276,43 @@ func (q *Queries) UpdateSplitPaymentInstructions(ctx context.Context, arg Update
 		&i.TipIsShared,
 		&i.SplitType,
 		&i.PaymentInstructions,
+		&i.PayoutMethodID,
+	)
+	return i, err
+}
+
+const updateSplitPayoutMethod = `-- name: UpdateSplitPayoutMethod :one
+UPDATE splits
+SET payout_method_id = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions, payout_method_id
+`
+
+type UpdateSplitPayoutMethodParams struct {
+	ID             pgtype.UUID `json:"id"`
+	PayoutMethodID pgtype.UUID `json:"payout_method_id"`
+}
+
+func (q *Queries) UpdateSplitPayoutMethod(ctx context.Context, arg UpdateSplitPayoutMethodParams) (Splits, error) {
+	row := q.db.QueryRow(ctx, updateSplitPayoutMethod, arg.ID, arg.PayoutMethodID)
+	var i Splits
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedBy,
+		&i.SplitCode,
+		&i.Name,
+		&i.Description,
+		&i.TotalAmount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SettledAt,
+		&i.TaxAmount,
+		&i.TipAmount,
+		&i.TipIsShared,
+		&i.SplitType,
+		&i.PaymentInstructions,
+		&i.PayoutMethodID,
 	)
 	return i, err
 }
@@ -244,7 +324,7 @@ SET
     settled_at = CASE WHEN $1::text = 'settled' THEN NOW() ELSE settled_at END,
     updated_at = NOW()
 WHERE id = $2
-RETURNING id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions
+RETURNING id, created_by, split_code, name, description, total_amount, status, created_at, updated_at, settled_at, tax_amount, tip_amount, tip_is_shared, split_type, payment_instructions, payout_method_id
 `
 
 type UpdateSplitStatusParams struct {
@@ -271,6 +351,7 @@ func (q *Queries) UpdateSplitStatus(ctx context.Context, arg UpdateSplitStatusPa
 		&i.TipIsShared,
 		&i.SplitType,
 		&i.PaymentInstructions,
+		&i.PayoutMethodID,
 	)
 	return i, err
 }
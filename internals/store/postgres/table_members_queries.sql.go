@@ -20,7 +20,7 @@ INSERT INTO table_members (
 ) VALUES (
     $1, $2, $3
 )
-RETURNING table_id, user_id, joined_at, role, is_settled
+RETURNING table_id, user_id, joined_at, role, is_settled, permissions
 `
 
 type AddUserToTableParams struct {
@@ -39,6 +39,7 @@ func (q *Queries) AddUserToTable(ctx context.Context, arg AddUserToTableParams)
 		&i.JoinedAt,
 		&i.Role,
 		&i.IsSettled,
+		&i.Permissions,
 	)
 	return i, err
 }
@@ -76,6 +77,18 @@ func (q *Queries) CountMembersInTable(ctx context.Context, tableID pgtype.UUID)
 	return count, err
 }
 
+const demoteUserInTables = `-- name: DemoteUserInTables :exec
+UPDATE table_members
+SET role = 'guest', permissions = '{}'
+WHERE user_id = $1 AND role <> 'guest'
+`
+
+// Makes the user a plain guest at every table, for when they delete their account.
+func (q *Queries) DemoteUserInTables(ctx context.Context, userID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, demoteUserInTables, userID)
+	return err
+}
+
 const getMemberRoleInTable = `-- name: GetMemberRoleInTable :one
 
 SELECT role FROM table_members
@@ -101,7 +114,7 @@ func (q *Queries) GetMemberRoleInTable(ctx context.Context, arg GetMemberRoleInT
 }
 
 const getTableMember = `-- name: GetTableMember :one
-SELECT table_id, user_id, joined_at, role, is_settled FROM table_members
+SELECT table_id, user_id, joined_at, role, is_settled, permissions FROM table_members
 WHERE table_id = $1 AND user_id = $2
 `
 
@@ -120,12 +133,13 @@ func (q *Queries) GetTableMember(ctx context.Context, arg GetTableMemberParams)
 		&i.JoinedAt,
 		&i.Role,
 		&i.IsSettled,
+		&i.Permissions,
 	)
 	return i, err
 }
 
 const getTableMembershipDetailsForUser = `-- name: GetTableMembershipDetailsForUser :many
-SELECT table_id, user_id, joined_at, role, is_settled FROM table_members
+SELECT table_id, user_id, joined_at, role, is_settled, permissions FROM table_members
 WHERE user_id = $1
 ORDER BY table_id, joined_at DESC
 `
@@ -146,6 +160,7 @@ func (q *Queries) GetTableMembershipDetailsForUser(ctx context.Context, userID p
 			&i.JoinedAt,
 			&i.Role,
 			&i.IsSettled,
+			&i.Permissions,
 		); err != nil {
 			return nil, err
 		}
@@ -158,7 +173,7 @@ func (q *Queries) GetTableMembershipDetailsForUser(ctx context.Context, userID p
 }
 
 const listMembersByTableID = `-- name: ListMembersByTableID :many
-SELECT table_id, user_id, joined_at, role, is_settled FROM table_members
+SELECT table_id, user_id, joined_at, role, is_settled, permissions FROM table_members
 WHERE table_id = $1
 ORDER BY joined_at ASC
 `
@@ -179,6 +194,7 @@ func (q *Queries) ListMembersByTableID(ctx context.Context, tableID pgtype.UUID)
 			&i.JoinedAt,
 			&i.Role,
 			&i.IsSettled,
+			&i.Permissions,
 		); err != nil {
 			return nil, err
 		}
@@ -197,12 +213,11 @@ SELECT
     tm.joined_at,
     tm.role,
     tm.is_settled,
+    tm.permissions,
     u.email AS user_email,
     u.name AS user_name,
     u.profile_picture_url AS user_profile_picture_url,
-    u.bank_name AS user_bank_name,
-    u.account_name AS user_account_name,
-    u.account_number AS user_account_number
+    u.is_placeholder AS user_is_placeholder
 FROM table_members tm
 JOIN users u ON tm.user_id = u.id
 WHERE tm.table_id = $1
@@ -215,12 +230,11 @@ type ListMembersWithUserDetailsByTableIDRow struct {
 	JoinedAt              pgtype.Timestamptz `json:"joined_at"`
 	Role                  string             `json:"role"`
 	IsSettled             bool               `json:"is_settled"`
+	Permissions           []string           `json:"permissions"`
 	UserEmail             string             `json:"user_email"`
 	UserName              pgtype.Text        `json:"user_name"`
 	UserProfilePictureUrl pgtype.Text        `json:"user_profile_picture_url"`
-	UserBankName          pgtype.Text        `json:"user_bank_name"`
-	UserAccountName       pgtype.Text        `json:"user_account_name"`
-	UserAccountNumber     pgtype.Text        `json:"user_account_number"`
+	UserIsPlaceholder     bool               `json:"user_is_placeholder"`
 }
 
 // Retrieves all members of a specific table_id and include their user details.
@@ -239,12 +253,11 @@ func (q *Queries) ListMembersWithUserDetailsByTableID(ctx context.Context, table
 			&i.JoinedAt,
 			&i.Role,
 			&i.IsSettled,
+			&i.Permissions,
 			&i.UserEmail,
 			&i.UserName,
 			&i.UserProfilePictureUrl,
-			&i.UserBankName,
-			&i.UserAccountName,
-			&i.UserAccountNumber,
+			&i.UserIsPlaceholder,
 		); err != nil {
 			return nil, err
 		}
@@ -257,7 +270,7 @@ func (q *Queries) ListMembersWithUserDetailsByTableID(ctx context.Context, table
 }
 
 const listSettledMembersInTable = `-- name: ListSettledMembersInTable :many
-SELECT table_id, user_id, joined_at, role, is_settled FROM table_members
+SELECT table_id, user_id, joined_at, role, is_settled, permissions FROM table_members
 WHERE table_id = $1 AND is_settled = TRUE
 ORDER BY joined_at ASC
 `
@@ -278,6 +291,7 @@ func (q *Queries) ListSettledMembersInTable(ctx context.Context, tableID pgtype.
 			&i.JoinedAt,
 			&i.Role,
 			&i.IsSettled,
+			&i.Permissions,
 		); err != nil {
 			return nil, err
 		}
@@ -289,6 +303,32 @@ func (q *Queries) ListSettledMembersInTable(ctx context.Context, tableID pgtype.
 	return items, nil
 }
 
+const listTablesHostedByUser = `-- name: ListTablesHostedByUser :many
+SELECT table_id
+FROM table_members
+WHERE user_id = $1 AND role = 'host'
+`
+
+func (q *Queries) ListTablesHostedByUser(ctx context.Context, userID pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listTablesHostedByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var table_id pgtype.UUID
+		if err := rows.Scan(&table_id); err != nil {
+			return nil, err
+		}
+		items = append(items, table_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTablesWithMembershipStatusForUser = `-- name: ListTablesWithMembershipStatusForUser :many
 SELECT
     t.id AS table_id,
@@ -371,7 +411,7 @@ func (q *Queries) ListTablesWithMembershipStatusForUser(ctx context.Context, use
 }
 
 const listUnsettledMembersInTable = `-- name: ListUnsettledMembersInTable :many
-SELECT table_id, user_id, joined_at, role, is_settled FROM table_members
+SELECT table_id, user_id, joined_at, role, is_settled, permissions FROM table_members
 WHERE table_id = $1 AND is_settled = FALSE
 ORDER BY joined_at ASC
 `
@@ -392,6 +432,7 @@ func (q *Queries) ListUnsettledMembersInTable(ctx context.Context, tableID pgtyp
 			&i.JoinedAt,
 			&i.Role,
 			&i.IsSettled,
+			&i.Permissions,
 		); err != nil {
 			return nil, err
 		}
@@ -407,7 +448,7 @@ const markAllMembersInTableAsSettled = `-- name: MarkAllMembersInTableAsSettled
 UPDATE table_members
 SET is_settled = TRUE
 WHERE table_id = $1
-RETURNING table_id, user_id, joined_at, role, is_settled
+RETURNING table_id, user_id, joined_at, role, is_settled, permissions
 `
 
 // Sets is_settled to true for all members of a specific table.
@@ -427,6 +468,7 @@ func (q *Queries) MarkAllMembersInTableAsSettled(ctx context.Context, tableID pg
 			&i.JoinedAt,
 			&i.Role,
 			&i.IsSettled,
+			&i.Permissions,
 		); err != nil {
 			return nil, err
 		}
@@ -438,6 +480,29 @@ func (q *Queries) MarkAllMembersInTableAsSettled(ctx context.Context, tableID pg
 	return items, nil
 }
 
+const nextTableHost = `-- name: NextTableHost :one
+SELECT tm.user_id
+FROM table_members tm
+JOIN users u ON u.id = tm.user_id
+WHERE tm.table_id = $1 AND tm.user_id <> $2 AND NOT u.is_placeholder
+ORDER BY tm.role = 'cohost' DESC, tm.joined_at ASC
+LIMIT 1
+`
+
+type NextTableHostParams struct {
+	TableID pgtype.UUID `json:"table_id"`
+	UserID  pgtype.UUID `json:"user_id"`
+}
+
+// Picks who takes over a table when its host deletes their account: the longest-standing
+// co-host, or failing that the longest-standing member with an account.
+func (q *Queries) NextTableHost(ctx context.Context, arg NextTableHostParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, nextTableHost, arg.TableID, arg.UserID)
+	var user_id pgtype.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
 const removeUserFromTable = `-- name: RemoveUserFromTable :exec
 DELETE FROM table_members
 WHERE table_id = $1 AND user_id = $2
@@ -458,7 +523,7 @@ const setMemberSettledStatus = `-- name: SetMemberSettledStatus :one
 UPDATE table_members
 SET is_settled = $3
 WHERE table_id = $1 AND user_id = $2
-RETURNING table_id, user_id, joined_at, role, is_settled
+RETURNING table_id, user_id, joined_at, role, is_settled, permissions
 `
 
 type SetMemberSettledStatusParams struct {
@@ -477,26 +542,33 @@ func (q *Queries) SetMemberSettledStatus(ctx context.Context, arg SetMemberSettl
 		&i.JoinedAt,
 		&i.Role,
 		&i.IsSettled,
+		&i.Permissions,
 	)
 	return i, err
 }
 
 const updateMemberRoleInTable = `-- name: UpdateMemberRoleInTable :one
 UPDATE table_members
-SET role = $3
+SET role = $3, permissions = $4
 WHERE table_id = $1 AND user_id = $2
-RETURNING table_id, user_id, joined_at, role, is_settled
+RETURNING table_id, user_id, joined_at, role, is_settled, permissions
 `
 
 type UpdateMemberRoleInTableParams struct {
-	TableID pgtype.UUID `json:"table_id"`
-	UserID  pgtype.UUID `json:"user_id"`
-	Role    string      `json:"role"`
+	TableID     pgtype.UUID `json:"table_id"`
+	UserID      pgtype.UUID `json:"user_id"`
+	Role        string      `json:"role"`
+	Permissions []string    `json:"permissions"`
 }
 
-// Updates the role of a user within a specific table.
+// Updates the role of a user within a specific table, and for co-hosts what they may do.
 func (q *Queries) UpdateMemberRoleInTable(ctx context.Context, arg UpdateMemberRoleInTableParams) (TableMembers, error) {
-	row := q.db.QueryRow(ctx, updateMemberRoleInTable, arg.TableID, arg.UserID, arg.Role)
+	row := q.db.QueryRow(ctx, updateMemberRoleInTable,
+		arg.TableID,
+		arg.UserID,
+		arg.Role,
+		arg.Permissions,
+	)
 	var i TableMembers
 	err := row.Scan(
 		&i.TableID,
@@ -504,6 +576,7 @@ func (q *Queries) UpdateMemberRoleInTable(ctx context.Context, arg UpdateMemberR
 		&i.JoinedAt,
 		&i.Role,
 		&i.IsSettled,
+		&i.Permissions,
 	)
 	return i, err
 }
@@ -203,20 +203,21 @@ const listTableGuestsForReminder = `-- name: ListTableGuestsForReminder :many
 SELECT
     tm.user_id,
     u.name AS user_name,
-    u.push_token
+    (u.email <> '' OR EXISTS (SELECT 1 FROM device_tokens d WHERE d.user_id = u.id AND d.last_seen_at > NOW() - INTERVAL '90 days')) AS reachable
 FROM table_members tm
 JOIN users u ON tm.user_id = u.id
 JOIN tables t ON tm.table_id = t.id
-WHERE t.table_code = $1 AND tm.role = 'guest'
+WHERE t.table_code = $1 AND tm.role <> 'host'
 `
 
 type ListTableGuestsForReminderRow struct {
 	UserID    pgtype.UUID `json:"user_id"`
 	UserName  pgtype.Text `json:"user_name"`
-	PushToken pgtype.Text `json:"push_token"`
+	Reachable bool        `json:"reachable"`
 }
 
-// Returns guest members of a table with their push tokens for payment reminders
+// Returns guest members of a table, and whether they can be reached by push or email, for payment reminders.
+// Push only counts devices seen in the last 90 days, like ListActiveDeviceTokens.
 func (q *Queries) ListTableGuestsForReminder(ctx context.Context, tableCode string) ([]ListTableGuestsForReminderRow, error) {
 	rows, err := q.db.Query(ctx, listTableGuestsForReminder, tableCode)
 	if err != nil {
@@ -226,7 +227,7 @@ func (q *Queries) ListTableGuestsForReminder(ctx context.Context, tableCode stri
 	items := []ListTableGuestsForReminderRow{}
 	for rows.Next() {
 		var i ListTableGuestsForReminderRow
-		if err := rows.Scan(&i.UserID, &i.UserName, &i.PushToken); err != nil {
+		if err := rows.Scan(&i.UserID, &i.UserName, &i.Reachable); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -359,6 +360,40 @@ func (q *Queries) SearchTablesByNameOrRestaurant(ctx context.Context, dollar_1 p
 	return items, nil
 }
 
+const transferTableHost = `-- name: TransferTableHost :one
+UPDATE tables
+SET created_by = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_by, table_code, name, restaurant_name, status, menu_url, vat, created_at, updated_at, closed_at, scanned_menu, url_extract_count
+`
+
+type TransferTableHostParams struct {
+	ID        pgtype.UUID `json:"id"`
+	CreatedBy pgtype.UUID `json:"created_by"`
+}
+
+// Makes another member the host of a table.
+func (q *Queries) TransferTableHost(ctx context.Context, arg TransferTableHostParams) (Tables, error) {
+	row := q.db.QueryRow(ctx, transferTableHost, arg.ID, arg.CreatedBy)
+	var i Tables
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedBy,
+		&i.TableCode,
+		&i.Name,
+		&i.RestaurantName,
+		&i.Status,
+		&i.MenuUrl,
+		&i.Vat,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClosedAt,
+		&i.ScannedMenu,
+		&i.UrlExtractCount,
+	)
+	return i, err
+}
+
 const updateTableMenuURL = `-- name: UpdateTableMenuURL :one
 UPDATE tables
 SET menu_url = $2, updated_at = NOW()
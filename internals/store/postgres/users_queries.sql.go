@@ -11,6 +11,59 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const anonymizeUser = `-- name: AnonymizeUser :one
+UPDATE users
+SET
+    name = $1,
+    profile_picture_url = NULL,
+    cognito_sub = 'deleted:' || id,
+    email = '',
+    bank_name = NULL,
+    account_name = NULL,
+    account_number = NULL,
+    username = NULL,
+    phone_number = NULL,
+    phone_verified_at = NULL,
+    is_placeholder = TRUE,
+    updated_at = NOW()
+WHERE
+    id = $2
+RETURNING id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, bank_name, account_name, account_number, timezone, is_placeholder, username, phone_number, phone_verified_at, discoverable_by_email, discoverable_by_phone, discoverable_by_username
+`
+
+type AnonymizeUserParams struct {
+	Name pgtype.Text `json:"name"`
+	ID   pgtype.UUID `json:"id"`
+}
+
+// Strips everything that identifies a user who deleted their account. The row stays on
+// as a placeholder so their memberships, balances and items still add up.
+func (q *Queries) AnonymizeUser(ctx context.Context, arg AnonymizeUserParams) (Users, error) {
+	row := q.db.QueryRow(ctx, anonymizeUser, arg.Name, arg.ID)
+	var i Users
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ProfilePictureUrl,
+		&i.CognitoSub,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.BankName,
+		&i.AccountName,
+		&i.AccountNumber,
+		&i.Timezone,
+		&i.IsPlaceholder,
+		&i.Username,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.DiscoverableByEmail,
+		&i.DiscoverableByPhone,
+		&i.DiscoverableByUsername,
+	)
+	return i, err
+}
+
 const checkIfCognitoSubExists = `-- name: CheckIfCognitoSubExists :one
 SELECT EXISTS (
     SELECT 1 FROM users
@@ -39,10 +92,43 @@ func (q *Queries) CheckIfEmailExists(ctx context.Context, email string) (bool, e
 	return exists, err
 }
 
+const clearPhoneNumber = `-- name: ClearPhoneNumber :one
+UPDATE users
+SET phone_number = NULL, phone_verified_at = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, bank_name, account_name, account_number, timezone, is_placeholder, username, phone_number, phone_verified_at, discoverable_by_email, discoverable_by_phone, discoverable_by_username
+`
+
+func (q *Queries) ClearPhoneNumber(ctx context.Context, id pgtype.UUID) (Users, error) {
+	row := q.db.QueryRow(ctx, clearPhoneNumber, id)
+	var i Users
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ProfilePictureUrl,
+		&i.CognitoSub,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.BankName,
+		&i.AccountName,
+		&i.AccountNumber,
+		&i.Timezone,
+		&i.IsPlaceholder,
+		&i.Username,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.DiscoverableByEmail,
+		&i.DiscoverableByPhone,
+		&i.DiscoverableByUsername,
+	)
+	return i, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (name, profile_picture_url, cognito_sub,  email)
 VALUES ($1, $2, $3, $4)
-RETURNING id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, push_token, bank_name, account_name, account_number
+RETURNING id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, bank_name, account_name, account_number, timezone, is_placeholder, username, phone_number, phone_verified_at, discoverable_by_email, discoverable_by_phone, discoverable_by_username
 `
 
 type CreateUserParams struct {
@@ -68,10 +154,17 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (Users,
 		&i.Email,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.PushToken,
 		&i.BankName,
 		&i.AccountName,
 		&i.AccountNumber,
+		&i.Timezone,
+		&i.IsPlaceholder,
+		&i.Username,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.DiscoverableByEmail,
+		&i.DiscoverableByPhone,
+		&i.DiscoverableByUsername,
 	)
 	return i, err
 }
@@ -96,8 +189,125 @@ func (q *Queries) DeleteUserByID(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const findUserByEmail = `-- name: FindUserByEmail :one
+SELECT id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, bank_name, account_name, account_number, timezone, is_placeholder, username, phone_number, phone_verified_at, discoverable_by_email, discoverable_by_phone, discoverable_by_username FROM users
+WHERE LOWER(email) = LOWER($1::text)
+  AND id <> $2
+  AND NOT is_placeholder
+`
+
+type FindUserByEmailParams struct {
+	Email  string      `json:"email"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+// Exact lookups for adding someone. Placeholders are not returned, nor are phone
+// numbers that haven't been verified; the caller checks the user's discoverability.
+func (q *Queries) FindUserByEmail(ctx context.Context, arg FindUserByEmailParams) (Users, error) {
+	row := q.db.QueryRow(ctx, findUserByEmail, arg.Email, arg.UserID)
+	var i Users
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ProfilePictureUrl,
+		&i.CognitoSub,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.BankName,
+		&i.AccountName,
+		&i.AccountNumber,
+		&i.Timezone,
+		&i.IsPlaceholder,
+		&i.Username,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.DiscoverableByEmail,
+		&i.DiscoverableByPhone,
+		&i.DiscoverableByUsername,
+	)
+	return i, err
+}
+
+const findUserByPhone = `-- name: FindUserByPhone :one
+SELECT id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, bank_name, account_name, account_number, timezone, is_placeholder, username, phone_number, phone_verified_at, discoverable_by_email, discoverable_by_phone, discoverable_by_username FROM users
+WHERE phone_number = $1::text
+  AND phone_verified_at IS NOT NULL
+  AND id <> $2
+  AND NOT is_placeholder
+`
+
+type FindUserByPhoneParams struct {
+	PhoneNumber string      `json:"phone_number"`
+	UserID      pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) FindUserByPhone(ctx context.Context, arg FindUserByPhoneParams) (Users, error) {
+	row := q.db.QueryRow(ctx, findUserByPhone, arg.PhoneNumber, arg.UserID)
+	var i Users
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ProfilePictureUrl,
+		&i.CognitoSub,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.BankName,
+		&i.AccountName,
+		&i.AccountNumber,
+		&i.Timezone,
+		&i.IsPlaceholder,
+		&i.Username,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.DiscoverableByEmail,
+		&i.DiscoverableByPhone,
+		&i.DiscoverableByUsername,
+	)
+	return i, err
+}
+
+const findUserByUsername = `-- name: FindUserByUsername :one
+SELECT id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, bank_name, account_name, account_number, timezone, is_placeholder, username, phone_number, phone_verified_at, discoverable_by_email, discoverable_by_phone, discoverable_by_username FROM users
+WHERE LOWER(username) = LOWER($1::text)
+  AND id <> $2
+  AND NOT is_placeholder
+`
+
+type FindUserByUsernameParams struct {
+	Username string      `json:"username"`
+	UserID   pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) FindUserByUsername(ctx context.Context, arg FindUserByUsernameParams) (Users, error) {
+	row := q.db.QueryRow(ctx, findUserByUsername, arg.Username, arg.UserID)
+	var i Users
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ProfilePictureUrl,
+		&i.CognitoSub,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.BankName,
+		&i.AccountName,
+		&i.AccountNumber,
+		&i.Timezone,
+		&i.IsPlaceholder,
+		&i.Username,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.DiscoverableByEmail,
+		&i.DiscoverableByPhone,
+		&i.DiscoverableByUsername,
+	)
+	return i, err
+}
+
 const getUserByCognitoSub = `-- name: GetUserByCognitoSub :one
-SELECT id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, push_token, bank_name, account_name, account_number FROM users
+SELECT id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, bank_name, account_name, account_number, timezone, is_placeholder, username, phone_number, phone_verified_at, discoverable_by_email, discoverable_by_phone, discoverable_by_username FROM users
 WHERE cognito_sub = $1
 `
 
@@ -112,16 +322,23 @@ func (q *Queries) GetUserByCognitoSub(ctx context.Context, cognitoSub string) (U
 		&i.Email,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.PushToken,
 		&i.BankName,
 		&i.AccountName,
 		&i.AccountNumber,
+		&i.Timezone,
+		&i.IsPlaceholder,
+		&i.Username,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.DiscoverableByEmail,
+		&i.DiscoverableByPhone,
+		&i.DiscoverableByUsername,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, push_token, bank_name, account_name, account_number FROM users
+SELECT id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, bank_name, account_name, account_number, timezone, is_placeholder, username, phone_number, phone_verified_at, discoverable_by_email, discoverable_by_phone, discoverable_by_username FROM users
 WHERE email = $1
 `
 
@@ -136,16 +353,23 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (Users, erro
 		&i.Email,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.PushToken,
 		&i.BankName,
 		&i.AccountName,
 		&i.AccountNumber,
+		&i.Timezone,
+		&i.IsPlaceholder,
+		&i.Username,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.DiscoverableByEmail,
+		&i.DiscoverableByPhone,
+		&i.DiscoverableByUsername,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, push_token, bank_name, account_name, account_number FROM users
+SELECT id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, bank_name, account_name, account_number, timezone, is_placeholder, username, phone_number, phone_verified_at, discoverable_by_email, discoverable_by_phone, discoverable_by_username FROM users
 WHERE id = $1
 `
 
@@ -160,16 +384,23 @@ func (q *Queries) GetUserByID(ctx context.Context, id pgtype.UUID) (Users, error
 		&i.Email,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.PushToken,
 		&i.BankName,
 		&i.AccountName,
 		&i.AccountNumber,
+		&i.Timezone,
+		&i.IsPlaceholder,
+		&i.Username,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.DiscoverableByEmail,
+		&i.DiscoverableByPhone,
+		&i.DiscoverableByUsername,
 	)
 	return i, err
 }
 
 const listAllUsers = `-- name: ListAllUsers :many
-SELECT id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, push_token, bank_name, account_name, account_number FROM users
+SELECT id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, bank_name, account_name, account_number, timezone, is_placeholder, username, phone_number, phone_verified_at, discoverable_by_email, discoverable_by_phone, discoverable_by_username FROM users
 `
 
 func (q *Queries) ListAllUsers(ctx context.Context) ([]Users, error) {
@@ -189,10 +420,17 @@ func (q *Queries) ListAllUsers(ctx context.Context) ([]Users, error) {
 			&i.Email,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.PushToken,
 			&i.BankName,
 			&i.AccountName,
 			&i.AccountNumber,
+			&i.Timezone,
+			&i.IsPlaceholder,
+			&i.Username,
+			&i.PhoneNumber,
+			&i.PhoneVerifiedAt,
+			&i.DiscoverableByEmail,
+			&i.DiscoverableByPhone,
+			&i.DiscoverableByUsername,
 		); err != nil {
 			return nil, err
 		}
@@ -204,40 +442,33 @@ func (q *Queries) ListAllUsers(ctx context.Context) ([]Users, error) {
 	return items, nil
 }
 
-const searchUsersByName = `-- name: SearchUsersByName :many
-SELECT id, name, email, profile_picture_url FROM users
-WHERE name ILIKE '%' || $1 || '%'
-  AND id != $2
-ORDER BY name ASC
-LIMIT 10
+const listUsersWithBankDetails = `-- name: ListUsersWithBankDetails :many
+SELECT id, bank_name, account_name, account_number FROM users
+WHERE bank_name IS NOT NULL OR account_name IS NOT NULL OR account_number IS NOT NULL
+ORDER BY id
 `
 
-type SearchUsersByNameParams struct {
-	Column1 pgtype.Text `json:"column_1"`
-	ID      pgtype.UUID `json:"id"`
-}
-
-type SearchUsersByNameRow struct {
-	ID                pgtype.UUID `json:"id"`
-	Name              pgtype.Text `json:"name"`
-	Email             string      `json:"email"`
-	ProfilePictureUrl pgtype.Text `json:"profile_picture_url"`
+type ListUsersWithBankDetailsRow struct {
+	ID            pgtype.UUID `json:"id"`
+	BankName      pgtype.Text `json:"bank_name"`
+	AccountName   pgtype.Text `json:"account_name"`
+	AccountNumber pgtype.Text `json:"account_number"`
 }
 
-func (q *Queries) SearchUsersByName(ctx context.Context, arg SearchUsersByNameParams) ([]SearchUsersByNameRow, error) {
-	rows, err := q.db.Query(ctx, searchUsersByName, arg.Column1, arg.ID)
+func (q *Queries) ListUsersWithBankDetails(ctx context.Context) ([]ListUsersWithBankDetailsRow, error) {
+	rows, err := q.db.Query(ctx, listUsersWithBankDetails)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []SearchUsersByNameRow{}
+	items := []ListUsersWithBankDetailsRow{}
 	for rows.Next() {
-		var i SearchUsersByNameRow
+		var i ListUsersWithBankDetailsRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.Email,
-			&i.ProfilePictureUrl,
+			&i.BankName,
+			&i.AccountName,
+			&i.AccountNumber,
 		); err != nil {
 			return nil, err
 		}
@@ -249,6 +480,62 @@ func (q *Queries) SearchUsersByName(ctx context.Context, arg SearchUsersByNamePa
 	return items, nil
 }
 
+const releasePhoneNumber = `-- name: ReleasePhoneNumber :exec
+UPDATE users
+SET phone_number = NULL, phone_verified_at = NULL, updated_at = NOW()
+WHERE phone_number = $1 AND id <> $2
+`
+
+type ReleasePhoneNumberParams struct {
+	PhoneNumber pgtype.Text `json:"phone_number"`
+	ID          pgtype.UUID `json:"id"`
+}
+
+// Takes a verified number off whoever else had it, for when a number has been
+// passed on to someone new.
+func (q *Queries) ReleasePhoneNumber(ctx context.Context, arg ReleasePhoneNumberParams) error {
+	_, err := q.db.Exec(ctx, releasePhoneNumber, arg.PhoneNumber, arg.ID)
+	return err
+}
+
+const setVerifiedPhoneNumber = `-- name: SetVerifiedPhoneNumber :one
+UPDATE users
+SET phone_number = $2, phone_verified_at = NOW(), updated_at = NOW()
+WHERE id = $1
+RETURNING id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, bank_name, account_name, account_number, timezone, is_placeholder, username, phone_number, phone_verified_at, discoverable_by_email, discoverable_by_phone, discoverable_by_username
+`
+
+type SetVerifiedPhoneNumberParams struct {
+	ID          pgtype.UUID `json:"id"`
+	PhoneNumber pgtype.Text `json:"phone_number"`
+}
+
+func (q *Queries) SetVerifiedPhoneNumber(ctx context.Context, arg SetVerifiedPhoneNumberParams) (Users, error) {
+	row := q.db.QueryRow(ctx, setVerifiedPhoneNumber, arg.ID, arg.PhoneNumber)
+	var i Users
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ProfilePictureUrl,
+		&i.CognitoSub,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.BankName,
+		&i.AccountName,
+		&i.AccountNumber,
+		&i.Timezone,
+		&i.IsPlaceholder,
+		&i.Username,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.DiscoverableByEmail,
+		&i.DiscoverableByPhone,
+		&i.DiscoverableByUsername,
+	)
+	return i, err
+}
+
 const updateBankDetails = `-- name: UpdateBankDetails :exec
 UPDATE users
 SET bank_name = $1, account_name = $2, account_number = $3, updated_at = NOW()
@@ -272,6 +559,55 @@ func (q *Queries) UpdateBankDetails(ctx context.Context, arg UpdateBankDetailsPa
 	return err
 }
 
+const updateDiscoverability = `-- name: UpdateDiscoverability :one
+UPDATE users
+SET
+    discoverable_by_email = $2,
+    discoverable_by_phone = $3,
+    discoverable_by_username = $4,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, bank_name, account_name, account_number, timezone, is_placeholder, username, phone_number, phone_verified_at, discoverable_by_email, discoverable_by_phone, discoverable_by_username
+`
+
+type UpdateDiscoverabilityParams struct {
+	ID                     pgtype.UUID `json:"id"`
+	DiscoverableByEmail    string      `json:"discoverable_by_email"`
+	DiscoverableByPhone    string      `json:"discoverable_by_phone"`
+	DiscoverableByUsername string      `json:"discoverable_by_username"`
+}
+
+func (q *Queries) UpdateDiscoverability(ctx context.Context, arg UpdateDiscoverabilityParams) (Users, error) {
+	row := q.db.QueryRow(ctx, updateDiscoverability,
+		arg.ID,
+		arg.DiscoverableByEmail,
+		arg.DiscoverableByPhone,
+		arg.DiscoverableByUsername,
+	)
+	var i Users
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ProfilePictureUrl,
+		&i.CognitoSub,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.BankName,
+		&i.AccountName,
+		&i.AccountNumber,
+		&i.Timezone,
+		&i.IsPlaceholder,
+		&i.Username,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.DiscoverableByEmail,
+		&i.DiscoverableByPhone,
+		&i.DiscoverableByUsername,
+	)
+	return i, err
+}
+
 const updateUserEmail = `-- name: UpdateUserEmail :one
 UPDATE users
 SET
@@ -279,7 +615,7 @@ SET
     updated_at = NOW()
 WHERE
     id = $1
-RETURNING id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, push_token, bank_name, account_name, account_number
+RETURNING id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, bank_name, account_name, account_number, timezone, is_placeholder, username, phone_number, phone_verified_at, discoverable_by_email, discoverable_by_phone, discoverable_by_username
 `
 
 type UpdateUserEmailParams struct {
@@ -298,10 +634,17 @@ func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams
 		&i.Email,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.PushToken,
 		&i.BankName,
 		&i.AccountName,
 		&i.AccountNumber,
+		&i.Timezone,
+		&i.IsPlaceholder,
+		&i.Username,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.DiscoverableByEmail,
+		&i.DiscoverableByPhone,
+		&i.DiscoverableByUsername,
 	)
 	return i, err
 }
@@ -313,7 +656,7 @@ SET
     updated_at = NOW()
 WHERE
     id = $1
-RETURNING id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, push_token, bank_name, account_name, account_number
+RETURNING id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, bank_name, account_name, account_number, timezone, is_placeholder, username, phone_number, phone_verified_at, discoverable_by_email, discoverable_by_phone, discoverable_by_username
 `
 
 type UpdateUserNameParams struct {
@@ -333,10 +676,56 @@ func (q *Queries) UpdateUserName(ctx context.Context, arg UpdateUserNameParams)
 		&i.Email,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.PushToken,
 		&i.BankName,
 		&i.AccountName,
 		&i.AccountNumber,
+		&i.Timezone,
+		&i.IsPlaceholder,
+		&i.Username,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.DiscoverableByEmail,
+		&i.DiscoverableByPhone,
+		&i.DiscoverableByUsername,
+	)
+	return i, err
+}
+
+const updateUsername = `-- name: UpdateUsername :one
+UPDATE users
+SET username = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, bank_name, account_name, account_number, timezone, is_placeholder, username, phone_number, phone_verified_at, discoverable_by_email, discoverable_by_phone, discoverable_by_username
+`
+
+type UpdateUsernameParams struct {
+	ID       pgtype.UUID `json:"id"`
+	Username pgtype.Text `json:"username"`
+}
+
+// Sets or, with NULL, clears the username. Fails on the unique index if it is taken.
+func (q *Queries) UpdateUsername(ctx context.Context, arg UpdateUsernameParams) (Users, error) {
+	row := q.db.QueryRow(ctx, updateUsername, arg.ID, arg.Username)
+	var i Users
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.ProfilePictureUrl,
+		&i.CognitoSub,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.BankName,
+		&i.AccountName,
+		&i.AccountNumber,
+		&i.Timezone,
+		&i.IsPlaceholder,
+		&i.Username,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.DiscoverableByEmail,
+		&i.DiscoverableByPhone,
+		&i.DiscoverableByUsername,
 	)
 	return i, err
 }
@@ -348,7 +737,7 @@ SET
     updated_at = NOW()
 WHERE
     id = $1
-RETURNING id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, push_token, bank_name, account_name, account_number
+RETURNING id, name, profile_picture_url, cognito_sub, email, created_at, updated_at, bank_name, account_name, account_number, timezone, is_placeholder, username, phone_number, phone_verified_at, discoverable_by_email, discoverable_by_phone, discoverable_by_username
 `
 
 type UpdateUserProfilePictureURLParams struct {
@@ -367,26 +756,33 @@ func (q *Queries) UpdateUserProfilePictureURL(ctx context.Context, arg UpdateUse
 		&i.Email,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.PushToken,
 		&i.BankName,
 		&i.AccountName,
 		&i.AccountNumber,
+		&i.Timezone,
+		&i.IsPlaceholder,
+		&i.Username,
+		&i.PhoneNumber,
+		&i.PhoneVerifiedAt,
+		&i.DiscoverableByEmail,
+		&i.DiscoverableByPhone,
+		&i.DiscoverableByUsername,
 	)
 	return i, err
 }
 
-const updateUserPushToken = `-- name: UpdateUserPushToken :exec
+const updateUserTimezone = `-- name: UpdateUserTimezone :exec
 UPDATE users
-SET push_token = $1, updated_at = NOW()
+SET timezone = $1, updated_at = NOW()
 WHERE id = $2
 `
 
-type UpdateUserPushTokenParams struct {
-	PushToken pgtype.Text `json:"push_token"`
-	ID        pgtype.UUID `json:"id"`
+type UpdateUserTimezoneParams struct {
+	Timezone string      `json:"timezone"`
+	ID       pgtype.UUID `json:"id"`
 }
 
-func (q *Queries) UpdateUserPushToken(ctx context.Context, arg UpdateUserPushTokenParams) error {
-	_, err := q.db.Exec(ctx, updateUserPushToken, arg.PushToken, arg.ID)
+func (q *Queries) UpdateUserTimezone(ctx context.Context, arg UpdateUserTimezoneParams) error {
+	_, err := q.db.Exec(ctx, updateUserTimezone, arg.Timezone, arg.ID)
 	return err
 }
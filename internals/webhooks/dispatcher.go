@@ -0,0 +1,145 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	tabmate "tabmate/internals/store/postgres"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// ErrUnknownSource is returned for deliveries to a provider nobody registered.
+	ErrUnknownSource = errors.New("unknown webhook source")
+	// ErrMalformedPayload is returned when a verified body cannot be decoded.
+	ErrMalformedPayload = errors.New("malformed webhook payload")
+	// ErrInProgress is returned when another delivery of the same event is being
+	// handled. The provider should retry later.
+	ErrInProgress = errors.New("webhook event is already being processed")
+)
+
+// Event is a verified delivery normalised by its Source.
+type Event struct {
+	Source string
+	ID     string
+	Type   string
+	// Data holds the provider-specific decoded payload for the registered handler.
+	Data any
+}
+
+// Source authenticates and decodes deliveries from a single provider.
+type Source interface {
+	Name() string
+	// ParseWebhook must verify the delivery before decoding it.
+	ParseWebhook(header http.Header, body []byte) (*Event, error)
+}
+
+// HandlerFunc applies a verified event to TabMate state. Returning an error marks
+// the event failed so that the provider's retry is processed again.
+type HandlerFunc func(ctx context.Context, event *Event) error
+
+// Store persists deliveries so each provider event is applied at most once.
+type Store interface {
+	RecordWebhookEvent(ctx context.Context, arg tabmate.RecordWebhookEventParams) (tabmate.WebhookEvents, error)
+	ClaimWebhookEvent(ctx context.Context, id pgtype.UUID) (int64, error)
+	MarkWebhookEventProcessed(ctx context.Context, id pgtype.UUID) error
+	MarkWebhookEventFailed(ctx context.Context, arg tabmate.MarkWebhookEventFailedParams) error
+}
+
+// Result describes what Handle did with a delivery.
+type Result string
+
+const (
+	ResultProcessed Result = "processed"
+	ResultDuplicate Result = "duplicate"
+)
+
+type registration struct {
+	source  Source
+	handler HandlerFunc
+}
+
+// Dispatcher routes inbound deliveries to the handler registered for their source.
+type Dispatcher struct {
+	store   Store
+	mu      sync.RWMutex
+	sources map[string]registration
+}
+
+func NewDispatcher(store Store) *Dispatcher {
+	return &Dispatcher{
+		store:   store,
+		sources: make(map[string]registration),
+	}
+}
+
+// Register attaches a handler to every event parsed by source.
+func (d *Dispatcher) Register(source Source, handler HandlerFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sources[source.Name()] = registration{source: source, handler: handler}
+}
+
+// Handle verifies, records and dispatches one delivery. Events that were already
+// processed are acknowledged without running the handler again. The event is claimed
+// before the handler runs, so of two concurrent deliveries only one applies it; the
+// other gets ErrInProgress.
+func (d *Dispatcher) Handle(ctx context.Context, sourceName string, header http.Header, body []byte) (Result, error) {
+	d.mu.RLock()
+	reg, ok := d.sources[sourceName]
+	d.mu.RUnlock()
+	if !ok {
+		return "", ErrUnknownSource
+	}
+
+	event, err := reg.source.ParseWebhook(header, body)
+	if err != nil {
+		if errors.Is(err, ErrInvalidSignature) || errors.Is(err, ErrStaleTimestamp) {
+			return "", err
+		}
+		return "", fmt.Errorf("%w: %v", ErrMalformedPayload, err)
+	}
+	if event.ID == "" {
+		return "", fmt.Errorf("%w: missing event id", ErrMalformedPayload)
+	}
+
+	record, err := d.store.RecordWebhookEvent(ctx, tabmate.RecordWebhookEventParams{
+		Source:    sourceName,
+		EventID:   event.ID,
+		EventType: event.Type,
+		Payload:   body,
+	})
+	if err != nil {
+		return "", fmt.Errorf("record webhook event: %w", err)
+	}
+	if record.Status == "processed" {
+		log.Printf("[webhooks] duplicate %s event %s ignored", sourceName, event.ID)
+		return ResultDuplicate, nil
+	}
+	claimed, err := d.store.ClaimWebhookEvent(ctx, record.ID)
+	if err != nil {
+		return "", fmt.Errorf("claim webhook event: %w", err)
+	}
+	if claimed == 0 {
+		return "", ErrInProgress
+	}
+
+	if err := reg.handler(ctx, event); err != nil {
+		if markErr := d.store.MarkWebhookEventFailed(ctx, tabmate.MarkWebhookEventFailedParams{
+			ID:        record.ID,
+			LastError: pgtype.Text{String: err.Error(), Valid: true},
+		}); markErr != nil {
+			log.Printf("[webhooks] failed to mark %s event %s failed: %v", sourceName, event.ID, markErr)
+		}
+		return "", fmt.Errorf("handle %s event %s: %w", sourceName, event.ID, err)
+	}
+
+	if err := d.store.MarkWebhookEventProcessed(ctx, record.ID); err != nil {
+		log.Printf("[webhooks] failed to mark %s event %s processed: %v", sourceName, event.ID, err)
+	}
+	return ResultProcessed, nil
+}
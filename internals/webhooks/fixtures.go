@@ -0,0 +1,42 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Fixture is a recorded provider delivery used to replay webhooks locally.
+// Bodies are re-signed at replay time so fixtures never contain real secrets.
+type Fixture struct {
+	Name        string          `json:"-"`
+	Source      string          `json:"source"`
+	Description string          `json:"description"`
+	Body        json.RawMessage `json:"body"`
+}
+
+// LoadFixtures reads every *.json fixture in dir, sorted by file name.
+func LoadFixtures(dir string) ([]Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	fixtures := make([]Fixture, 0, len(paths))
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var fixture Fixture
+		if err := json.Unmarshal(raw, &fixture); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		fixture.Name = filepath.Base(path)
+		fixtures = append(fixtures, fixture)
+	}
+	return fixtures, nil
+}
@@ -0,0 +1,226 @@
+package webhooks_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"tabmate/internals/auth"
+	"tabmate/internals/payments"
+	tabmate "tabmate/internals/store/postgres"
+	"tabmate/internals/webhooks"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type memoryStore struct {
+	events map[string]*tabmate.WebhookEvents
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{events: make(map[string]*tabmate.WebhookEvents)}
+}
+
+func (s *memoryStore) RecordWebhookEvent(_ context.Context, arg tabmate.RecordWebhookEventParams) (tabmate.WebhookEvents, error) {
+	key := arg.Source + "/" + arg.EventID
+	if event, ok := s.events[key]; ok {
+		event.Attempts++
+		return *event, nil
+	}
+	event := &tabmate.WebhookEvents{
+		ID:        pgtype.UUID{Bytes: [16]byte{byte(len(s.events) + 1)}, Valid: true},
+		Source:    arg.Source,
+		EventID:   arg.EventID,
+		EventType: arg.EventType,
+		Payload:   arg.Payload,
+		Status:    "received",
+		Attempts:  1,
+	}
+	s.events[key] = event
+	return *event, nil
+}
+
+func (s *memoryStore) ClaimWebhookEvent(_ context.Context, id pgtype.UUID) (int64, error) {
+	for _, event := range s.events {
+		if event.ID == id && event.Status != "processed" && event.Status != "processing" {
+			event.Status = "processing"
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (s *memoryStore) MarkWebhookEventProcessed(_ context.Context, id pgtype.UUID) error {
+	for _, event := range s.events {
+		if event.ID == id {
+			event.Status = "processed"
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) MarkWebhookEventFailed(_ context.Context, arg tabmate.MarkWebhookEventFailedParams) error {
+	for _, event := range s.events {
+		if event.ID == arg.ID {
+			event.Status = "failed"
+			event.LastError = arg.LastError
+		}
+	}
+	return nil
+}
+
+// clerkReplaySecret is a Svix endpoint secret: "whsec_" and a base64 key.
+const clerkReplaySecret = "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"
+
+var verifiers = map[string]webhooks.Signer{
+	"paystack": payments.PaystackWebhookVerifier("sk_test_replay"),
+	"fake":     payments.FakeWebhookVerifier("fake-replay"),
+	"clerk":    auth.ClerkWebhookVerifier(clerkReplaySecret),
+}
+
+func newDispatcher(store webhooks.Store, handled map[string]int, fail *bool) *webhooks.Dispatcher {
+	dispatcher := webhooks.NewDispatcher(store)
+	handler := func(_ context.Context, event *webhooks.Event) error {
+		if *fail {
+			return errors.New("database unavailable")
+		}
+		switch event.Data.(type) {
+		case *payments.WebhookEvent, *auth.ClerkUserEvent:
+		default:
+			return errors.New("missing event data")
+		}
+		handled[event.Source+"/"+event.ID]++
+		return nil
+	}
+	dispatcher.Register(payments.WebhookSource(payments.NewPaystackProvider("sk_test_replay", "", nil)), handler)
+	dispatcher.Register(payments.WebhookSource(payments.NewFakeProvider("fake-replay", "")), handler)
+	dispatcher.Register(auth.ClerkWebhookSource(clerkReplaySecret), handler)
+	return dispatcher
+}
+
+func TestReplayRecordedFixtures(t *testing.T) {
+	fixtures, err := webhooks.LoadFixtures("testdata")
+	if err != nil {
+		t.Fatalf("LoadFixtures: %v", err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no fixtures found in testdata")
+	}
+
+	store := newMemoryStore()
+	handled := make(map[string]int)
+	fail := false
+	dispatcher := newDispatcher(store, handled, &fail)
+	ctx := context.Background()
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			header := verifiers[fixture.Source].Sign(fixture.Body)
+
+			result, err := dispatcher.Handle(ctx, fixture.Source, header, fixture.Body)
+			if err != nil || result != webhooks.ResultProcessed {
+				t.Fatalf("first delivery = %q, %v", result, err)
+			}
+
+			result, err = dispatcher.Handle(ctx, fixture.Source, header, fixture.Body)
+			if err != nil || result != webhooks.ResultDuplicate {
+				t.Fatalf("redelivery = %q, %v", result, err)
+			}
+
+			tampered := append([]byte(nil), fixture.Body...)
+			tampered[len(tampered)-2] = ' '
+			if _, err := dispatcher.Handle(ctx, fixture.Source, header, tampered); !errors.Is(err, webhooks.ErrInvalidSignature) {
+				t.Fatalf("tampered body error = %v", err)
+			}
+		})
+	}
+
+	for key, count := range handled {
+		if count != 1 {
+			t.Errorf("%s handled %d times, want 1", key, count)
+		}
+	}
+	if len(handled) != len(fixtures) {
+		t.Errorf("handled %d events, want %d", len(handled), len(fixtures))
+	}
+}
+
+func TestFailedDeliveryIsRetried(t *testing.T) {
+	fixtures, err := webhooks.LoadFixtures("testdata")
+	if err != nil || len(fixtures) == 0 {
+		t.Fatalf("LoadFixtures: %v", err)
+	}
+	fixture := fixtures[0]
+	header := verifiers[fixture.Source].Sign(fixture.Body)
+
+	store := newMemoryStore()
+	handled := make(map[string]int)
+	fail := true
+	dispatcher := newDispatcher(store, handled, &fail)
+
+	if _, err := dispatcher.Handle(context.Background(), fixture.Source, header, fixture.Body); err == nil {
+		t.Fatal("expected handler failure to be returned")
+	}
+
+	fail = false
+	result, err := dispatcher.Handle(context.Background(), fixture.Source, header, fixture.Body)
+	if err != nil || result != webhooks.ResultProcessed {
+		t.Fatalf("retry = %q, %v", result, err)
+	}
+}
+
+func TestStaleTimestampIsRejected(t *testing.T) {
+	verifier := payments.FakeWebhookVerifier("fake-replay")
+	verifier.Now = func() time.Time { return time.Now().Add(-time.Hour) }
+	body := []byte(`{"event_id":"evt_old","type":"payment.succeeded","reference":"TM-x","status":"succeeded"}`)
+	header := verifier.Sign(body)
+
+	dispatcher := newDispatcher(newMemoryStore(), make(map[string]int), new(bool))
+	if _, err := dispatcher.Handle(context.Background(), "fake", header, body); !errors.Is(err, webhooks.ErrStaleTimestamp) {
+		t.Fatalf("stale delivery error = %v", err)
+	}
+
+	if _, err := dispatcher.Handle(context.Background(), "stripe", http.Header{}, body); !errors.Is(err, webhooks.ErrUnknownSource) {
+		t.Fatalf("unknown source error = %v", err)
+	}
+}
+
+func TestConcurrentDeliveryIsNotHandledTwice(t *testing.T) {
+	fixtures, err := webhooks.LoadFixtures("testdata")
+	if err != nil || len(fixtures) == 0 {
+		t.Fatalf("LoadFixtures: %v", err)
+	}
+	fixture := fixtures[0]
+	header := verifiers[fixture.Source].Sign(fixture.Body)
+
+	store := newMemoryStore()
+	handled := make(map[string]int)
+	dispatcher := webhooks.NewDispatcher(store)
+	var redelivery error
+	// The provider redelivers the event while the handler is still running.
+	handler := func(ctx context.Context, event *webhooks.Event) error {
+		handled[event.ID]++
+		if handled[event.ID] == 1 {
+			_, redelivery = dispatcher.Handle(ctx, fixture.Source, header, fixture.Body)
+		}
+		return nil
+	}
+	dispatcher.Register(payments.WebhookSource(payments.NewPaystackProvider("sk_test_replay", "", nil)), handler)
+	dispatcher.Register(payments.WebhookSource(payments.NewFakeProvider("fake-replay", "")), handler)
+	dispatcher.Register(auth.ClerkWebhookSource(clerkReplaySecret), handler)
+
+	result, err := dispatcher.Handle(context.Background(), fixture.Source, header, fixture.Body)
+	if err != nil || result != webhooks.ResultProcessed {
+		t.Fatalf("first delivery = %q, %v", result, err)
+	}
+	if !errors.Is(redelivery, webhooks.ErrInProgress) {
+		t.Errorf("concurrent redelivery error = %v, want ErrInProgress", redelivery)
+	}
+	for id, count := range handled {
+		if count != 1 {
+			t.Errorf("%s handled %d times, want 1", id, count)
+		}
+	}
+}
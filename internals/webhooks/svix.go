@@ -0,0 +1,99 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signer produces the headers a provider would attach to a delivery. The local fakes
+// and the fixture replay harness use it.
+type Signer interface {
+	Sign(body []byte) http.Header
+}
+
+// SvixVerifier checks deliveries signed the way Svix signs them, as Clerk's webhooks
+// are: a base64 HMAC-SHA256 of "<svix-id>.<svix-timestamp>.<body>" in svix-signature,
+// which may list several space-separated "v1,<signature>" entries while a secret is
+// being rotated.
+type SvixVerifier struct {
+	// Secret is the endpoint's signing secret, with or without its "whsec_" prefix.
+	Secret    string
+	Tolerance time.Duration
+	Now       func() time.Time
+}
+
+// Verify returns nil when the delivery is authentic and fresh.
+func (v SvixVerifier) Verify(header http.Header, body []byte) error {
+	timestamp := header.Get("svix-timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrStaleTimestamp
+	}
+	age := v.now().Sub(time.Unix(seconds, 0))
+	if age < 0 {
+		age = -age
+	}
+	if age > v.tolerance() {
+		return ErrStaleTimestamp
+	}
+
+	key, err := v.key()
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	expected := svixSignature(key, header.Get("svix-id"), timestamp, body)
+	for _, candidate := range strings.Fields(header.Get("svix-signature")) {
+		version, signature, ok := strings.Cut(candidate, ",")
+		if ok && version == "v1" && hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// Sign returns the headers Svix would attach to body. The message ID is derived from
+// the body so replaying a fixture twice is recognised as a redelivery.
+func (v SvixVerifier) Sign(body []byte) http.Header {
+	sum := sha256.Sum256(body)
+	id := "msg_" + hex.EncodeToString(sum[:12])
+	timestamp := strconv.FormatInt(v.now().Unix(), 10)
+	key, _ := v.key()
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("svix-id", id)
+	header.Set("svix-timestamp", timestamp)
+	header.Set("svix-signature", "v1,"+svixSignature(key, id, timestamp, body))
+	return header
+}
+
+func (v SvixVerifier) key() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.TrimPrefix(v.Secret, "whsec_"))
+}
+
+func svixSignature(key []byte, id, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(id + "." + timestamp + "."))
+	mac.Write(body)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (v SvixVerifier) now() time.Time {
+	if v.Now != nil {
+		return v.Now()
+	}
+	return time.Now()
+}
+
+func (v SvixVerifier) tolerance() time.Duration {
+	if v.Tolerance > 0 {
+		return v.Tolerance
+	}
+	return DefaultTolerance
+}
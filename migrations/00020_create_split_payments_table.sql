@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE split_payments (
+  id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  split_id     UUID        NOT NULL REFERENCES splits(id) ON DELETE CASCADE,
+  user_id      UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  provider     TEXT        NOT NULL,
+  reference    TEXT        NOT NULL UNIQUE,
+  amount       NUMERIC     NOT NULL,
+  currency     TEXT        NOT NULL,
+  status       TEXT        NOT NULL DEFAULT 'pending', -- 'pending', 'succeeded', 'failed'
+  checkout_url TEXT        NOT NULL,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  confirmed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_split_payments_split_id ON split_payments(split_id);
+CREATE INDEX idx_split_payments_user_id ON split_payments(user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_split_payments_user_id;
+DROP INDEX IF EXISTS idx_split_payments_split_id;
+DROP TABLE IF EXISTS split_payments;
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE webhook_events (
+  id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  source       TEXT        NOT NULL,
+  event_id     TEXT        NOT NULL,
+  event_type   TEXT        NOT NULL,
+  payload      JSONB       NOT NULL,
+  status       TEXT        NOT NULL DEFAULT 'received', -- 'received', 'processed', 'failed'
+  attempts     INT         NOT NULL DEFAULT 1,
+  last_error   TEXT,
+  received_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  processed_at TIMESTAMPTZ,
+  UNIQUE (source, event_id)
+);
+
+CREATE INDEX idx_webhook_events_status ON webhook_events(status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_webhook_events_status;
+DROP TABLE IF EXISTS webhook_events;
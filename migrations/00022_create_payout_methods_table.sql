@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE payout_methods (
+  id          UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id     UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  method_type TEXT        NOT NULL, -- 'bank_account', 'mobile_money', 'paypal', 'iban'
+  label       TEXT,
+  details     TEXT        NOT NULL, -- envelope-encrypted JSON, see internals/payouts
+  is_default  BOOLEAN     NOT NULL DEFAULT FALSE,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_payout_methods_user_id ON payout_methods(user_id);
+
+ALTER TABLE splits ADD COLUMN payout_method_id UUID REFERENCES payout_methods(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE splits DROP COLUMN payout_method_id;
+DROP INDEX IF EXISTS idx_payout_methods_user_id;
+DROP TABLE IF EXISTS payout_methods;
@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC';
+
+CREATE TABLE split_reminder_policies (
+  split_id           UUID        PRIMARY KEY REFERENCES splits(id) ON DELETE CASCADE,
+  first_after_hours  INT         NOT NULL,
+  repeat_every_hours INT         NOT NULL,
+  max_reminders      INT         NOT NULL,
+  enabled            BOOLEAN     NOT NULL DEFAULT TRUE,
+  created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE split_member_reminders (
+  split_id         UUID        NOT NULL,
+  user_id          UUID        NOT NULL,
+  reminders_sent   INT         NOT NULL DEFAULT 0,
+  last_reminded_at TIMESTAMPTZ,
+  PRIMARY KEY (split_id, user_id),
+  FOREIGN KEY (split_id, user_id) REFERENCES split_members(split_id, user_id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS split_member_reminders;
+DROP TABLE IF EXISTS split_reminder_policies;
+ALTER TABLE users DROP COLUMN timezone;
@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE notification_outbox (
+  id              UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id         UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  split_id        UUID        REFERENCES splits(id) ON DELETE CASCADE,
+  kind            TEXT        NOT NULL,
+  title           TEXT        NOT NULL,
+  body            TEXT        NOT NULL,
+  data            JSONB       NOT NULL DEFAULT '{}',
+  status          TEXT        NOT NULL DEFAULT 'pending', -- 'pending', 'sending', 'delivered', 'failed'
+  attempts        INT         NOT NULL DEFAULT 0,
+  max_attempts    INT         NOT NULL DEFAULT 5,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  locked_until    TIMESTAMPTZ,
+  last_error      TEXT,
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  delivered_at    TIMESTAMPTZ
+);
+
+CREATE INDEX idx_notification_outbox_due ON notification_outbox(status, next_attempt_at);
+CREATE INDEX idx_notification_outbox_split_id ON notification_outbox(split_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_notification_outbox_split_id;
+DROP INDEX IF EXISTS idx_notification_outbox_due;
+DROP TABLE IF EXISTS notification_outbox;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Expo push tickets, kept until their receipt has been checked.
+CREATE TABLE push_tickets (
+  id              TEXT        PRIMARY KEY, -- Expo ticket id
+  notification_id UUID        NOT NULL REFERENCES notification_outbox(id) ON DELETE CASCADE,
+  push_token      TEXT        NOT NULL,
+  status          TEXT        NOT NULL DEFAULT 'pending', -- 'pending', 'ok', 'error', 'expired'
+  error           TEXT,
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  checked_at      TIMESTAMPTZ
+);
+
+CREATE INDEX idx_push_tickets_pending ON push_tickets(created_at) WHERE status = 'pending';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_push_tickets_pending;
+DROP TABLE IF EXISTS push_tickets;
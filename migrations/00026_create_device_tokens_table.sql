@@ -0,0 +1,34 @@
+-- +goose Up
+-- One row per device, so a user signed in on a phone and a tablet is notified on both.
+CREATE TABLE device_tokens (
+  id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id      UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  token        TEXT        NOT NULL UNIQUE,
+  platform     TEXT        NOT NULL DEFAULT 'unknown', -- 'ios', 'android', 'web', 'unknown'
+  app_version  TEXT,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_device_tokens_user_id ON device_tokens(user_id);
+
+INSERT INTO device_tokens (user_id, token)
+SELECT id, push_token FROM users
+WHERE push_token IS NOT NULL AND push_token <> ''
+ON CONFLICT (token) DO NOTHING;
+
+ALTER TABLE users DROP COLUMN push_token;
+
+-- +goose Down
+ALTER TABLE users ADD COLUMN push_token TEXT;
+
+UPDATE users u
+SET push_token = (
+  SELECT d.token FROM device_tokens d
+  WHERE d.user_id = u.id
+  ORDER BY d.last_seen_at DESC
+  LIMIT 1
+);
+
+DROP INDEX IF EXISTS idx_device_tokens_user_id;
+DROP TABLE IF EXISTS device_tokens;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Users without a row get the defaults: every event on every channel, quiet from 21:00 to 08:00.
+CREATE TABLE notification_preferences (
+  user_id             UUID        PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  channels            JSONB       NOT NULL DEFAULT '{}', -- {"reminders": {"push": false}}; missing entries are enabled
+  quiet_hours_enabled BOOLEAN     NOT NULL DEFAULT TRUE,
+  quiet_hours_start   INT         NOT NULL DEFAULT 21 CHECK (quiet_hours_start BETWEEN 0 AND 23),
+  quiet_hours_end     INT         NOT NULL DEFAULT 8 CHECK (quiet_hours_end BETWEEN 0 AND 23),
+  updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_preferences;
@@ -0,0 +1,35 @@
+-- +goose Up
+-- Placeholder users stand in for someone who has no account yet, so they can be
+-- members of splits and tables. They have no Clerk identity or email; when the person
+-- signs up their memberships move to the real user and the placeholder is deleted.
+ALTER TABLE users ADD COLUMN is_placeholder BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Placeholders share the empty email, so uniqueness only applies to real addresses.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX users_email_key ON users(email) WHERE email <> '';
+
+-- An invite link points at exactly one split or table. Links with a placeholder are
+-- single use: whoever accepts first takes over the placeholder.
+CREATE TABLE invite_links (
+  id                  UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  split_id            UUID        REFERENCES splits(id) ON DELETE CASCADE,
+  table_id            UUID        REFERENCES tables(id) ON DELETE CASCADE,
+  created_by          UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  placeholder_user_id UUID        REFERENCES users(id) ON DELETE SET NULL,
+  expires_at          TIMESTAMPTZ NOT NULL,
+  revoked_at          TIMESTAMPTZ,
+  claimed_by          UUID        REFERENCES users(id) ON DELETE SET NULL,
+  claimed_at          TIMESTAMPTZ,
+  created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CHECK ((split_id IS NULL) <> (table_id IS NULL))
+);
+
+CREATE INDEX idx_invite_links_split_id ON invite_links(split_id);
+CREATE INDEX idx_invite_links_table_id ON invite_links(table_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS invite_links;
+DELETE FROM users WHERE is_placeholder;
+DROP INDEX IF EXISTS users_email_key;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+ALTER TABLE users DROP COLUMN IF EXISTS is_placeholder;
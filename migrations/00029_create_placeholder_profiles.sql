@@ -0,0 +1,15 @@
+-- +goose Up
+-- Contact details a host recorded for a placeholder member. When someone signs in with
+-- a matching email they can take the placeholder over, history included.
+CREATE TABLE placeholder_profiles (
+  user_id    UUID        PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  created_by UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  email      TEXT,
+  phone      TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_placeholder_profiles_email ON placeholder_profiles(LOWER(email)) WHERE email IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS placeholder_profiles;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Join codes are rotatable, optionally expiring codes a host hands out instead of the
+-- permanent split or table code. Once a split or table has had one, its permanent code
+-- no longer lets new people join.
+CREATE TABLE join_codes (
+  code       VARCHAR(10) PRIMARY KEY,
+  split_id   UUID        REFERENCES splits(id) ON DELETE CASCADE,
+  table_id   UUID        REFERENCES tables(id) ON DELETE CASCADE,
+  created_by UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  expires_at TIMESTAMPTZ, -- NULL never expires
+  revoked_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CHECK ((split_id IS NULL) <> (table_id IS NULL))
+);
+
+CREATE INDEX idx_join_codes_split_id ON join_codes(split_id);
+CREATE INDEX idx_join_codes_table_id ON join_codes(table_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS join_codes;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Members are 'host', 'cohost' or 'guest'. A co-host can do what their permissions
+-- list allows; the host can do everything.
+ALTER TABLE split_members ADD COLUMN permissions TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE table_members ADD COLUMN permissions TEXT[] NOT NULL DEFAULT '{}';
+
+-- Table host checks now go through the member role, so make sure every table creator
+-- has it.
+UPDATE table_members tm
+SET role = 'host'
+FROM tables t
+WHERE tm.table_id = t.id AND tm.user_id = t.created_by AND tm.role <> 'host';
+
+-- +goose Down
+UPDATE split_members SET role = 'guest' WHERE role = 'cohost';
+UPDATE table_members SET role = 'guest' WHERE role = 'cohost';
+ALTER TABLE table_members DROP COLUMN IF EXISTS permissions;
+ALTER TABLE split_members DROP COLUMN IF EXISTS permissions;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Personal access tokens let scripts call the API as a user. Only a SHA-256 hash of
+-- each token is stored; the token itself is shown once, when it is created.
+CREATE TABLE personal_access_tokens (
+  id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id      UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name         TEXT        NOT NULL,
+  token_hash   BYTEA       NOT NULL UNIQUE,
+  token_hint   TEXT        NOT NULL, -- last characters of the token, to tell them apart
+  scopes       TEXT[]      NOT NULL,
+  expires_at   TIMESTAMPTZ, -- NULL never expires
+  last_used_at TIMESTAMPTZ,
+  revoked_at   TIMESTAMPTZ,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_personal_access_tokens_user_id ON personal_access_tokens(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS personal_access_tokens;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- A friend request from requester to addressee. Accepting it makes them friends;
+-- declining or cancelling it, or unfriending, deletes the row.
+CREATE TABLE friendships (
+  requester_id UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  addressee_id UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  status       TEXT        NOT NULL DEFAULT 'pending', -- 'pending', 'accepted'
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  accepted_at  TIMESTAMPTZ,
+  PRIMARY KEY (requester_id, addressee_id),
+  CHECK (requester_id <> addressee_id)
+);
+
+-- One row per pair, whichever of them asked.
+CREATE UNIQUE INDEX idx_friendships_pair ON friendships(LEAST(requester_id, addressee_id), GREATEST(requester_id, addressee_id));
+CREATE INDEX idx_friendships_addressee_id ON friendships(addressee_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS friendships;
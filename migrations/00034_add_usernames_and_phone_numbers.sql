@@ -0,0 +1,38 @@
+-- +goose Up
+-- Usernames are unique ignoring case. Phone numbers are stored in E.164 form and only
+-- count once verified; until then the same number can be pending on several accounts.
+ALTER TABLE users ADD COLUMN username TEXT;
+ALTER TABLE users ADD COLUMN phone_number TEXT;
+ALTER TABLE users ADD COLUMN phone_verified_at TIMESTAMPTZ;
+
+-- Who can find the user by an exact email, phone number or username:
+-- 'everyone', 'contacts' (friends and people they share splits or tables with) or 'nobody'.
+ALTER TABLE users ADD COLUMN discoverable_by_email TEXT NOT NULL DEFAULT 'everyone';
+ALTER TABLE users ADD COLUMN discoverable_by_phone TEXT NOT NULL DEFAULT 'contacts';
+ALTER TABLE users ADD COLUMN discoverable_by_username TEXT NOT NULL DEFAULT 'everyone';
+
+CREATE UNIQUE INDEX idx_users_username ON users(LOWER(username));
+CREATE UNIQUE INDEX idx_users_verified_phone ON users(phone_number) WHERE phone_verified_at IS NOT NULL;
+CREATE INDEX idx_users_email_lower ON users(LOWER(email));
+
+-- The code last sent to confirm a phone number. Only a hash of the code is kept.
+CREATE TABLE phone_verifications (
+  user_id      UUID        PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  phone_number TEXT        NOT NULL,
+  code_hash    BYTEA       NOT NULL,
+  attempts     INT         NOT NULL DEFAULT 0,
+  expires_at   TIMESTAMPTZ NOT NULL,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS phone_verifications;
+DROP INDEX IF EXISTS idx_users_email_lower;
+DROP INDEX IF EXISTS idx_users_verified_phone;
+DROP INDEX IF EXISTS idx_users_username;
+ALTER TABLE users DROP COLUMN IF EXISTS discoverable_by_username;
+ALTER TABLE users DROP COLUMN IF EXISTS discoverable_by_phone;
+ALTER TABLE users DROP COLUMN IF EXISTS discoverable_by_email;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_verified_at;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_number;
+ALTER TABLE users DROP COLUMN IF EXISTS username;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- The stored thumbnails of each avatar a user has uploaded. Uploading a new one or
+-- removing it marks the current one replaced; the sweeper deletes the objects of
+-- replaced avatars once nothing should still be showing them, then the row.
+CREATE TABLE avatars (
+  id          UUID        PRIMARY KEY,
+  user_id     UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  object_keys TEXT[]      NOT NULL,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  replaced_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_avatars_user_id ON avatars(user_id);
+CREATE INDEX idx_avatars_replaced_at ON avatars(replaced_at) WHERE replaced_at IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS avatars;
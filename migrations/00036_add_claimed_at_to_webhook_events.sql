@@ -0,0 +1,7 @@
+-- +goose Up
+-- A delivery claims its event ('processing') before running the handler, so concurrent
+-- redeliveries of the same event can't both apply it.
+ALTER TABLE webhook_events ADD COLUMN claimed_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE webhook_events DROP COLUMN IF EXISTS claimed_at;
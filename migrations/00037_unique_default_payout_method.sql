@@ -0,0 +1,14 @@
+-- +goose Up
+-- Keep the oldest default if concurrent requests left a user with more than one.
+UPDATE payout_methods p
+SET is_default = FALSE
+WHERE is_default AND EXISTS (
+  SELECT 1 FROM payout_methods o
+  WHERE o.user_id = p.user_id AND o.is_default
+    AND (o.created_at, o.id) < (p.created_at, p.id)
+);
+
+CREATE UNIQUE INDEX idx_payout_methods_one_default ON payout_methods(user_id) WHERE is_default;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_payout_methods_one_default;